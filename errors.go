@@ -0,0 +1,36 @@
+package simgo
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Reasons of a tracking session failure, test them with errors.Is on the errors of SimGo.Error
+var (
+	ErrConnectFailed   = errors.New("connection to the simulator failed")
+	ErrStaleData       = errors.New("no data received from the simulator")
+	ErrSimQuit         = errors.New("simulator quit")
+	ErrCrashed         = errors.New("aircraft crashed")
+	ErrPanic           = errors.New("tracking session panicked")
+	ErrExceededRetries = errors.New("exceeded max tries")
+	// ErrUnsupportedProvider is sent when the provider of SimGo is not tracked, only SimConnect is
+	ErrUnsupportedProvider = errors.New("provider not supported by the tracking sessions")
+	// ErrAlreadyTracking is sent when a SimGo is already tracked, its state and channels are not shared
+	ErrAlreadyTracking = errors.New("already tracking")
+)
+
+// TrackError is sent on SimGo.Error when a tracking session of TrackWithRecover stops
+type TrackError struct {
+	TrackID int
+	// Attempt is the number of consecutive failed sessions, including this one
+	Attempt int
+	Err     error
+}
+
+func (e *TrackError) Error() string {
+	return fmt.Sprintf("track %d attempt %d: %s", e.TrackID, e.Attempt, e.Err.Error())
+}
+
+func (e *TrackError) Unwrap() error {
+	return e.Err
+}
@@ -3,6 +3,7 @@ package main
 import (
 	"context"
 	"reflect"
+	"time"
 
 	"github.com/flysim-apps/simgo"
 	"github.com/op/go-logging"
@@ -11,40 +12,40 @@ import (
 var logger = logging.MustGetLogger("simgo")
 
 func main() {
+	// the code below is the same with simgo.SimConnect
 	sim := simgo.NewSimGo(logger, simgo.FSUIPC)
 
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
-	if err := sim.FSUIPC(ctx, "ws://localhost:2048/fsuipc/"); err != nil {
-		panic("Unable to establish websocket connection: " + err.Error())
+	provider, err := sim.NewProvider("simgo", "ws://localhost:2048/fsuipc/")
+	if err != nil {
+		panic(err.Error())
 	}
-
-	if err := sim.OffsetPolling("event", simgo.Offsets{}, 1000); err != nil {
-		sim.Logger.Errorf("Failed to obtain polling: %s", err.Error())
-	}
-	if err := sim.Payload(10000); err != nil {
-		sim.Logger.Errorf("Failed to obtain payload: %s", err.Error())
+	if err := provider.Connect(ctx); err != nil {
+		panic("Unable to connect to the simulator: " + err.Error())
 	}
+	defer provider.Close()
 
-	eventChan := make(chan interface{})
-	payloadChan := make(chan interface{})
-
-	sim.ReadData("event", simgo.Report{}, eventChan, payloadChan)
+	events, err := provider.Subscribe(simgo.Report{}, time.Second)
+	if err != nil {
+		logger.Errorf("Failed to subscribe: %s", err.Error())
+		return
+	}
 
 	for {
 		select {
-		case result := <-eventChan:
+		case result := <-events:
 			logger.Debugf("===================================================================================")
 			val := reflect.ValueOf(result)
 			for i := 0; i < val.Type().NumField(); i++ {
 				logger.Debugf("%s = %+v", val.Type().Field(i).Name, val.Field(i))
 			}
-		case result := <-payloadChan:
-			logger.Debugf("Payload: %+v", result)
-		case err := <-sim.Error:
-			logger.Errorf("Track failed: %s", err.Error())
-			return
+		case event := <-provider.Events():
+			logger.Infof("Event %s %v", event.Type, event.Value)
+			if event.Type == simgo.EventQuit {
+				return
+			}
 		}
 	}
 
@@ -9,6 +9,7 @@ import (
 	"strconv"
 	"strings"
 
+	"github.com/op/go-logging"
 	"nhooyr.io/websocket/wsjson"
 )
 
@@ -107,6 +108,10 @@ func (s *SimGo) ReadData(name string, v interface{}, eventChan chan interface{},
 }
 
 func (s *SimGo) FSUIPC_Remap(val reflect.Value) []FSUIPC_Offset {
+	return fsuipcRemap(val)
+}
+
+func fsuipcRemap(val reflect.Value) []FSUIPC_Offset {
 	vars := make([]FSUIPC_Offset, 0)
 
 	for i := 0; i < val.Type().NumField(); i++ {
@@ -142,6 +147,12 @@ func (s *SimGo) FSUIPC_Remap(val reflect.Value) []FSUIPC_Offset {
 }
 
 func (s *SimGo) FSUIPC_ToInterface(data Offsets, dst reflect.Value) interface{} {
+	return fsuipcToInterface(s.Logger, data, dst)
+}
+
+// fsuipcToInterface convert the offsets read in data, a struct with fsuipc tags, to a new value of the type of dst.
+// Fields are matched by name
+func fsuipcToInterface(logger *logging.Logger, data interface{}, dst reflect.Value) interface{} {
 	val := reflect.ValueOf(data)
 	r := reflect.New(reflect.TypeOf(dst.Interface())).Elem()
 	for i := 0; i < val.Type().NumField(); i++ {
@@ -149,7 +160,7 @@ func (s *SimGo) FSUIPC_ToInterface(data Offsets, dst reflect.Value) interface{}
 			dstField := dst.Type().Field(j)
 			if val.Type().Field(i).Name == dstField.Name {
 				if err := setValueForField(val.Type().Field(i), val.Field(i), r.Field(j)); err != nil {
-					s.Logger.Warningf("Failed set value for %s", err.Error())
+					logger.Warningf("Failed set value for %s", err.Error())
 				}
 			}
 		}
@@ -0,0 +1,230 @@
+package simgo
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/op/go-logging"
+	"nhooyr.io/websocket"
+	"nhooyr.io/websocket/wsjson"
+)
+
+var _ DataProvider = (*FSUIPCProvider)(nil)
+
+// FSUIPCProvider is the DataProvider of the FSUIPC WebSocket server. Reports with address, type and size tags
+// receive the raw offset values, other reports like Report are converted from Offsets matching fields by name
+type FSUIPCProvider struct {
+	url    string
+	logger *logging.Logger
+	// ctx is the lifetime of the connection, cancel is called by Close
+	ctx    context.Context
+	cancel context.CancelFunc
+	ws     *websocket.Conn
+	events chan Event
+	mu     sync.Mutex
+	subs   map[string]fsuipcSubscription
+	writes map[reflect.Type]string
+}
+
+type fsuipcSubscription struct {
+	source reflect.Type
+	report reflect.Value
+	out    chan interface{}
+}
+
+// NewFSUIPCProvider create a FSUIPC provider for the WebSocket server at url, like DefaultFSUIPCAddr
+func NewFSUIPCProvider(url string, logger *logging.Logger) *FSUIPCProvider {
+	return &FSUIPCProvider{
+		url:    url,
+		logger: logger,
+		events: make(chan Event, 16),
+		subs:   make(map[string]fsuipcSubscription),
+		writes: make(map[reflect.Type]string),
+	}
+}
+
+// Connect open the WebSocket connection. ctx only bounds the handshake, the connection lasts until Close
+func (p *FSUIPCProvider) Connect(ctx context.Context) error {
+	ws, _, err := websocket.Dial(ctx, p.url, &websocket.DialOptions{
+		Subprotocols: []string{"fsuipc"},
+	})
+	if err != nil {
+		return err
+	}
+	connCtx, cancel := context.WithCancel(context.Background())
+	p.mu.Lock()
+	p.ctx, p.cancel, p.ws = connCtx, cancel, ws
+	p.mu.Unlock()
+	p.logger.Info("Connected to FSUIPC")
+	go p.readLoop(connCtx, cancel, ws)
+	return nil
+}
+
+func (p *FSUIPCProvider) readLoop(ctx context.Context, cancel context.CancelFunc, ws *websocket.Conn) {
+	defer func() {
+		if _, _, err := p.conn(); err == nil {
+			sendEvent(p.events, Event{Type: EventQuit})
+		}
+		// the subscriptions end with the connection, readLoop is the only sender
+		cancel()
+		p.mu.Lock()
+		for name, sub := range p.subs {
+			close(sub.out)
+			delete(p.subs, name)
+		}
+		p.mu.Unlock()
+	}()
+	for {
+		var msg FSUIPC_Response
+		if err := wsjson.Read(ctx, ws, &msg); err != nil {
+			p.logger.Errorf("Unable to read from socket: %s", err.Error())
+			return
+		}
+		if !msg.Success {
+			p.logger.Errorf("Error for %s (%s): %v - %s", msg.Name, msg.Command, msg.ErrorCode, msg.ErrorMessage)
+			if msg.ErrorCode == "NoFlightSim" {
+				return
+			}
+			continue
+		}
+		if msg.Command != "offsets.read" {
+			continue
+		}
+		p.mu.Lock()
+		sub, found := p.subs[msg.Name]
+		p.mu.Unlock()
+		if !found {
+			continue
+		}
+		data := reflect.New(sub.source)
+		if err := loadFromMap(msg.Data, data.Interface()); err != nil {
+			p.logger.Warningf("Failed to decode offsets %s: %s", msg.Name, err.Error())
+			continue
+		}
+		var v interface{} = data.Elem().Interface()
+		if sub.source != sub.report.Type() {
+			v = fsuipcToInterface(p.logger, v, sub.report)
+		}
+		select {
+		case sub.out <- v:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func loadFromMap(m map[string]interface{}, v interface{}) error {
+	data, err := json.Marshal(m)
+	if err == nil {
+		err = json.Unmarshal(data, v)
+	}
+	return err
+}
+
+func (p *FSUIPCProvider) conn() (context.Context, *websocket.Conn, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.ws == nil {
+		return nil, nil, errProviderNotConnected
+	}
+	return p.ctx, p.ws, nil
+}
+
+// Subscribe declare the offsets of report and read them every interval, one second when zero
+func (p *FSUIPCProvider) Subscribe(report interface{}, interval time.Duration) (<-chan interface{}, error) {
+	ctx, ws, err := p.conn()
+	if err != nil {
+		return nil, err
+	}
+	val := reflect.Indirect(reflect.ValueOf(report))
+	if val.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("report must be a struct, got %s", val.Kind())
+	}
+	if interval <= 0 {
+		interval = time.Second
+	}
+
+	source := val
+	offsets := fsuipcRemap(val)
+	if len(offsets) == 0 {
+		source = reflect.ValueOf(Offsets{})
+		offsets = fsuipcRemap(source)
+	}
+
+	p.mu.Lock()
+	if ctx.Err() != nil {
+		p.mu.Unlock()
+		return nil, errProviderNotConnected
+	}
+	name := fmt.Sprintf("simgo.%d.%s", len(p.subs)+1, val.Type().Name())
+	sub := fsuipcSubscription{source.Type(), val, make(chan interface{})}
+	p.subs[name] = sub
+	p.mu.Unlock()
+
+	if err := wsjson.Write(ctx, ws, FSUIPC_Command{Command: "offsets.declare", Name: name, Offsets: offsets}); err != nil {
+		return nil, err
+	}
+	if err := wsjson.Write(ctx, ws, FSUIPC_Command{Command: "offsets.read", Name: name, Interval: int(interval.Milliseconds())}); err != nil {
+		return nil, err
+	}
+	return sub.out, nil
+}
+
+// Write the raw values of the fields of report, a struct with address, type and size tags
+func (p *FSUIPCProvider) Write(report interface{}) error {
+	ctx, ws, err := p.conn()
+	if err != nil {
+		return err
+	}
+	val := reflect.Indirect(reflect.ValueOf(report))
+	if val.Kind() != reflect.Struct {
+		return fmt.Errorf("report must be a struct, got %s", val.Kind())
+	}
+	offsets := fsuipcRemap(val)
+	if len(offsets) == 0 {
+		return errors.New("report has no FSUIPC offset")
+	}
+
+	p.mu.Lock()
+	name, declared := p.writes[val.Type()]
+	if !declared {
+		name = fmt.Sprintf("simgo.write.%d.%s", len(p.writes)+1, val.Type().Name())
+		p.writes[val.Type()] = name
+	}
+	p.mu.Unlock()
+
+	if !declared {
+		if err := wsjson.Write(ctx, ws, FSUIPC_Command{Command: "offsets.declare", Name: name, Offsets: offsets}); err != nil {
+			return err
+		}
+	}
+	values := make([]FSUIPC_Offset_Value, 0, len(offsets))
+	for _, offset := range offsets {
+		values = append(values, FSUIPC_Offset_Value{offset.Name, val.FieldByName(offset.Name).Interface()})
+	}
+	return wsjson.Write(ctx, ws, FSUIPC_Command_Write{Command: "offsets.write", Name: name, Offsets: values})
+}
+
+// Events return EventQuit when the connection to FSUIPC or the simulator is lost
+func (p *FSUIPCProvider) Events() <-chan Event {
+	return p.events
+}
+
+// Close the WebSocket connection
+func (p *FSUIPCProvider) Close() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.ws == nil {
+		return nil
+	}
+	p.logger.Info("Closed connection to FSUIPC")
+	err := p.ws.Close(websocket.StatusNormalClosure, "")
+	p.ws = nil
+	p.cancel()
+	return err
+}
@@ -132,10 +132,10 @@ type Offsets struct {
 	APUSwitch            int          `address:"0x029D" type:"uint" size:"1" fsuipc:"bool"`
 	BatterySwitch        int          `address:"0x281C" type:"uint" size:"4" fsuipc:"bool"`
 	ExtPowerOn           int          `address:"0x07AB" type:"uint" size:"1" fsuipc:"bool"`
-	IsDoorsOpen          int          `address:"0x2A70" type:"uint" size:"8" fsuipc:"bool"`
+	IsDoorsOpen          int          `address:"0x2A70" type:"uint" size:"8" fsuipc:"bool" dataref:"sim/cockpit2/switches/canopy_open" name:"CANOPY_OPEN" access:"rw"`
 	Lights               map[int]bool `address:"0x0D0C" type:"bits" size:"2" fsuipc:"bits"`
-	FastenSeatBealts     int          `address:"0x341D" type:"int" size:"1" fsuipc:"bool"`
-	NoSmoking            int          `address:"0x341C" type:"int" size:"1" fsuipc:"bool"`
+	FastenSeatBealts     int          `address:"0x341D" type:"int" size:"1" fsuipc:"bool" dataref:"sim/cockpit/switches/fasten_seat_belts" name:"SEAT_B" access:"rw" scale:"1 -"`
+	NoSmoking            int          `address:"0x341C" type:"int" size:"1" fsuipc:"bool" dataref:"sim/cockpit/switches/no_smoking" access:"rw"`
 	StallWarning         int          `address:"0x036C" type:"int" size:"1" fsuipc:"bool"`
 	OverspeedWarning     int          `address:"0x036D" type:"int" size:"1" fsuipc:"bool"`
 	InParkingState       int          `address:"0x062B" type:"int" size:"1" fsuipc:"bool"`
@@ -192,6 +192,38 @@ func getValue(field reflect.Value, simVar sim.SimVar) {
 	}
 }
 
+// setFloat set a numeric or bool field from a simulator value
+func setFloat(field reflect.Value, f float64) {
+	switch field.Kind() {
+	case reflect.Float32, reflect.Float64:
+		field.SetFloat(f)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		field.SetInt(int64(f))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		field.SetUint(uint64(f))
+	case reflect.Bool:
+		field.SetBool(f > 0)
+	}
+}
+
+// fieldFloat return the value of a numeric or bool field
+func fieldFloat(field reflect.Value) (float64, bool) {
+	switch {
+	case field.CanFloat():
+		return field.Float(), true
+	case field.CanInt():
+		return float64(field.Int()), true
+	case field.CanUint():
+		return float64(field.Uint()), true
+	case field.Kind() == reflect.Bool:
+		if field.Bool() {
+			return 1, true
+		}
+		return 0, true
+	}
+	return 0, false
+}
+
 type FSUIPC_Offset_Payload struct {
 	WeightUnit              string                   `json:"weightUnit"`
 	VolumeUnit              string                   `json:"volumeUnit"`
@@ -234,6 +266,17 @@ type FSUIPC_Command struct {
 	Interval int             `json:"interval,omitempty"`
 }
 
+type FSUIPC_Command_Write struct {
+	Command string                `json:"command"`
+	Name    string                `json:"name"`
+	Offsets []FSUIPC_Offset_Value `json:"offsets"`
+}
+
+type FSUIPC_Offset_Value struct {
+	Name  string      `json:"name"`
+	Value interface{} `json:"value"`
+}
+
 type FSUIPC_Command_Payload struct {
 	Command    string `json:"command"`
 	WeightUnit string `json:"weightUnit"`
@@ -258,9 +301,12 @@ type FSUIPC_Response struct {
 	Data         map[string]interface{} `json:"data"`
 }
 
+// Provider select the sim bridge used by SimGo, see SimGo.NewProvider for its DataProvider
 type Provider string
 
 const (
 	SimConnect Provider = "SimConnect"
 	FSUIPC     Provider = "FSUIPC"
+	XPlane     Provider = "XPlane"
+	XPlaneWeb  Provider = "XPlaneWeb"
 )
@@ -0,0 +1,99 @@
+package simgo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// DataProvider is a bridge to a flight simulator. SimConnect, FSUIPC and future backends implement it
+// so application code is the same whatever sim bridge the user has installed.
+type DataProvider interface {
+	// Connect to the simulator, it returns when the connection is confirmed
+	Connect(ctx context.Context) error
+	// Subscribe to report, a struct tagged for the provider. A value of the same type is sent on the
+	// returned chan every interval or at the provider rate when interval is zero, the chan is closed
+	// when the provider is closed or loses the simulator
+	Subscribe(report interface{}, interval time.Duration) (<-chan interface{}, error)
+	// Write the tagged fields of report in the simulator
+	Write(report interface{}) error
+	// Events return the notifications of the simulator
+	Events() <-chan Event
+	// Close the connection
+	Close() error
+}
+
+// EventType is the kind of a simulator notification
+type EventType string
+
+const (
+	// EventSimStart the flight is running, the user controls the aircraft
+	EventSimStart EventType = "sim_start"
+	// EventSimStop the flight is not running, the user is in the menu or loading a flight
+	EventSimStop EventType = "sim_stop"
+	// EventPause the flight is paused or resumed, Value is a bool
+	EventPause EventType = "pause"
+	// EventCrash the user aircraft crashed
+	EventCrash EventType = "crash"
+	// EventAircraftLoaded an aircraft was loaded, Value is the file name
+	EventAircraftLoaded EventType = "aircraft_loaded"
+	// EventQuit the simulator quit or the connection was lost
+	EventQuit EventType = "quit"
+)
+
+// Event is a notification of the simulator
+type Event struct {
+	Type  EventType
+	Value interface{}
+}
+
+const (
+	// DefaultFSUIPCAddr is the url of the FSUIPC WebSocket server on the local computer
+	DefaultFSUIPCAddr = "ws://localhost:2048/fsuipc/"
+	// DefaultXPlaneAddr is the UDP address of X-Plane on the local computer
+	DefaultXPlaneAddr = "127.0.0.1:49000"
+	// DefaultXPlaneWebAddr is the url of the X-Plane 12 Web API on the local computer
+	DefaultXPlaneWebAddr = "http://localhost:8086"
+)
+
+var errProviderNotConnected = errors.New("provider is not connected")
+
+// NewProvider return the DataProvider selected by s.Provider.
+// address is the FSUIPC WebSocket url, the SimConnect server, X-Plane (host:port) or the X-Plane Web API url,
+// when empty DefaultFSUIPCAddr, SimConnectAddr, DefaultXPlaneAddr and DefaultXPlaneWebAddr are used
+func (s *SimGo) NewProvider(name string, address string) (DataProvider, error) {
+	switch s.Provider {
+	case SimConnect:
+		if address == "" {
+			address = s.SimConnectAddr
+		}
+		return NewSimConnectProvider(name, address, s.Logger), nil
+	case FSUIPC:
+		if address == "" {
+			address = DefaultFSUIPCAddr
+		}
+		return NewFSUIPCProvider(address, s.Logger), nil
+	case XPlane:
+		if address == "" {
+			address = DefaultXPlaneAddr
+		}
+		return NewXPlaneUDPProvider(address, s.Logger), nil
+	case XPlaneWeb:
+		if address == "" {
+			address = DefaultXPlaneWebAddr
+		}
+		return NewXPlaneWebProvider(address, s.Logger), nil
+	}
+	return nil, fmt.Errorf("unknown provider %q", s.Provider)
+}
+
+// sendEvent deliver e without blocking the provider when nobody reads the events
+func sendEvent(events chan Event, e Event) bool {
+	select {
+	case events <- e:
+		return true
+	default:
+		return false
+	}
+}
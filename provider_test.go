@@ -0,0 +1,200 @@
+package simgo
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/flysim-apps/simgo/simconnect/simtest"
+	"github.com/op/go-logging"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"nhooyr.io/websocket"
+	"nhooyr.io/websocket/wsjson"
+)
+
+func nextEvent(t *testing.T, p DataProvider, eventType EventType) Event {
+	timeout := time.After(2 * time.Second)
+	for {
+		select {
+		case e := <-p.Events():
+			if e.Type == eventType {
+				return e
+			}
+		case <-timeout:
+			t.Fatalf("no %s event", eventType)
+		}
+	}
+}
+
+// requireClosed wait for the end of a subscription, dropping the reports still sent
+func requireClosed(t *testing.T, c <-chan interface{}) {
+	timeout := time.After(2 * time.Second)
+	for {
+		select {
+		case _, ok := <-c:
+			if !ok {
+				return
+			}
+		case <-timeout:
+			t.Fatal("subscription not closed")
+		}
+	}
+}
+
+func TestSimConnectProvider(t *testing.T) {
+	srv, err := simtest.NewServer()
+	require.NoError(t, err)
+	defer srv.Close()
+	srv.Aircraft.Set("PLANE ALTITUDE", 1200)
+
+	s := NewSimGo(logging.MustGetLogger("simgo-test"), SimConnect)
+	s.SimConnectAddr = srv.Addr()
+	p, err := s.NewProvider("simgo-test", "")
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	require.NoError(t, p.Connect(ctx))
+	defer p.Close()
+	// the connection outlives the ctx of Connect
+	cancel()
+
+	c, err := p.Subscribe(ReportTest{}, 100*time.Millisecond)
+	require.NoError(t, err)
+	select {
+	case r := <-c:
+		assert.Equal(t, 1200.0, r.(ReportTest).PlaneAltitude)
+	case <-time.After(2 * time.Second):
+		t.Fatal("no report received")
+	}
+
+	require.NoError(t, p.Write(struct {
+		Altitude int `name:"PLANE ALTITUDE" unit:"feet"`
+	}{3000}))
+	assert.Eventually(t, func() bool {
+		return srv.Aircraft.Float64("PLANE ALTITUDE") == 3000
+	}, 2*time.Second, 10*time.Millisecond)
+
+	srv.Crash()
+	nextEvent(t, p, EventCrash)
+	srv.Quit()
+	nextEvent(t, p, EventQuit)
+	requireClosed(t, c)
+	assert.Error(t, p.Write(struct {
+		Altitude int `name:"PLANE ALTITUDE" unit:"feet"`
+	}{1000}))
+}
+
+func TestFSUIPCProvider(t *testing.T) {
+	commands := make(chan map[string]interface{}, 8)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ws, err := websocket.Accept(w, r, &websocket.AcceptOptions{Subprotocols: []string{"fsuipc"}})
+		if err != nil {
+			return
+		}
+		defer ws.CloseNow()
+		for {
+			var cmd map[string]interface{}
+			if err := wsjson.Read(r.Context(), ws, &cmd); err != nil {
+				return
+			}
+			commands <- cmd
+			if cmd["command"] == "offsets.read" {
+				wsjson.Write(r.Context(), ws, FSUIPC_Response{
+					Success: true,
+					Command: "offsets.read",
+					Name:    cmd["name"].(string),
+					Data:    map[string]interface{}{"AltIndicated": 1500},
+				})
+			}
+		}
+	}))
+	defer ts.Close()
+
+	s := NewSimGo(logging.MustGetLogger("simgo-test"), FSUIPC)
+	p, err := s.NewProvider("simgo-test", "ws"+strings.TrimPrefix(ts.URL, "http"))
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	require.NoError(t, p.Connect(ctx))
+	defer p.Close()
+	// the connection outlives the ctx of Connect
+	cancel()
+
+	c, err := p.Subscribe(Report{}, 0)
+	require.NoError(t, err)
+	assert.Equal(t, "offsets.declare", (<-commands)["command"])
+	read := <-commands
+	assert.Equal(t, "offsets.read", read["command"])
+	assert.Equal(t, 1000.0, read["interval"])
+	select {
+	case r := <-c:
+		assert.Equal(t, 1500, r.(Report).AltIndicated)
+	case <-time.After(2 * time.Second):
+		t.Fatal("no report received")
+	}
+
+	require.NoError(t, p.Write(struct {
+		Com1 int64 `address:"0x034E" type:"uint" size:"2"`
+	}{0x2345}))
+	assert.Equal(t, "offsets.declare", (<-commands)["command"])
+	write := <-commands
+	assert.Equal(t, "offsets.write", write["command"])
+	assert.Equal(t, []interface{}{map[string]interface{}{"name": "Com1", "value": float64(0x2345)}}, write["offsets"])
+
+	require.NoError(t, p.Close())
+	requireClosed(t, c)
+	_, err = p.Subscribe(Report{}, 0)
+	assert.Error(t, err)
+}
+
+func TestSimConnectProviderLVars(t *testing.T) {
+	srv, err := simtest.NewServer()
+	require.NoError(t, err)
+	defer srv.Close()
+	srv.StartMobiFlight()
+	srv.Aircraft.Set("PLANE ALTITUDE", 1200)
+	srv.SetLVar("A32NX_SEAT_BELT", 1)
+
+	s := NewSimGo(logging.MustGetLogger("simgo-test"), SimConnect)
+	s.SimConnectAddr = srv.Addr()
+	p, err := s.NewProvider("simgo-test", "")
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	require.NoError(t, p.Connect(ctx))
+	defer p.Close()
+
+	type cabin struct {
+		Altitude  float64 `name:"PLANE ALTITUDE" unit:"feet"`
+		SeatBelts bool    `lvar:"A32NX_SEAT_BELT"`
+		NoSmoking int     `lvar:"A32NX_NO_SMOKING"`
+	}
+	c, err := p.Subscribe(cabin{}, 100*time.Millisecond)
+	require.NoError(t, err)
+	wait := func(want cabin) {
+		timeout := time.After(2 * time.Second)
+		for {
+			select {
+			case r := <-c:
+				if r.(cabin) == want {
+					return
+				}
+			case <-timeout:
+				t.Fatalf("no report %+v received", want)
+			}
+		}
+	}
+	wait(cabin{Altitude: 1200, SeatBelts: true})
+
+	require.NoError(t, p.Write(struct {
+		NoSmoking int `lvar:"A32NX_NO_SMOKING"`
+	}{2}))
+	wait(cabin{Altitude: 1200, SeatBelts: true, NoSmoking: 2})
+	value, _ := srv.LVar("A32NX_NO_SMOKING")
+	assert.Equal(t, 2.0, value)
+}
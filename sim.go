@@ -2,6 +2,7 @@ package simgo
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net/http"
 	"reflect"
@@ -17,28 +18,111 @@ import (
 )
 
 type SimGo struct {
-	Error      chan error
-	State      chan int
-	Connection <-chan bool
+	Error chan error
+	// State receive the changes of ConnectionState, see also SubscribeState
+	State chan ConnectionState
+	// Connection receive true when a simulator connects and false when it is lost
+	Connection chan bool
 	TrackEvent chan interface{}
 	TrackPause chan bool
-	TrackCrash <-chan bool
+	// TrackCrash receive true when the user aircraft crashed
+	TrackCrash chan bool
 	Logger     *logging.Logger
 	Socket     *websockets.Websocket
 	Context    context.Context
 	WS         *websocket.Conn
 	Provider   Provider
 	Alive      bool
+	// SimConnectAddr is host:port of a remote SimConnect server. When empty SimConnect.dll is used
+	SimConnectAddr string
+	state          stateMachine
 }
 
-var maxTriesInitial int
-var connectToMsfsInProgress = false
-var lastMessageReceived time.Time
-var simPaused = false
+// trackSession is the state of one TrackWithRecover call. A SimGo runs one session at a time as they share
+// its channels and State, several trackers run concurrently with one SimGo each, every session has its own watchdog
+type trackSession struct {
+	mu                  sync.Mutex
+	connectInProgress   bool
+	lastMessageReceived time.Time
+	receivedInRun       bool
+	paused              bool
+	// staleTimeout is the delay without message after which the session is stale
+	staleTimeout time.Duration
+}
+
+// defaultStaleTimeout is the staleTimeout of the new sessions
+var defaultStaleTimeout = 15 * time.Second
+
+func newTrackSession() *trackSession {
+	return &trackSession{staleTimeout: defaultStaleTimeout}
+}
+
+func (t *trackSession) connecting() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.connectInProgress = true
+}
+
+func (t *trackSession) connected() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.connectInProgress = false
+	t.lastMessageReceived = time.Now()
+}
+
+// heartbeat is called when the simulator shows it is running, without data
+func (t *trackSession) heartbeat() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.lastMessageReceived = time.Now()
+}
+
+func (t *trackSession) received() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.lastMessageReceived = time.Now()
+	t.receivedInRun = true
+}
+
+// reset is called when a run of the session starts
+func (t *trackSession) reset() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.receivedInRun = false
+}
+
+// hasReceived return true if data was received since the run started
+func (t *trackSession) hasReceived() bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.receivedInRun
+}
+
+func (t *trackSession) setPaused(paused bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.paused = paused
+}
+
+// stale return a reason when the connection was not confirmed for 2m or no message was received for staleTimeout
+func (t *trackSession) stale(now time.Time) (string, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.lastMessageReceived.IsZero() {
+		return "", false
+	}
+	if t.connectInProgress && t.lastMessageReceived.Before(now.Add(-2*time.Minute)) {
+		return "Connection was not confirmed for 2m. Cancel tracking", true
+	}
+	if !t.connectInProgress && t.lastMessageReceived.Before(now.Add(-t.staleTimeout)) {
+		return fmt.Sprintf("Last received message was received %s ago. Cancel tracking", t.staleTimeout), true
+	}
+	return "", false
+}
 
 // creates new simgo instance
 func NewSimGo(logger *logging.Logger, provider Provider) *SimGo {
-	return &SimGo{State: make(chan int, 0), TrackEvent: make(chan interface{}, 0), TrackPause: make(chan bool, 0), TrackCrash: make(chan bool, 0), Logger: logger, Provider: provider, Error: make(chan error, 1)}
+	return &SimGo{State: make(chan ConnectionState, 16), Connection: make(chan bool, 16), TrackEvent: make(chan interface{}, 0), TrackPause: make(chan bool, 0), TrackCrash: make(chan bool, 16), Logger: logger, Provider: provider, Error: make(chan error, 16)}
 }
 
 // starts web socket server on given host and port
@@ -75,10 +159,17 @@ func (s *SimGo) FSUIPC_Close() {
 	s.WS.Close(websocket.StatusNormalClosure, "")
 }
 
-// connects to MSFS
-func (s *SimGo) connect(ctx context.Context, name string) (*sim.EasySimConnect, error) {
+// simConnection is an open SimConnect connection and the notifications read by the tracking session
+type simConnection struct {
+	sc      *sim.EasySimConnect
+	open    <-chan bool
+	running <-chan bool
+}
+
+// connects to MSFS and wait the flight to be running
+func (s *SimGo) connect(ctx context.Context, name string) (*simConnection, error) {
 	s.Logger.Info("Connecting to MSFS...")
-	sc, err := sim.NewEasySimConnect(ctx)
+	sc, err := s.newEasySimConnect(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -86,163 +177,220 @@ func (s *SimGo) connect(ctx context.Context, name string) (*sim.EasySimConnect,
 	sc.SetDelay(1 * time.Second)
 	sc.SetLoggerLevel(sim.LogInfo)
 
-	//time.Sleep(5 * time.Second)
-
 	c, err := sc.Connect(name)
 	if err != nil {
 		return nil, err
 	}
 
-	<-c // wait connection confirmation
+	select {
+	case open := <-c: // wait connection confirmation
+		if !open {
+			return nil, errors.New("connection refused by MSFS")
+		}
+	case <-ctx.Done():
+		sc.Close()
+		return nil, context.Cause(ctx)
+	}
 
 	s.Logger.Info("Still working on connection to MSFS...")
+	s.setState(StateConnectedInMenu)
 
-	for {
-		if <-sc.ConnectSysEventSim() {
-			break // wait sim start
+	running := sc.ConnectSysEventSim()
+	for started := false; !started; {
+		select {
+		case started = <-running: // wait sim start
+		case <-c:
+			return nil, ErrSimQuit
+		case <-ctx.Done():
+			sc.Close()
+			return nil, context.Cause(ctx)
 		}
 	}
 
 	s.Logger.Info("Connected to MSFS!")
+	s.setState(StateInFlight)
 
-	connectToMsfsInProgress = false
-	lastMessageReceived = time.Now()
-
-	return sc, nil
+	return &simConnection{sc, c, running}, nil
 }
 
-func (s *SimGo) TrackWithRecover(name string, report interface{}, maxTries int, trackID int) {
-
-	if s.Provider == FSUIPC {
-		return
+func (s *SimGo) newEasySimConnect(ctx context.Context) (*sim.EasySimConnect, error) {
+	if s.SimConnectAddr != "" {
+		return sim.NewEasySimConnectRemote(ctx, s.SimConnectAddr), nil
 	}
-
-	maxTriesInitial = maxTries
-
-	go s.recoverer(maxTries, trackID, func() {
-		checker := time.NewTicker(15 * time.Second)
-		ctx, cancel := context.WithCancel(context.Background())
-		wait := sync.WaitGroup{}
-
-		defer checker.Stop()
-		wait.Add(2)
-
-		go s.track(name, report, ctx, &wait)
-
-		go func() {
-			defer wait.Done()
-
-			for {
-				select {
-				case <-ctx.Done():
-					s.Logger.Warning("Checking routine will exit")
-					return
-				case <-checker.C:
-					timeOut5s := time.Now().Add(-15 * time.Second)
-					timeOutConnection := time.Now().Add(-2 * time.Minute)
-					if connectToMsfsInProgress && !lastMessageReceived.IsZero() && lastMessageReceived.Before(timeOutConnection) {
-						s.Logger.Error("Connection was not confirmed for 2m. Cancel tracking")
-						cancel()
-					}
-					if !connectToMsfsInProgress && !lastMessageReceived.IsZero() && lastMessageReceived.Before(timeOut5s) {
-						s.Logger.Error("Last received message was received 15 sec ago. Cancel tracking")
-						cancel()
-					}
-				}
-			}
-		}()
-
-		wait.Wait()
-
-		s.Logger.Debug(ctx.Err())
-
-		if ctx.Err() != nil {
-			panic(ctx.Err().Error())
-		}
-
-		panic("Exiting from tracker routine...")
-	})
+	return sim.NewEasySimConnect(ctx)
 }
 
-func (s *SimGo) track(name string, report interface{}, ctx context.Context, wg *sync.WaitGroup) {
-	connectToMsfsInProgress = true
-	sc, err := s.connect(ctx, name)
-	defer wg.Done()
-	defer sc.Close()
-
+// track run one tracking session until it fails. The error is ErrConnectFailed, ErrSimQuit, ErrCrashed
+// or the cause of ctx like ErrStaleData
+func (s *SimGo) track(ctx context.Context, session *trackSession, name string, reports []*TrackedReport) error {
+	session.connecting()
+	conn, err := s.connect(ctx, name)
 	if err != nil {
-		s.Logger.Errorf("connection to MSFS has been failed. Reason: %s", err.Error())
-		return
+		if ctx.Err() != nil || errors.Is(err, ErrSimQuit) {
+			return err
+		}
+		return fmt.Errorf("%w: %s", ErrConnectFailed, err.Error())
 	}
-
-	cSimVar, err := sc.ConnectToSimVar(convertToSimSimVar(reflect.ValueOf(report))...)
-	if err != nil {
-		s.Logger.Errorf("ConnectToSimVar(): connection to MSFS has been failed. Reason: %s", err.Error())
-		return
+	sc := conn.sc
+	defer sc.Close()
+	session.connected()
+
+	changedOnly := true
+	for _, r := range reports {
+		val := reflect.ValueOf(r.Report)
+		simVars, err := convertToSimSimVar(val)
+		if err != nil {
+			return fmt.Errorf("%w: %s", ErrConnectFailed, err.Error())
+		}
+		connectToSimVar := sc.ConnectToSimVarEvery
+		if r.ChangedOnly {
+			connectToSimVar = sc.ConnectToSimVarChanged
+		}
+		changedOnly = changedOnly && r.ChangedOnly
+		cSimVar, err := connectToSimVar(r.Interval, simVars...)
+		if err != nil {
+			return fmt.Errorf("%w: ConnectToSimVar(): %s", ErrConnectFailed, err.Error())
+		}
+		go s.forward(ctx, session, val, r.ChangedOnly, cSimVar, r.C)
 	}
 
 	crashed := sc.ConnectSysEventCrashed()
 	paused := sc.ConnectSysEventPause()
 	airloaded := sc.ConnectSysEventAircraftLoaded()
+	// changed-only reports are silent while the aircraft is parked, the 1sec event keeps the session alive
+	var heartbeat <-chan bool
+	if changedOnly {
+		heartbeat = sc.ConnectSysEvent1sec()
+	}
 
 	for {
 		select {
 		case <-ctx.Done():
 			s.Logger.Warning("Tracking routine will exit")
-			return
-		case sv := <-cSimVar:
-			s.Logger.Debug("Received simVar")
-			lastMessageReceived = time.Now()
-			s.TrackEvent <- convertToInterface(reflect.ValueOf(report), sv)
+			return context.Cause(ctx)
+		case open := <-conn.open:
+			if !open {
+				return ErrSimQuit
+			}
+		case r := <-conn.running:
+			s.Logger.Debugf("Sim running: %v", r)
+			if r {
+				s.setState(StateInFlight)
+			} else {
+				s.setState(StateConnectedInMenu)
+			}
 		case r := <-paused:
-			simPaused = r
-			s.TrackPause <- simPaused
+			session.setPaused(r)
+			s.setPaused(r)
+			select {
+			case s.TrackPause <- r:
+			case <-ctx.Done():
+			}
 		case r := <-airloaded:
 			s.Logger.Debugf("Aircraft: %v", r)
+		case <-heartbeat:
+			session.heartbeat()
 		case <-crashed:
 			s.Logger.Error("Your are crashed !!")
-			<-sc.Close() // Wait close confirmation
+			select {
+			case s.TrackCrash <- true:
+			default:
+			}
+			return ErrCrashed
+		}
+	}
+}
+
+// forward convert the SimVars of a report until ctx is done, a slow reader only delays its own report.
+// With changed the SimVars received are the changed ones, they update the last report
+func (s *SimGo) forward(ctx context.Context, session *trackSession, val reflect.Value, changed bool, cSimVar <-chan []sim.SimVar, c chan<- interface{}) {
+	last := reflect.New(val.Type()).Elem()
+	for {
+		select {
+		case <-ctx.Done():
 			return
+		case sv := <-cSimVar:
+			s.Logger.Debug("Received simVar")
+			session.received()
+			var report interface{}
+			if changed {
+				assignSimVars(last, sv)
+				report = last.Interface()
+			} else {
+				report = convertToInterface(val, sv)
+			}
+			select {
+			case c <- report:
+			case <-ctx.Done():
+				return
+			}
 		}
 	}
 }
 
-func convertToSimSimVar(val reflect.Value) []sim.SimVar {
+func convertToSimSimVar(val reflect.Value) ([]sim.SimVar, error) {
 	vars := make([]sim.SimVar, 0)
 
 	for i := 0; i < val.Type().NumField(); i++ {
-		nameTag, _ := val.Type().Field(i).Tag.Lookup("name")
-		indexTag, _ := val.Type().Field(i).Tag.Lookup("index")
-		unitTag, _ := val.Type().Field(i).Tag.Lookup("unit")
-		settableTag, _ := val.Type().Field(i).Tag.Lookup("settable")
-
-		if nameTag == "" || unitTag == "" {
-			continue
+		simv, ok, err := simVarField(val.Type().Field(i))
+		if err != nil {
+			return nil, err
 		}
-
-		simv := sim.SimVar{
-			Name: nameTag,
-			Unit: sim.SimVarUnit(unitTag),
+		if ok {
+			vars = append(vars, simv)
 		}
+	}
 
-		if indexTag != "" {
-			idx, _ := strconv.Atoi(indexTag)
-			simv.Index = idx
-		}
+	return vars, nil
+}
 
-		if settableTag != "" {
-			simv.Settable = settableTag == "1" || strings.ToLower(settableTag) == "true"
-		}
+// simVarField return the SimVar described by the name, index, unit, settable and epsilon tags of a field
+func simVarField(field reflect.StructField) (sim.SimVar, bool, error) {
+	nameTag, _ := field.Tag.Lookup("name")
+	indexTag, _ := field.Tag.Lookup("index")
+	unitTag, _ := field.Tag.Lookup("unit")
+	settableTag, _ := field.Tag.Lookup("settable")
+	epsilonTag, _ := field.Tag.Lookup("epsilon")
+
+	if nameTag == "" || unitTag == "" {
+		return sim.SimVar{}, false, nil
+	}
+
+	simv := sim.SimVar{
+		Name: nameTag,
+		Unit: sim.SimVarUnit(unitTag),
+	}
+
+	if indexTag != "" {
+		idx, _ := strconv.Atoi(indexTag)
+		simv.Index = idx
+	}
 
-		vars = append(vars, simv)
+	if settableTag != "" {
+		simv.Settable = settableTag == "1" || strings.ToLower(settableTag) == "true"
+	}
+
+	if epsilonTag != "" {
+		epsilon, err := strconv.ParseFloat(epsilonTag, 32)
+		if err != nil {
+			return sim.SimVar{}, false, fmt.Errorf("epsilon of %s: %w", field.Name, err)
+		}
+		simv.Epsilon = float32(epsilon)
 	}
 
-	return vars
+	return simv, true, nil
 }
 
 func convertToInterface(val reflect.Value, vars []sim.SimVar) interface{} {
-	found := make([]string, 0)
 	r := reflect.New(reflect.TypeOf(val.Interface())).Elem()
+	assignSimVars(r, vars)
+	return r.Interface()
+}
+
+// assignSimVars set the fields of r tagged with the name and index of vars, the other fields are unchanged
+func assignSimVars(r reflect.Value, vars []sim.SimVar) {
+	val := r
+	found := make([]string, 0)
 	for _, simVar := range vars {
 		//fmt.Printf("iterateSimVars(): Name: %s                                               Index: %b    Unit: %s\n", simVar.Name, simVar.Index, simVar.Unit)
 		for j := 0; j < val.NumField(); j++ {
@@ -260,26 +408,4 @@ func convertToInterface(val reflect.Value, vars []sim.SimVar) interface{} {
 			}
 		}
 	}
-	return r.Interface()
-}
-
-func (s *SimGo) recoverer(maxPanics, id int, f func()) {
-	defer func() {
-		if err := recover(); err != nil {
-			s.Logger.Error(err)
-			if maxPanics == 0 {
-				panic("SimGo exceeded max tries. Exiting...")
-			} else {
-				if err.(string) != "context canceled" {
-					maxPanics -= 1
-					s.Logger.Info("Panic caused by error")
-				} else {
-					maxPanics = maxTriesInitial
-				}
-				s.Logger.Info("Recovering...")
-				go s.recoverer(maxPanics, id, f)
-			}
-		}
-	}()
-	f()
 }
@@ -1,12 +1,17 @@
 package simgo
 
 import (
+	"context"
 	"reflect"
 	"strconv"
 	"testing"
+	"time"
 
 	"github.com/flysim-apps/simgo/simconnect"
+	"github.com/flysim-apps/simgo/simconnect/simtest"
+	"github.com/op/go-logging"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 type ReportTest struct {
@@ -22,7 +27,8 @@ type ReportTest struct {
 }
 
 func TestConvertToSimSimVar(t *testing.T) {
-	vars := convertToSimSimVar(reflect.ValueOf(ReportTest{}))
+	vars, err := convertToSimSimVar(reflect.ValueOf(ReportTest{}))
+	require.NoError(t, err)
 	assert.Greater(t, len(vars), 0, "vars is zero")
 }
 
@@ -36,7 +42,8 @@ func TestConvertToInterface(t *testing.T) {
 
 func TestConvertWithReflect(t *testing.T) {
 	some := func(v interface{}) {
-		vars := convertToSimSimVar(reflect.ValueOf(v))
+		vars, err := convertToSimSimVar(reflect.ValueOf(v))
+		require.NoError(t, err)
 		assert.Greater(t, len(vars), 0, "vars is zero")
 
 		val := reflect.ValueOf(v)
@@ -60,5 +67,356 @@ func TestConvertWithReflect(t *testing.T) {
 		}
 	}
 
-	some(Report{})
+	some(ReportTest{})
+}
+
+func TestTrack(t *testing.T) {
+	srv, err := simtest.NewServer()
+	require.NoError(t, err)
+	defer srv.Close()
+	srv.Aircraft.Set("PLANE ALTITUDE", 1200)
+	srv.Aircraft.Set("PLANE LATITUDE", 48.7)
+
+	s := NewSimGo(logging.MustGetLogger("simgo-test"), SimConnect)
+	s.SimConnectAddr = srv.Addr()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- s.track(ctx, newTrackSession(), "simgo-test", []*TrackedReport{{Report: ReportTest{}, C: s.TrackEvent}})
+	}()
+
+	timeout := time.After(5 * time.Second)
+	for {
+		select {
+		case <-s.TrackPause:
+			continue
+		case event := <-s.TrackEvent:
+			report := event.(ReportTest)
+			assert.Equal(t, 1200.0, report.PlaneAltitude)
+			assert.Equal(t, 48.7, report.Latitude)
+		case <-timeout:
+			t.Fatal("no report received")
+		}
+		break
+	}
+
+	cancel()
+	select {
+	case err := <-done:
+		assert.ErrorIs(t, err, context.Canceled)
+	case <-time.After(5 * time.Second):
+		t.Fatal("track did not exit")
+	}
+}
+
+func TestTrackSeveralSimGo(t *testing.T) {
+	srv, err := simtest.NewServer()
+	require.NoError(t, err)
+	defer srv.Close()
+	srv.Aircraft.Set("PLANE ALTITUDE", 1200)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	trackers := make([]*SimGo, 2)
+	for i := range trackers {
+		s := NewSimGo(logging.MustGetLogger("simgo-test"), SimConnect)
+		s.SimConnectAddr = srv.Addr()
+		s.Context = ctx
+		go func() {
+			for range s.TrackPause {
+			}
+		}()
+		s.TrackWithPolicy("simgo-test", ReportTest{}, RestartPolicy{MaxTries: 1, Backoff: time.Millisecond}, i+1)
+		trackers[i] = s
+	}
+
+	// each SimGo has its own session, channels and state
+	for i, s := range trackers {
+		select {
+		case event := <-s.TrackEvent:
+			assert.Equal(t, 1200.0, event.(ReportTest).PlaneAltitude)
+		case err := <-s.Error:
+			t.Fatalf("tracker %d failed: %s", i+1, err.Error())
+		case <-time.After(5 * time.Second):
+			t.Fatalf("no report received by tracker %d", i+1)
+		}
+		assert.Eventually(t, func() bool { return s.ConnectionState() == StateInFlight }, 2*time.Second, 10*time.Millisecond)
+	}
+}
+
+func TestTrackWithPolicyCrashed(t *testing.T) {
+	srv, err := simtest.NewServer()
+	require.NoError(t, err)
+	defer srv.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	s := NewSimGo(logging.MustGetLogger("simgo-test"), SimConnect)
+	s.SimConnectAddr = srv.Addr()
+	s.Context = ctx
+	go func() {
+		for range s.TrackPause {
+		}
+	}()
+
+	s.TrackWithPolicy("simgo-test", ReportTest{}, RestartPolicy{MaxTries: 0, Backoff: time.Millisecond}, 7)
+	select {
+	case <-s.TrackEvent:
+	case <-time.After(5 * time.Second):
+		t.Fatal("no report received")
+	}
+	srv.Crash()
+
+	for _, reason := range []error{ErrCrashed, ErrExceededRetries} {
+		select {
+		case err := <-s.Error:
+			assert.ErrorIs(t, err, reason)
+			var trackErr *TrackError
+			require.ErrorAs(t, err, &trackErr)
+			assert.Equal(t, 7, trackErr.TrackID)
+		case <-time.After(5 * time.Second):
+			t.Fatalf("no %s error", reason)
+		}
+	}
+}
+
+func TestTrackWithPolicyConnectFailed(t *testing.T) {
+	srv, err := simtest.NewServer()
+	require.NoError(t, err)
+	addr := srv.Addr()
+	srv.Close()
+
+	s := NewSimGo(logging.MustGetLogger("simgo-test"), SimConnect)
+	s.SimConnectAddr = addr
+	s.TrackWithPolicy("simgo-test", ReportTest{}, RestartPolicy{MaxTries: 1, Backoff: time.Millisecond}, 1)
+
+	attempts := []int{1, 2, 2}
+	for i, reason := range []error{ErrConnectFailed, ErrConnectFailed, ErrExceededRetries} {
+		select {
+		case err := <-s.Error:
+			assert.ErrorIs(t, err, reason)
+			var trackErr *TrackError
+			require.ErrorAs(t, err, &trackErr)
+			assert.Equal(t, attempts[i], trackErr.Attempt)
+		case <-time.After(5 * time.Second):
+			t.Fatalf("no %s error", reason)
+		}
+	}
+}
+
+func TestTrackReportsUnsupportedProvider(t *testing.T) {
+	for _, provider := range []Provider{FSUIPC, XPlane, XPlaneWeb} {
+		s := NewSimGo(logging.MustGetLogger("simgo-test"), provider)
+		s.TrackWithRecover("simgo-test", ReportTest{}, 1, 1)
+		select {
+		case err := <-s.Error:
+			assert.ErrorIs(t, err, ErrUnsupportedProvider)
+			assert.ErrorContains(t, err, string(provider))
+		default:
+			t.Fatalf("no error for %s", provider)
+		}
+	}
+}
+
+func TestTrackReports(t *testing.T) {
+	srv, err := simtest.NewServer()
+	require.NoError(t, err)
+	defer srv.Close()
+	srv.Aircraft.Set("PLANE ALTITUDE", 1200)
+	srv.Aircraft.Set("PLANE HEADING DEGREES TRUE", 90)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	s := NewSimGo(logging.MustGetLogger("simgo-test"), SimConnect)
+	s.SimConnectAddr = srv.Addr()
+	s.Context = ctx
+	go func() {
+		for range s.TrackPause {
+		}
+	}()
+
+	type heading struct {
+		Heading float64 `name:"PLANE HEADING DEGREES TRUE" unit:"degrees"`
+	}
+	fast := NewTrackedReport(ReportTest{}, 20*time.Millisecond)
+	slow := NewTrackedReport(heading{}, time.Hour)
+	s.TrackReports("simgo-test", RestartPolicy{MaxTries: 1, Backoff: time.Millisecond}, 1, fast, slow)
+
+	receive := func(c chan interface{}) interface{} {
+		t.Helper()
+		select {
+		case r := <-c:
+			return r
+		case <-time.After(5 * time.Second):
+			t.Fatal("no report received")
+		}
+		return nil
+	}
+	assert.Equal(t, 90.0, receive(slow.C).(heading).Heading)
+	for i := 0; i < 5; i++ {
+		assert.Equal(t, 1200.0, receive(fast.C).(ReportTest).PlaneAltitude)
+	}
+	select {
+	case <-slow.C:
+		t.Fatal("slow report received before its interval")
+	default:
+	}
+
+	// both reports are registered again on the new connection
+	srv.Aircraft.Set("PLANE HEADING DEGREES TRUE", 180)
+	srv.Crash()
+	select {
+	case err := <-s.Error:
+		assert.ErrorIs(t, err, ErrCrashed)
+	case <-time.After(5 * time.Second):
+		t.Fatal("no crash error")
+	}
+	assert.Equal(t, 180.0, receive(slow.C).(heading).Heading)
+	receive(fast.C)
+}
+
+func TestTrackReportsChangedOnly(t *testing.T) {
+	srv, err := simtest.NewServer()
+	require.NoError(t, err)
+	defer srv.Close()
+	srv.Aircraft.Set("PLANE ALTITUDE", 1200)
+	srv.Aircraft.Set("PLANE HEADING DEGREES TRUE", 90)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	s := NewSimGo(logging.MustGetLogger("simgo-test"), SimConnect)
+	s.SimConnectAddr = srv.Addr()
+	s.Context = ctx
+	go func() {
+		for range s.TrackPause {
+		}
+	}()
+
+	type position struct {
+		Altitude float64 `name:"PLANE ALTITUDE" unit:"feet" epsilon:"10"`
+		Heading  float64 `name:"PLANE HEADING DEGREES TRUE" unit:"degrees"`
+	}
+	report := NewTrackedReport(position{}, 50*time.Millisecond)
+	report.ChangedOnly = true
+	s.TrackReports("simgo-test", RestartPolicy{}, 1, report)
+
+	next := func() position {
+		t.Helper()
+		select {
+		case r := <-report.C:
+			return r.(position)
+		case <-time.After(5 * time.Second):
+			t.Fatal("no report received")
+		}
+		return position{}
+	}
+	assert.Equal(t, position{1200, 90}, next())
+
+	srv.Aircraft.Set("PLANE ALTITUDE", 1205)
+	select {
+	case r := <-report.C:
+		t.Fatalf("change under epsilon received: %v", r)
+	case <-time.After(300 * time.Millisecond):
+	}
+
+	srv.Aircraft.Set("PLANE HEADING DEGREES TRUE", 180)
+	assert.Equal(t, position{1200, 180}, next(), "unchanged fields keep their last value")
+}
+
+func TestTrackReportsChangedOnlyParked(t *testing.T) {
+	defer func(timeout time.Duration) { defaultStaleTimeout = timeout }(defaultStaleTimeout)
+	// the dispatch of the session sleeps up to 500ms when idle
+	defaultStaleTimeout = 1500 * time.Millisecond
+
+	srv, err := simtest.NewServer()
+	require.NoError(t, err)
+	defer srv.Close()
+	srv.Aircraft.Set("PLANE ALTITUDE", 1200)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	s := NewSimGo(logging.MustGetLogger("simgo-test"), SimConnect)
+	s.SimConnectAddr = srv.Addr()
+	s.Context = ctx
+	go func() {
+		for range s.TrackPause {
+		}
+	}()
+	go func() {
+		ticker := time.NewTicker(50 * time.Millisecond)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				srv.FireSystemEvent(simconnect.SystemEvent1sec, 0)
+			}
+		}
+	}()
+
+	type altitude struct {
+		Altitude float64 `name:"PLANE ALTITUDE" unit:"feet" epsilon:"10"`
+	}
+	report := NewTrackedReport(altitude{}, 50*time.Millisecond)
+	report.ChangedOnly = true
+	s.TrackReports("simgo-test", RestartPolicy{Backoff: time.Millisecond}, 1, report)
+	select {
+	case r := <-report.C:
+		assert.Equal(t, altitude{1200}, r)
+	case <-time.After(5 * time.Second):
+		t.Fatal("no report received")
+	}
+
+	// the aircraft stays parked for several stale windows
+	select {
+	case err := <-s.Error:
+		t.Fatalf("parked session failed: %s", err)
+	case r := <-report.C:
+		t.Fatalf("unchanged report received: %v", r)
+	case <-time.After(2 * defaultStaleTimeout):
+	}
+}
+
+func TestSimVarFieldEpsilon(t *testing.T) {
+	_, err := convertToSimSimVar(reflect.ValueOf(struct {
+		Altitude float64 `name:"PLANE ALTITUDE" unit:"feet" epsilon:"ten"`
+	}{}))
+	assert.Error(t, err)
+}
+
+func TestRestartPolicyDelay(t *testing.T) {
+	p := RestartPolicy{Backoff: time.Second, MaxBackoff: 5 * time.Second}
+	assert.Equal(t, time.Second, p.delay(1))
+	assert.Equal(t, 2*time.Second, p.delay(2))
+	assert.Equal(t, 4*time.Second, p.delay(3))
+	assert.Equal(t, 5*time.Second, p.delay(4))
+	assert.Equal(t, time.Second, RestartPolicy{}.delay(1))
+}
+
+func TestTrackSessionStale(t *testing.T) {
+	now := time.Now()
+	first, second := newTrackSession(), newTrackSession()
+
+	_, stale := first.stale(now)
+	assert.False(t, stale, "never connected")
+
+	first.connecting()
+	first.connected()
+	second.connecting()
+	second.connected()
+	second.mu.Lock()
+	second.lastMessageReceived = now.Add(-time.Minute)
+	second.mu.Unlock()
+
+	_, stale = first.stale(now)
+	assert.False(t, stale)
+	reason, stale := second.stale(now)
+	assert.True(t, stale)
+	assert.Contains(t, reason, "15s ago")
+
+	second.connecting()
+	_, stale = second.stale(now)
+	assert.False(t, stale, "connection in progress for less than 2m")
 }
@@ -0,0 +1,78 @@
+package simconnect
+
+import (
+	"context"
+	"fmt"
+)
+
+// aiCreate send a creation and wait its object ID
+func (esc *EasySimConnect) aiCreate(ctx context.Context, name string, create func(requestID uint32) (error, uint32)) (uint32, error) {
+	objectID, err := esc.request(ctx, name, create)
+	if err != nil {
+		return 0, err
+	}
+	return objectID.(uint32), nil
+}
+
+// AICreateParkedATCAircraft create an aircraft parked at airportID and controlled by ATC, it return its object ID
+func (esc *EasySimConnect) AICreateParkedATCAircraft(ctx context.Context, containerTitle, tailNumber, airportID string) (uint32, error) {
+	return esc.aiCreate(ctx, "AICreateParkedATCAircraft", func(requestID uint32) (error, uint32) {
+		return esc.sc.AICreateParkedATCAircraft(containerTitle, tailNumber, airportID, requestID)
+	})
+}
+
+// AICreateEnrouteATCAircraft create an aircraft flying the flight plan flightPlanPath (without the .pln extension)
+// from flightPlanPosition (0 is the departure, 1.5 halfway of the second leg), it return its object ID
+func (esc *EasySimConnect) AICreateEnrouteATCAircraft(ctx context.Context, containerTitle, tailNumber string, flightNumber int, flightPlanPath string, flightPlanPosition float64, touchAndGo bool) (uint32, error) {
+	return esc.aiCreate(ctx, "AICreateEnrouteATCAircraft", func(requestID uint32) (error, uint32) {
+		return esc.sc.AICreateEnrouteATCAircraft(containerTitle, tailNumber, flightNumber, flightPlanPath, flightPlanPosition, touchAndGo, requestID)
+	})
+}
+
+// AICreateNonATCAircraft create an aircraft at initPos which is not controlled by ATC, it return its object ID
+func (esc *EasySimConnect) AICreateNonATCAircraft(ctx context.Context, containerTitle, tailNumber string, initPos SIMCONNECT_DATA_INITPOSITION) (uint32, error) {
+	return esc.aiCreate(ctx, "AICreateNonATCAircraft", func(requestID uint32) (error, uint32) {
+		return esc.sc.AICreateNonATCAircraft(containerTitle, tailNumber, initPos, requestID)
+	})
+}
+
+// AICreateSimulatedObject create a ground vehicle, a boat or a static object at initPos, it return its object ID
+func (esc *EasySimConnect) AICreateSimulatedObject(ctx context.Context, containerTitle string, initPos SIMCONNECT_DATA_INITPOSITION) (uint32, error) {
+	return esc.aiCreate(ctx, "AICreateSimulatedObject", func(requestID uint32) (error, uint32) {
+		return esc.sc.AICreateSimulatedObject(containerTitle, initPos, requestID)
+	})
+}
+
+// aiControl send a call on an existing AI object, the exception of an unknown objectID is returned as an error
+func (esc *EasySimConnect) aiControl(name string, call func(requestID uint32) (error, uint32)) error {
+	requestID := esc.nextRequestID()
+	exception, err := esc.check(func() (error, uint32) { return call(requestID) })
+	if err != nil {
+		return fmt.Errorf("Error in %s : %#v", name, err)
+	}
+	if exception != nil {
+		return fmt.Errorf("Error in %s : %s", name, getTextException(exception.dwException))
+	}
+	return nil
+}
+
+// AIReleaseControl stop the AI of objectID, its position is then set with SetSimObjectOn
+func (esc *EasySimConnect) AIReleaseControl(objectID uint32) error {
+	return esc.aiControl("AIReleaseControl", func(requestID uint32) (error, uint32) {
+		return esc.sc.AIReleaseControl(objectID, requestID)
+	})
+}
+
+// AIRemoveObject remove an object created with EasySimConnect
+func (esc *EasySimConnect) AIRemoveObject(objectID uint32) error {
+	return esc.aiControl("AIRemoveObject", func(requestID uint32) (error, uint32) {
+		return esc.sc.AIRemoveObject(objectID, requestID)
+	})
+}
+
+// AISetAircraftFlightPlan give the flight plan flightPlanPath (without the .pln extension) to an AI aircraft
+func (esc *EasySimConnect) AISetAircraftFlightPlan(objectID uint32, flightPlanPath string) error {
+	return esc.aiControl("AISetAircraftFlightPlan", func(requestID uint32) (error, uint32) {
+		return esc.sc.AISetAircraftFlightPlan(objectID, flightPlanPath, requestID)
+	})
+}
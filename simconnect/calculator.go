@@ -0,0 +1,24 @@
+package simconnect
+
+import (
+	"strings"
+)
+
+// ExecuteCalculatorCode run RPN calculator code in the simulator, like 1 (>K:TOGGLE_ICING) or
+// (L:A32NX_SEAT_BELT) ! (>L:A32NX_SEAT_BELT)
+func (lc *LVarClient) ExecuteCalculatorCode(code string) error {
+	lc.mu.Lock()
+	defer lc.mu.Unlock()
+	return lc.command("MF.SimVars.Set." + code)
+}
+
+// hEventName return the name of an H:event without the H: prefix, A320_Neo_CDU_1_BTN_A for (>H:A320_Neo_CDU_1_BTN_A)
+func hEventName(name string) string {
+	name = strings.TrimSuffix(strings.TrimPrefix(name, "("), ")")
+	return strings.TrimPrefix(strings.TrimPrefix(name, ">"), "H:")
+}
+
+// FireHEvent fire an H:event of the aircraft, the buttons of most glass cockpits are H:events
+func (lc *LVarClient) FireHEvent(name string) error {
+	return lc.ExecuteCalculatorCode("(>H:" + hEventName(name) + ")")
+}
@@ -0,0 +1,247 @@
+package simconnect
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"time"
+	"unsafe"
+)
+
+// MapClientDataNameToID map the client data area name and return its client data ID, an area is mapped once
+func (esc *EasySimConnect) MapClientDataNameToID(name string) (uint32, error) {
+	esc.requestMu.Lock()
+	defer esc.requestMu.Unlock()
+	if clientDataID, found := esc.clientDataIDs[name]; found {
+		return clientDataID, nil
+	}
+	esc.indexRequest++
+	clientDataID := esc.indexRequest
+	err := esc.expect(func() (error, uint32) { return esc.sc.MapClientDataNameToID(name, clientDataID) })
+	if err != nil {
+		return 0, fmt.Errorf("Error in MapClientDataNameToID : %#v", err)
+	}
+	esc.clientDataIDs[name] = clientDataID
+	return clientDataID, nil
+}
+
+// CreateClientData create the client data area name of size bytes (up to SIMCONNECT_CLIENTDATA_MAX_SIZE),
+// only EasySimConnect can write into a readOnly area. An area already created returns
+// SIMCONNECT_EXCEPTION_ALREADY_CREATED
+func (esc *EasySimConnect) CreateClientData(name string, size uint32, readOnly bool) error {
+	clientDataID, err := esc.MapClientDataNameToID(name)
+	if err != nil {
+		return err
+	}
+	flags := uint32(SIMCONNECT_CREATE_CLIENT_DATA_FLAG_DEFAULT)
+	if readOnly {
+		flags = SIMCONNECT_CREATE_CLIENT_DATA_FLAG_READ_ONLY
+	}
+	exception, err := esc.check(func() (error, uint32) { return esc.sc.CreateClientData(clientDataID, size, flags) })
+	if err != nil {
+		return fmt.Errorf("Error in CreateClientData : %#v", err)
+	}
+	if exception != nil {
+		return fmt.Errorf("Error in CreateClientData : %s", getTextException(exception.dwException))
+	}
+	return nil
+}
+
+// clientDataFixedSize return true when t holds only numbers, booleans and arrays or structs of them
+func clientDataFixedSize(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Bool, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Float32, reflect.Float64:
+		return true
+	case reflect.Array:
+		return clientDataFixedSize(t.Elem())
+	case reflect.Struct:
+		for i := 0; i < t.NumField(); i++ {
+			if !clientDataFixedSize(t.Field(i).Type) {
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
+
+// clientDataDefinition return the client data definition of T and its size. T is a single datum at the
+// offset 0 of the area, its fields are laid out like a C structure
+func clientDataDefinition[T any](esc *EasySimConnect) (uint32, uint32, error) {
+	t := reflect.TypeOf((*T)(nil)).Elem()
+	if !clientDataFixedSize(t) || t.Size() == 0 {
+		return 0, 0, fmt.Errorf("%s is not a fixed size client data", t)
+	}
+	esc.requestMu.Lock()
+	defer esc.requestMu.Unlock()
+	if defineID, found := esc.clientDataDefs[t]; found {
+		return defineID, uint32(t.Size()), nil
+	}
+	esc.indexRequest++
+	defineID := esc.indexRequest
+	err := esc.expect(func() (error, uint32) {
+		return esc.sc.AddToClientDataDefinition(defineID, 0, uint32(t.Size()), 0, SIMCONNECT_UNUSED)
+	})
+	if err != nil {
+		return 0, 0, fmt.Errorf("Error in AddToClientDataDefinition : %#v", err)
+	}
+	esc.clientDataDefs[t] = defineID
+	return defineID, uint32(t.Size()), nil
+}
+
+// clientDataValue decode data into a T, false when data is too short
+func clientDataValue[T any](data []byte) (T, bool) {
+	var value T
+	size := int(unsafe.Sizeof(value))
+	if len(data) < size {
+		return value, false
+	}
+	copy(unsafe.Slice((*byte)(unsafe.Pointer(&value)), size), data)
+	return value, true
+}
+
+// clientData deliver the data of a SIMCONNECT_RECV_CLIENT_DATA to its subscription or to the request waiting
+// for it
+func (esc *EasySimConnect) clientData(requestID uint32, data []byte) {
+	esc.requestMu.Lock()
+	c, found := esc.listClientData[requestID]
+	esc.requestMu.Unlock()
+	if !found {
+		esc.reply(requestID, data)
+		return
+	}
+	select {
+	case c <- data:
+	case <-time.After(esc.updateDelay()):
+	}
+}
+
+// ReadClientData return the content of the client data area name as a T
+func ReadClientData[T any](ctx context.Context, esc *EasySimConnect, name string) (T, error) {
+	var value T
+	clientDataID, err := esc.MapClientDataNameToID(name)
+	if err != nil {
+		return value, err
+	}
+	defineID, _, err := clientDataDefinition[T](esc)
+	if err != nil {
+		return value, err
+	}
+	data, err := esc.request(ctx, "RequestClientData", func(requestID uint32) (error, uint32) {
+		return esc.sc.RequestClientData(clientDataID, requestID, defineID, SIMCONNECT_CLIENT_DATA_PERIOD_ONCE, 0, 0, 0, 0)
+	})
+	if err != nil {
+		return value, err
+	}
+	value, ok := clientDataValue[T](data.([]byte))
+	if !ok {
+		return value, fmt.Errorf("Error in RequestClientData : %d bytes received for %T", len(data.([]byte)), value)
+	}
+	return value, nil
+}
+
+// SubscribeClientData return a chan receiving the client data area name as a T each time it is set with a new
+// value, until ctx is done. The exception of an area not created yet is logged and the chan receives nothing
+func SubscribeClientData[T any](ctx context.Context, esc *EasySimConnect, name string) (<-chan T, error) {
+	clientDataID, err := esc.MapClientDataNameToID(name)
+	if err != nil {
+		return nil, err
+	}
+	defineID, _, err := clientDataDefinition[T](esc)
+	if err != nil {
+		return nil, err
+	}
+	cData, err := esc.subscribeClientData(ctx, clientDataID, defineID, SIMCONNECT_CLIENT_DATA_REQUEST_FLAG_CHANGED)
+	if err != nil {
+		return nil, err
+	}
+
+	c := make(chan T)
+	go func() {
+		defer close(c)
+		for data := range cData {
+			value, ok := clientDataValue[T](data)
+			if !ok {
+				esc.logf(LogWarn, "Client data %s : %d bytes received for %T", name, len(data), value)
+				continue
+			}
+			select {
+			case c <- value:
+			case <-ctx.Done():
+			}
+		}
+	}()
+	return c, nil
+}
+
+// subscribeClientData return a chan receiving the data of the definition defineID each time the client data area
+// clientDataID is set, or only when it changes with SIMCONNECT_CLIENT_DATA_REQUEST_FLAG_CHANGED. The request is
+// stopped and the chan closed when ctx is done
+func (esc *EasySimConnect) subscribeClientData(ctx context.Context, clientDataID, defineID, flags uint32) (<-chan []byte, error) {
+	cData := make(chan []byte, 1)
+	requestID := esc.nextRequestID()
+	esc.requestMu.Lock()
+	esc.listClientData[requestID] = cData
+	esc.requestMu.Unlock()
+	unregister := func() {
+		esc.requestMu.Lock()
+		delete(esc.listClientData, requestID)
+		esc.requestMu.Unlock()
+	}
+	err := esc.expect(func() (error, uint32) {
+		return esc.sc.RequestClientData(clientDataID, requestID, defineID, SIMCONNECT_CLIENT_DATA_PERIOD_ON_SET, flags, 0, 0, 0)
+	})
+	if err != nil {
+		unregister()
+		return nil, fmt.Errorf("Error in RequestClientData : %#v", err)
+	}
+
+	c := make(chan []byte)
+	go func() {
+		defer close(c)
+		defer func() {
+			unregister()
+			if esc.alive.Load() {
+				esc.expect(func() (error, uint32) {
+					return esc.sc.RequestClientData(clientDataID, requestID, defineID, SIMCONNECT_CLIENT_DATA_PERIOD_NEVER, 0, 0, 0, 0)
+				})
+			}
+		}()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case data := <-cData:
+				select {
+				case c <- data:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+	return c, nil
+}
+
+// SetClientData write value at the beginning of the client data area name, the exception of an area not created
+// yet or read only is logged
+func SetClientData[T any](esc *EasySimConnect, name string, value T) error {
+	clientDataID, err := esc.MapClientDataNameToID(name)
+	if err != nil {
+		return err
+	}
+	defineID, size, err := clientDataDefinition[T](esc)
+	if err != nil {
+		return err
+	}
+	data := make([]byte, size)
+	copy(data, unsafe.Slice((*byte)(unsafe.Pointer(&value)), size))
+	err = esc.expect(func() (error, uint32) {
+		return esc.sc.SetClientData(clientDataID, defineID, SIMCONNECT_CLIENT_DATA_SET_FLAG_DEFAULT, 0, size, data)
+	})
+	if err != nil {
+		return fmt.Errorf("Error in SetClientData : %#v", err)
+	}
+	return nil
+}
@@ -74,6 +74,17 @@ const (
 	SIMCONNECT_RECV_ID_EVENT_MULTIPLAYER_SESSION_ENDED
 	SIMCONNECT_RECV_ID_EVENT_RACE_END
 	SIMCONNECT_RECV_ID_EVENT_RACE_LAP
+	SIMCONNECT_RECV_ID_EVENT_EX1
+	SIMCONNECT_RECV_ID_FACILITY_DATA
+	SIMCONNECT_RECV_ID_FACILITY_DATA_END
+	SIMCONNECT_RECV_ID_FACILITY_MINIMAL_LIST
+	SIMCONNECT_RECV_ID_JETWAY_DATA
+	SIMCONNECT_RECV_ID_CONTROLLERS_LIST
+	SIMCONNECT_RECV_ID_ACTION_CALLBACK
+	SIMCONNECT_RECV_ID_ENUMERATE_INPUT_EVENTS
+	SIMCONNECT_RECV_ID_GET_INPUT_EVENT
+	SIMCONNECT_RECV_ID_SUBSCRIBE_INPUT_EVENT
+	SIMCONNECT_RECV_ID_ENUMERATE_INPUT_EVENT_PARAMS
 )
 
 const (
@@ -233,6 +244,42 @@ const (
 	SIMCONNECT_FACILITY_LIST_TYPE_COUNT // invalid
 )
 
+// SIMCONNECT_FACILITY_DATA_TYPE type of the objects of SIMCONNECT_RECV_ID_FACILITY_DATA
+const (
+	SIMCONNECT_FACILITY_DATA_AIRPORT = iota
+	SIMCONNECT_FACILITY_DATA_RUNWAY
+	SIMCONNECT_FACILITY_DATA_START
+	SIMCONNECT_FACILITY_DATA_FREQUENCY
+	SIMCONNECT_FACILITY_DATA_HELIPAD
+	SIMCONNECT_FACILITY_DATA_APPROACH
+	SIMCONNECT_FACILITY_DATA_APPROACH_TRANSITION
+	SIMCONNECT_FACILITY_DATA_APPROACH_LEG
+	SIMCONNECT_FACILITY_DATA_FINAL_APPROACH_LEG
+	SIMCONNECT_FACILITY_DATA_MISSED_APPROACH_LEG
+	SIMCONNECT_FACILITY_DATA_DEPARTURE
+	SIMCONNECT_FACILITY_DATA_ARRIVAL
+	SIMCONNECT_FACILITY_DATA_RUNWAY_TRANSITION
+	SIMCONNECT_FACILITY_DATA_ENROUTE_TRANSITION
+	SIMCONNECT_FACILITY_DATA_TAXI_POINT
+	SIMCONNECT_FACILITY_DATA_TAXI_PARKING
+	SIMCONNECT_FACILITY_DATA_TAXI_PATH
+	SIMCONNECT_FACILITY_DATA_TAXI_NAME
+	SIMCONNECT_FACILITY_DATA_JETWAY
+	SIMCONNECT_FACILITY_DATA_VOR
+	SIMCONNECT_FACILITY_DATA_NDB
+	SIMCONNECT_FACILITY_DATA_WAYPOINT
+	SIMCONNECT_FACILITY_DATA_ROUTE
+	SIMCONNECT_FACILITY_DATA_PAVEMENT
+	SIMCONNECT_FACILITY_DATA_APPROACHLIGHTS
+	SIMCONNECT_FACILITY_DATA_VASI
+)
+
+// SIMCONNECT_INPUT_EVENT_TYPE type of the value of an input event
+const (
+	SIMCONNECT_INPUT_EVENT_TYPE_DOUBLE = iota
+	SIMCONNECT_INPUT_EVENT_TYPE_STRING
+)
+
 // SIMCONNECT_VOR_FLAGS flags for SIMCONNECT_RECV_ID_VOR_LIST
 const (
 	SIMCONNECT_RECV_ID_VOR_LIST_HAS_NAV_SIGNAL  = 0x00000001 // Has Nav signal
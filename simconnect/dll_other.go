@@ -0,0 +1,13 @@
+//go:build !windows
+
+package simconnect
+
+// DLLTransport is only available on Windows
+type DLLTransport struct {
+	Transport
+}
+
+// NewDLLTransport always return ErrProviderUnavailable out of Windows
+func NewDLLTransport() (*DLLTransport, error) {
+	return nil, ErrProviderUnavailable
+}
@@ -0,0 +1,356 @@
+//go:build windows
+
+package simconnect
+
+import (
+	"errors"
+	"math"
+	"sync"
+	"unsafe"
+)
+
+// convert string to const char *
+func cChar(str string) uintptr {
+	b := []byte(str + "\x00")
+	return uintptr(unsafe.Pointer(&b[0]))
+}
+
+func cBool(b bool) uintptr {
+	mask := 0
+	if b {
+		mask = 1
+	}
+	return uintptr(mask)
+}
+
+var (
+	_ Transport            = (*DLLTransport)(nil)
+	_ WeatherTransport     = (*DLLTransport)(nil)
+	_ AITransport          = (*DLLTransport)(nil)
+	_ FacilitiesTransport  = (*DLLTransport)(nil)
+	_ ClientDataTransport  = (*DLLTransport)(nil)
+	_ InputEventsTransport = (*DLLTransport)(nil)
+	_ MenuTransport        = (*DLLTransport)(nil)
+)
+
+// DLLTransport is the Transport calling SimConnect.dll
+type DLLTransport struct {
+	hSimConnect uintptr
+	syscallSC   *SyscallSC
+	// mu is held from a call until SimConnect_GetLastSentPacketID returned its packet ID
+	mu sync.Mutex
+}
+
+// NewDLLTransport load SimConnect.dll
+func NewDLLTransport() (*DLLTransport, error) {
+	syscallSC, err := NewSyscallSC()
+	if err != nil {
+		return nil, err
+	}
+	return &DLLTransport{syscallSC: syscallSC}, nil
+}
+
+func (t *DLLTransport) Open(appTitle string) error {
+	err := t.syscallSC.Open(uintptr(unsafe.Pointer(&t.hSimConnect)), cChar(appTitle), uintptr(unsafe.Pointer(nil)), 0, 0, 0)
+	if err != nil {
+		return errors.New("Not connected")
+	}
+	return nil
+}
+
+func (t *DLLTransport) Close() error {
+	return t.syscallSC.Close(t.hSimConnect)
+}
+
+// send make a call to SimConnect.dll and return the ID of the packet it sent, the lock keeps another call
+// from sending between the call and SimConnect_GetLastSentPacketID
+func (t *DLLTransport) send(call func() error) (uint32, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if err := call(); err != nil {
+		return 0, err
+	}
+	var id uint32
+	t.syscallSC.GetLastSentPacketID(t.hSimConnect, uintptr(unsafe.Pointer(&id)))
+	return id, nil
+}
+
+func (t *DLLTransport) GetNextDispatch() ([]byte, error) {
+	var ppData unsafe.Pointer
+	var pcbData uint32
+	err := t.syscallSC.GetNextDispatch(t.hSimConnect, uintptr(unsafe.Pointer(&ppData)), uintptr(unsafe.Pointer(&pcbData)))
+	if err != nil {
+		return nil, err
+	}
+	return convCBytesToGoBytes(ppData, int(pcbData))
+}
+
+func (t *DLLTransport) MapClientEventToSimEvent(EventID uint32, EventName string) (uint32, error) {
+	return t.send(func() error {
+		return t.syscallSC.MapClientEventToSimEvent(t.hSimConnect, uintptr(EventID), cChar(EventName))
+	})
+}
+
+func (t *DLLTransport) TransmitClientEvent(ObjectID uint32, EventID uint32, dwData uint32, GroupID uint32, Flags uint32) (uint32, error) {
+	return t.send(func() error {
+		return t.syscallSC.TransmitClientEvent(t.hSimConnect, uintptr(ObjectID), uintptr(EventID), uintptr(dwData), uintptr(GroupID), uintptr(Flags))
+	})
+}
+
+func (t *DLLTransport) AddClientEventToNotificationGroup(GroupID uint32, EventID uint32, bMaskable bool) (uint32, error) {
+	return t.send(func() error {
+		return t.syscallSC.AddClientEventToNotificationGroup(t.hSimConnect, uintptr(GroupID), uintptr(EventID), cBool(bMaskable))
+	})
+}
+
+func (t *DLLTransport) SetNotificationGroupPriority(GroupID uint32, uPriority uint32) (uint32, error) {
+	return t.send(func() error {
+		return t.syscallSC.SetNotificationGroupPriority(t.hSimConnect, uintptr(GroupID), uintptr(uPriority))
+	})
+}
+
+func (t *DLLTransport) AddToDataDefinition(DefineID uint32, DatumName string, UnitsName string, DatumType uint32, fEpsilon float32, DatumID uint32) (uint32, error) {
+	return t.send(func() error {
+		return t.syscallSC.AddToDataDefinition(t.hSimConnect, uintptr(DefineID), cChar(DatumName), cChar(UnitsName), uintptr(DatumType), uintptr(math.Float32bits(fEpsilon)), uintptr(DatumID))
+	})
+}
+
+func (t *DLLTransport) ClearDataDefinition(DefineID uint32) (uint32, error) {
+	return t.send(func() error {
+		return t.syscallSC.ClearDataDefinition(t.hSimConnect, uintptr(DefineID))
+	})
+}
+
+func (t *DLLTransport) RequestDataOnSimObject(RequestID uint32, DefineID uint32, ObjectID uint32, Period uint32, Flags uint32, origin uint32, interval uint32, limit uint32) (uint32, error) {
+	return t.send(func() error {
+		return t.syscallSC.RequestDataOnSimObject(t.hSimConnect, uintptr(RequestID), uintptr(DefineID), uintptr(ObjectID), uintptr(Period), uintptr(Flags), uintptr(origin), uintptr(interval), uintptr(limit))
+	})
+}
+
+func (t *DLLTransport) RequestDataOnSimObjectType(RequestID uint32, DefineID uint32, dwRadiusMeters uint32, st uint32) (uint32, error) {
+	return t.send(func() error {
+		return t.syscallSC.RequestDataOnSimObjectType(t.hSimConnect, uintptr(RequestID), uintptr(DefineID), uintptr(dwRadiusMeters), uintptr(st))
+	})
+}
+
+func (t *DLLTransport) SetDataOnSimObject(DefineID uint32, ObjectID uint32, Flags uint32, ArrayCount uint32, cbUnitSize uint32, pDataSet []byte) (uint32, error) {
+	return t.send(func() error {
+		return t.syscallSC.SetDataOnSimObject(t.hSimConnect, uintptr(DefineID), uintptr(ObjectID), uintptr(Flags), uintptr(ArrayCount), uintptr(cbUnitSize), uintptr(unsafe.Pointer(&pDataSet[0])))
+	})
+}
+
+func (t *DLLTransport) MapInputEventToClientEvent(GroupID uint32, szInputDefinition string, DownEventID uint32, DownValue uint32, UpEventID uint32, UpValue uint32, bMaskable bool) (uint32, error) {
+	return t.send(func() error {
+		return t.syscallSC.MapInputEventToClientEvent(t.hSimConnect, uintptr(GroupID), cChar(szInputDefinition), uintptr(DownEventID), uintptr(DownValue), uintptr(UpEventID), uintptr(UpValue), cBool(bMaskable))
+	})
+}
+
+func (t *DLLTransport) SetInputGroupState(GroupID uint32, dwState uint32) (uint32, error) {
+	return t.send(func() error {
+		return t.syscallSC.SetInputGroupState(t.hSimConnect, uintptr(GroupID), uintptr(dwState))
+	})
+}
+
+func (t *DLLTransport) SubscribeToSystemEvent(EventID uint32, SystemEventName string) (uint32, error) {
+	return t.send(func() error {
+		return t.syscallSC.SubscribeToSystemEvent(t.hSimConnect, uintptr(EventID), cChar(SystemEventName))
+	})
+}
+
+func (t *DLLTransport) MenuAddItem(szMenuItem string, MenuEventID uint32, dwData uint32) (uint32, error) {
+	return t.send(func() error {
+		return t.syscallSC.MenuAddItem(t.hSimConnect, cChar(szMenuItem), uintptr(MenuEventID), uintptr(dwData))
+	})
+}
+
+func (t *DLLTransport) MenuDeleteItem(MenuEventID uint32) (uint32, error) {
+	return t.send(func() error {
+		return t.syscallSC.MenuDeleteItem(t.hSimConnect, uintptr(MenuEventID))
+	})
+}
+
+func (t *DLLTransport) MenuAddSubItem(MenuEventID uint32, szMenuItem string, SubMenuEventID uint32, dwData uint32) (uint32, error) {
+	return t.send(func() error {
+		return t.syscallSC.MenuAddSubItem(t.hSimConnect, uintptr(MenuEventID), cChar(szMenuItem), uintptr(SubMenuEventID), uintptr(dwData))
+	})
+}
+
+func (t *DLLTransport) MenuDeleteSubItem(MenuEventID uint32, SubMenuEventID uint32) (uint32, error) {
+	return t.send(func() error {
+		return t.syscallSC.MenuDeleteSubItem(t.hSimConnect, uintptr(MenuEventID), uintptr(SubMenuEventID))
+	})
+}
+
+func (t *DLLTransport) Text(tt uint32, fTimeSeconds float32, EventID uint32, pDataSet []byte) (uint32, error) {
+	return t.send(func() error {
+		return t.syscallSC.Text(t.hSimConnect, uintptr(tt), uintptr(math.Float32bits(fTimeSeconds)), uintptr(EventID), uintptr(len(pDataSet)), uintptr(unsafe.Pointer(&pDataSet[0])))
+	})
+}
+
+func (t *DLLTransport) WeatherRequestInterpolatedObservation(RequestID uint32, lat float32, lon float32, alt float32) (uint32, error) {
+	return t.send(func() error {
+		return t.syscallSC.WeatherRequestInterpolatedObservation(t.hSimConnect, uintptr(RequestID), uintptr(math.Float32bits(lat)), uintptr(math.Float32bits(lon)), uintptr(math.Float32bits(alt)))
+	})
+}
+
+func (t *DLLTransport) WeatherRequestObservationAtStation(RequestID uint32, szICAO string) (uint32, error) {
+	return t.send(func() error {
+		return t.syscallSC.WeatherRequestObservationAtStation(t.hSimConnect, uintptr(RequestID), cChar(szICAO))
+	})
+}
+
+func (t *DLLTransport) WeatherRequestObservationAtNearestStation(RequestID uint32, lat float32, lon float32) (uint32, error) {
+	return t.send(func() error {
+		return t.syscallSC.WeatherRequestObservationAtNearestStation(t.hSimConnect, uintptr(RequestID), uintptr(math.Float32bits(lat)), uintptr(math.Float32bits(lon)))
+	})
+}
+
+// cInitPosition pass a SIMCONNECT_DATA_INITPOSITION by value, the x64 calling convention copies structures
+// larger than 8 bytes and passes their address
+func cInitPosition(pos SIMCONNECT_DATA_INITPOSITION) uintptr {
+	return uintptr(unsafe.Pointer(&pos))
+}
+
+func (t *DLLTransport) AICreateParkedATCAircraft(szContainerTitle string, szTailNumber string, szAirportID string, RequestID uint32) (uint32, error) {
+	return t.send(func() error {
+		return t.syscallSC.AICreateParkedATCAircraft(t.hSimConnect, cChar(szContainerTitle), cChar(szTailNumber), cChar(szAirportID), uintptr(RequestID))
+	})
+}
+
+func (t *DLLTransport) AICreateEnrouteATCAircraft(szContainerTitle string, szTailNumber string, iFlightNumber int32, szFlightPlanPath string, dFlightPlanPosition float64, bTouchAndGo bool, RequestID uint32) (uint32, error) {
+	return t.send(func() error {
+		return t.syscallSC.AICreateEnrouteATCAircraft(t.hSimConnect, cChar(szContainerTitle), cChar(szTailNumber), uintptr(iFlightNumber), cChar(szFlightPlanPath), uintptr(math.Float64bits(dFlightPlanPosition)), cBool(bTouchAndGo), uintptr(RequestID))
+	})
+}
+
+func (t *DLLTransport) AICreateNonATCAircraft(szContainerTitle string, szTailNumber string, InitPos SIMCONNECT_DATA_INITPOSITION, RequestID uint32) (uint32, error) {
+	return t.send(func() error {
+		return t.syscallSC.AICreateNonATCAircraft(t.hSimConnect, cChar(szContainerTitle), cChar(szTailNumber), cInitPosition(InitPos), uintptr(RequestID))
+	})
+}
+
+func (t *DLLTransport) AICreateSimulatedObject(szContainerTitle string, InitPos SIMCONNECT_DATA_INITPOSITION, RequestID uint32) (uint32, error) {
+	return t.send(func() error {
+		return t.syscallSC.AICreateSimulatedObject(t.hSimConnect, cChar(szContainerTitle), cInitPosition(InitPos), uintptr(RequestID))
+	})
+}
+
+func (t *DLLTransport) AIReleaseControl(ObjectID uint32, RequestID uint32) (uint32, error) {
+	return t.send(func() error {
+		return t.syscallSC.AIReleaseControl(t.hSimConnect, uintptr(ObjectID), uintptr(RequestID))
+	})
+}
+
+func (t *DLLTransport) AIRemoveObject(ObjectID uint32, RequestID uint32) (uint32, error) {
+	return t.send(func() error {
+		return t.syscallSC.AIRemoveObject(t.hSimConnect, uintptr(ObjectID), uintptr(RequestID))
+	})
+}
+
+func (t *DLLTransport) AISetAircraftFlightPlan(ObjectID uint32, szFlightPlanPath string, RequestID uint32) (uint32, error) {
+	return t.send(func() error {
+		return t.syscallSC.AISetAircraftFlightPlan(t.hSimConnect, uintptr(ObjectID), cChar(szFlightPlanPath), uintptr(RequestID))
+	})
+}
+
+func (t *DLLTransport) SubscribeToFacilities(tt uint32, RequestID uint32) (uint32, error) {
+	return t.send(func() error {
+		return t.syscallSC.SubscribeToFacilities(t.hSimConnect, uintptr(tt), uintptr(RequestID))
+	})
+}
+
+func (t *DLLTransport) UnsubscribeToFacilities(tt uint32) (uint32, error) {
+	return t.send(func() error {
+		return t.syscallSC.UnsubscribeToFacilities(t.hSimConnect, uintptr(tt))
+	})
+}
+
+func (t *DLLTransport) RequestFacilitiesList(tt uint32, RequestID uint32) (uint32, error) {
+	return t.send(func() error {
+		return t.syscallSC.RequestFacilitiesList(t.hSimConnect, uintptr(tt), uintptr(RequestID))
+	})
+}
+
+func (t *DLLTransport) AddToFacilityDefinition(DefineID uint32, FieldName string) (uint32, error) {
+	return t.send(func() error {
+		return t.syscallSC.AddToFacilityDefinition(t.hSimConnect, uintptr(DefineID), cChar(FieldName))
+	})
+}
+
+func (t *DLLTransport) RequestFacilityData(DefineID uint32, RequestID uint32, ICAO string, Region string) (uint32, error) {
+	return t.send(func() error {
+		return t.syscallSC.RequestFacilityData(t.hSimConnect, uintptr(DefineID), uintptr(RequestID), cChar(ICAO), cChar(Region))
+	})
+}
+
+func (t *DLLTransport) EnumerateInputEvents(RequestID uint32) (uint32, error) {
+	return t.send(func() error {
+		return t.syscallSC.EnumerateInputEvents(t.hSimConnect, uintptr(RequestID))
+	})
+}
+
+func (t *DLLTransport) GetInputEvent(RequestID uint32, Hash uint64) (uint32, error) {
+	return t.send(func() error {
+		return t.syscallSC.GetInputEvent(t.hSimConnect, uintptr(RequestID), uintptr(Hash))
+	})
+}
+
+func (t *DLLTransport) SetInputEvent(Hash uint64, cbUnitSize uint32, Value []byte) (uint32, error) {
+	return t.send(func() error {
+		return t.syscallSC.SetInputEvent(t.hSimConnect, uintptr(Hash), uintptr(cbUnitSize), uintptr(unsafe.Pointer(&Value[0])))
+	})
+}
+
+func (t *DLLTransport) SubscribeInputEvent(Hash uint64) (uint32, error) {
+	return t.send(func() error {
+		return t.syscallSC.SubscribeInputEvent(t.hSimConnect, uintptr(Hash))
+	})
+}
+
+func (t *DLLTransport) UnsubscribeInputEvent(Hash uint64) (uint32, error) {
+	return t.send(func() error {
+		return t.syscallSC.UnsubscribeInputEvent(t.hSimConnect, uintptr(Hash))
+	})
+}
+
+func (t *DLLTransport) EnumerateInputEventParams(Hash uint64) (uint32, error) {
+	return t.send(func() error {
+		return t.syscallSC.EnumerateInputEventParams(t.hSimConnect, uintptr(Hash))
+	})
+}
+
+func (t *DLLTransport) MapClientDataNameToID(szClientDataName string, ClientDataID uint32) (uint32, error) {
+	return t.send(func() error {
+		return t.syscallSC.MapClientDataNameToID(t.hSimConnect, cChar(szClientDataName), uintptr(ClientDataID))
+	})
+}
+
+func (t *DLLTransport) CreateClientData(ClientDataID uint32, dwSize uint32, Flags uint32) (uint32, error) {
+	return t.send(func() error {
+		return t.syscallSC.CreateClientData(t.hSimConnect, uintptr(ClientDataID), uintptr(dwSize), uintptr(Flags))
+	})
+}
+
+func (t *DLLTransport) AddToClientDataDefinition(DefineID uint32, dwOffset uint32, dwSizeOrType uint32, fEpsilon float32, DatumID uint32) (uint32, error) {
+	return t.send(func() error {
+		return t.syscallSC.AddToClientDataDefinition(t.hSimConnect, uintptr(DefineID), uintptr(dwOffset), uintptr(dwSizeOrType), uintptr(math.Float32bits(fEpsilon)), uintptr(DatumID))
+	})
+}
+
+func (t *DLLTransport) ClearClientDataDefinition(DefineID uint32) (uint32, error) {
+	return t.send(func() error {
+		return t.syscallSC.ClearClientDataDefinition(t.hSimConnect, uintptr(DefineID))
+	})
+}
+
+func (t *DLLTransport) RequestClientData(ClientDataID uint32, RequestID uint32, DefineID uint32, Period uint32, Flags uint32, origin uint32, interval uint32, limit uint32) (uint32, error) {
+	return t.send(func() error {
+		return t.syscallSC.RequestClientData(t.hSimConnect, uintptr(ClientDataID), uintptr(RequestID), uintptr(DefineID), uintptr(Period), uintptr(Flags), uintptr(origin), uintptr(interval), uintptr(limit))
+	})
+}
+
+func (t *DLLTransport) SetClientData(ClientDataID uint32, DefineID uint32, Flags uint32, dwReserved uint32, cbUnitSize uint32, pDataSet []byte) (uint32, error) {
+	return t.send(func() error {
+		return t.syscallSC.SetClientData(t.hSimConnect, uintptr(ClientDataID), uintptr(DefineID), uintptr(Flags), uintptr(dwReserved), uintptr(cbUnitSize), uintptr(unsafe.Pointer(&pDataSet[0])))
+	})
+}
@@ -2,7 +2,11 @@ package simconnect
 
 import (
 	"context"
+	"encoding/binary"
 	"fmt"
+	"reflect"
+	"sync"
+	"sync/atomic"
 	"time"
 	"unsafe"
 
@@ -23,18 +27,54 @@ const (
 // EasySimConnect for easy use of SimConnect in golang
 // Please show example_test.go for use case
 type EasySimConnect struct {
-	sc           *SimConnect
-	delay        time.Duration
+	sc *SimConnect
+	// delay is a time.Duration, see SetDelay
+	delay atomic.Int64
+	// listMu guards the data definitions (listSimVar to listTraffic) and the client events (indexEvent to
+	// listSimEvent), they are added by the callers while runDispatch reads them
+	listMu       sync.RWMutex
 	listSimVar   [][]SimVar
 	listChan     []chan []SimVar
+	listThrottle []time.Duration
+	listLast     []time.Time
+	listTraffic  []*traffic
 	indexEvent   uint32
 	listEvent    map[uint32]func(interface{})
 	listSimEvent map[KeySimEvent]SimEvent
-	logLevel     EasySimConnectLogLevel
+	logLevel     atomic.Int32
 	cOpen        chan bool
-	alive        bool
-	cException   chan *SIMCONNECT_RECV_EXCEPTION
+	alive        atomic.Bool
 	ctx          context.Context
+	// listRequest are the calls waiting for their answer by request ID
+	requestMu    sync.Mutex
+	listRequest  map[uint32]chan interface{}
+	indexRequest uint32
+	// listSend are the calls expecting an exception by packet ID, done is closed with the reason doneErr
+	// when the session ends
+	sendMu   sync.Mutex
+	listSend map[uint32]chan *SIMCONNECT_RECV_EXCEPTION
+	done     chan struct{}
+	doneErr  error
+	doneOnce sync.Once
+	// listFacilities are the facility subscriptions by request ID, facilityChunks the lists being received
+	listFacilities map[uint32]chan []Facility
+	facilityChunks map[uint32][]Facility
+	// facilityDefinitions are the facility definitions by request, facilityData the airports being received
+	facilityDefinitions map[AirportDataRequest]uint32
+	facilityData        map[uint32]*airportData
+	// clientDataIDs are the client data areas by name, clientDataDefs the client data definitions by Go type
+	// and listClientData the client data subscriptions by request ID
+	clientDataIDs  map[string]uint32
+	clientDataDefs map[reflect.Type]uint32
+	listClientData map[uint32]chan []byte
+	// inputEventChunks are the input event catalogs being received, listInputEvents the input event
+	// subscriptions by hash and inputEventParamRequests the EnumerateInputEventParams requests by hash in
+	// sending order
+	inputEventChunks        map[uint32]InputEvents
+	listInputEvents         map[uint64]map[chan InputEventValue]struct{}
+	inputEventParamRequests map[uint64][]uint32
+	// menuItems are the menu items and sub items by client event ID
+	menuItems map[uint32]*MenuItem
 }
 
 // NewEasySimConnect create instance of EasySimConnect
@@ -43,45 +83,94 @@ func NewEasySimConnect(ctx context.Context) (*EasySimConnect, error) {
 	if err != nil {
 		return nil, err
 	}
+	return newEasySimConnect(ctx, sc), nil
+}
+
+// NewEasySimConnectRemote create instance of EasySimConnect connected with the SimConnect network protocol
+// to address (host:port), like configured in SimConnect.xml of the simulator. SimConnect.dll is not required.
+func NewEasySimConnectRemote(ctx context.Context, address string) *EasySimConnect {
+	return newEasySimConnect(ctx, NewSimConnectRemote(address))
+}
+
+// NewEasySimConnectWithTransport create instance of EasySimConnect on top of any Transport
+func NewEasySimConnectWithTransport(ctx context.Context, transport Transport) *EasySimConnect {
+	return newEasySimConnect(ctx, NewSimConnectWithTransport(transport))
+}
+
+func newEasySimConnect(ctx context.Context, sc *SimConnect) *EasySimConnect {
 	logrus.SetFormatter(&logrus.TextFormatter{ForceColors: true})
-	return &EasySimConnect{
+	esc := &EasySimConnect{
 		sc,
-		100 * time.Millisecond,
+		atomic.Int64{},
+		sync.RWMutex{},
 		make([][]SimVar, 0),
 		make([]chan []SimVar, 0),
+		make([]time.Duration, 0),
+		make([]time.Time, 0),
+		make([]*traffic, 0),
 		0,
 		make(map[uint32]func(interface{})),
 		make(map[KeySimEvent]SimEvent),
-		LogNo,
+		atomic.Int32{},
 		make(chan bool, 1),
-		true,
-		make(chan *SIMCONNECT_RECV_EXCEPTION),
+		atomic.Bool{},
 		ctx,
-	}, nil
+		sync.Mutex{},
+		make(map[uint32]chan interface{}),
+		firstRequestID,
+		sync.Mutex{},
+		make(map[uint32]chan *SIMCONNECT_RECV_EXCEPTION),
+		make(chan struct{}),
+		nil,
+		sync.Once{},
+		make(map[uint32]chan []Facility),
+		make(map[uint32][]Facility),
+		make(map[AirportDataRequest]uint32),
+		make(map[uint32]*airportData),
+		make(map[string]uint32),
+		make(map[reflect.Type]uint32),
+		make(map[uint32]chan []byte),
+		make(map[uint32]InputEvents),
+		make(map[uint64]map[chan InputEventValue]struct{}),
+		make(map[uint64][]uint32),
+		make(map[uint32]*MenuItem),
+	}
+	esc.delay.Store(int64(100 * time.Millisecond))
+	esc.alive.Store(true)
+	return esc
 }
 
 // SetLoggerLevel you can set log level in EasySimConnect
 func (esc *EasySimConnect) SetLoggerLevel(level EasySimConnectLogLevel) {
-	esc.logLevel = level
+	esc.logLevel.Store(int32(level))
 }
 
-// Close Finishing EasySimConnect, All object created with this EasySimConnect's instance is perished after call this function
+// Close Finishing EasySimConnect, All object created with this EasySimConnect's instance is perished after call this function.
+// The menu items are removed from the simulator
 func (esc *EasySimConnect) Close() <-chan bool {
 	if esc == nil {
 		return nil
 	}
-	esc.alive = false
+	if esc.alive.Load() {
+		esc.deleteMenuItems()
+	}
+	esc.alive.Store(false)
 	return esc.cOpen
 }
 
 // IsAlive return true if connected
 func (esc *EasySimConnect) IsAlive() bool {
-	return esc.alive
+	return esc.alive.Load()
 }
 
-// SetDelay Select delay update SimVar and
+// SetDelay Select delay update SimVar for the next ConnectToSimVar
 func (esc *EasySimConnect) SetDelay(t time.Duration) {
-	esc.delay = t
+	esc.delay.Store(int64(t))
+}
+
+// updateDelay return the delay of SetDelay
+func (esc *EasySimConnect) updateDelay() time.Duration {
+	return time.Duration(esc.delay.Load())
 }
 
 // Connect to sim and run dispatch or return error
@@ -95,7 +184,7 @@ func (esc *EasySimConnect) Connect(appName string) (<-chan bool, error) {
 }
 
 func (esc *EasySimConnect) logf(level EasySimConnectLogLevel, format string, args ...interface{}) {
-	if level > esc.logLevel {
+	if level > EasySimConnectLogLevel(esc.logLevel.Load()) {
 		return
 	}
 	if level == LogInfo {
@@ -113,11 +202,11 @@ func (esc *EasySimConnect) runDispatch() {
 	defer func() {
 		esc.logf(LogWarn, "Defer panic in runDispatch()")
 		if r := recover(); r != nil {
-			defer esc.sc.Close()
+			esc.stop(fmt.Errorf("%v", r))
 		}
 	}()
 
-	for esc.alive {
+	for esc.alive.Load() {
 		if esc.ctx.Err() != nil {
 			esc.logf(LogWarn, "Context error, quit")
 
@@ -128,7 +217,7 @@ func (esc *EasySimConnect) runDispatch() {
 		err, _ := esc.sc.GetNextDispatch(&ppdata, &pcbData)
 		//créer un buffer en copy les data ppdata avec longueur pcbdata et utiliser le buffer pour la suite
 		if err != nil {
-			time.Sleep(esc.delay / 2)
+			time.Sleep(esc.updateDelay() / 2)
 			continue
 		}
 		buf, err := convCBytesToGoBytes(ppdata, int(pcbData))
@@ -144,90 +233,247 @@ func (esc *EasySimConnect) runDispatch() {
 			esc.cOpen <- true
 		case SIMCONNECT_RECV_ID_EVENT:
 			recv := *(*SIMCONNECT_RECV_EVENT)(ppdata)
-			cb, found := esc.listEvent[recv.uEventID]
+			if esc.menuSelected(recv.uEventID) {
+				continue
+			}
+			cb, found := esc.event(recv.uEventID)
 			if !found {
 				esc.logf(LogInfo, "Ignored event : %#v\n", recv)
 				continue
 			}
 			cb(recv)
 		case SIMCONNECT_RECV_ID_QUIT:
-			esc.sc.Close()
+			esc.stop(fmt.Errorf("simulator quit"))
 			esc.cOpen <- false
 			return
+		case SIMCONNECT_RECV_ID_ASSIGNED_OBJECT_ID:
+			recv := *(*SIMCONNECT_RECV_ASSIGNED_OBJECT_ID)(ppdata)
+			esc.reply(recv.dwRequestID, recv.dwObjectID)
+		case SIMCONNECT_RECV_ID_WEATHER_OBSERVATION:
+			recv := *(*SIMCONNECT_RECV_WEATHER_OBSERVATION)(ppdata)
+			esc.reply(recv.dwRequestID, convStrToGoString(buf[unsafe.Offsetof(recv.szMetar):]))
+		case SIMCONNECT_RECV_ID_AIRPORT_LIST, SIMCONNECT_RECV_ID_WAYPOINT_LIST, SIMCONNECT_RECV_ID_NDB_LIST, SIMCONNECT_RECV_ID_VOR_LIST:
+			esc.facilitiesList(recvInfo.dwID, buf)
+		case SIMCONNECT_RECV_ID_FACILITY_DATA:
+			esc.facilityDataItem(buf)
+		case SIMCONNECT_RECV_ID_FACILITY_DATA_END:
+			recv := *(*SIMCONNECT_RECV_FACILITY_DATA_END)(ppdata)
+			esc.facilityDataEnd(recv.RequestId)
+		case SIMCONNECT_RECV_ID_CLIENT_DATA:
+			recv := convBytesToSimObjectData(buf)
+			esc.clientData(recv.dwRequestID, buf[unsafe.Offsetof(recv.dwData):])
+		case SIMCONNECT_RECV_ID_ENUMERATE_INPUT_EVENTS:
+			esc.inputEvents(buf)
+		case SIMCONNECT_RECV_ID_GET_INPUT_EVENT:
+			requestID := binary.LittleEndian.Uint32(buf[12:])
+			esc.reply(requestID, convInputEventValue(0, binary.LittleEndian.Uint32(buf[16:]), buf[20:]))
+		case SIMCONNECT_RECV_ID_SUBSCRIBE_INPUT_EVENT:
+			esc.inputEventChanged(buf)
+		case SIMCONNECT_RECV_ID_ENUMERATE_INPUT_EVENT_PARAMS:
+			esc.inputEventParams(buf)
 		case SIMCONNECT_RECV_ID_EVENT_FILENAME:
 			recv := *(*SIMCONNECT_RECV_EVENT_FILENAME)(ppdata)
-			esc.listEvent[recv.uEventID](recv)
+			cb, found := esc.event(recv.uEventID)
+			if !found {
+				esc.logf(LogInfo, "Ignored event : %#v\n", recv)
+				continue
+			}
+			cb(recv)
 		case SIMCONNECT_RECV_ID_EXCEPTION:
-			recv := (*SIMCONNECT_RECV_EXCEPTION)(ppdata)
-			select {
-			case esc.cException <- recv:
-			case <-time.After(100 * time.Millisecond):
+			recv := *(*SIMCONNECT_RECV_EXCEPTION)(ppdata)
+			esc.logf(LogInfo, "SimConnect Exception : %s %#v\n", getTextException(recv.dwException), recv)
+			// the exception of a call waiting for it fails only that call
+			if esc.exception(&recv) && !fatalException(recv.dwException) {
+				continue
 			}
-			esc.logf(LogInfo, "SimConnect Exception : %s %#v\n", getTextException(recv.dwException), *recv)
-			esc.sc.Close()
+			esc.stop(fmt.Errorf("connection closed by %s", getTextException(recv.dwException)))
 			esc.cOpen <- false
 			return
 		case SIMCONNECT_RECV_ID_SIMOBJECT_DATA, SIMCONNECT_RECV_ID_SIMOBJECT_DATA_BYTYPE:
-			recv := *(*SIMCONNECT_RECV_SIMOBJECT_DATA)(ppdata)
-			if len(esc.listSimVar) < int(recv.dwDefineID) {
-				esc.logf(LogWarn, "ListSimVar not found: %#v\n %#v\n %d>=%d", recv, esc.listSimVar, len(esc.listSimVar), int(recv.dwDefineID))
+			recv := convBytesToSimObjectData(buf)
+			listSimVar, traffic, found := esc.definition(recv.dwDefineID)
+			if !found {
+				esc.logf(LogWarn, "ListSimVar not found: %#v\n %d", recv, int(recv.dwDefineID))
+				continue
+			}
+			if traffic != nil && recv.dwoutof == 0 {
+				// no object in the radius
+				traffic.send(traffic.add(recv.dwObjectID, 0, 0, nil))
 				continue
 			}
-			listSimVar := esc.listSimVar[recv.dwDefineID]
-			if len(listSimVar) != int(recv.dwDefineCount) {
+			if recv.dwFlags&SIMCONNECT_DATA_REQUEST_FLAG_TAGGED == 0 && len(listSimVar) != int(recv.dwDefineCount) {
 				esc.logf(LogWarn, "ListSimVar size not equal %#v ?= %#v\n", int(recv.dwDefineCount), len(listSimVar))
 				continue
 			}
 			position := int(unsafe.Offsetof(recv.dwData))
-			returnSimVar := make([]SimVar, len(listSimVar))
-			for i, simVar := range listSimVar {
+			tagged := recv.dwFlags&SIMCONNECT_DATA_REQUEST_FLAG_TAGGED != 0
+			returnSimVar := make([]SimVar, 0, recv.dwDefineCount)
+			for i := 0; i < int(recv.dwDefineCount); i++ {
+				datumID := i
+				if tagged {
+					// only the changed datums are sent, each preceded by its datum ID
+					if position+4 > int(pcbData) {
+						esc.logf(LogError, "slice bounds out of range")
+						break
+					}
+					datumID = int(binary.LittleEndian.Uint32(buf[position:]))
+					position += 4
+				}
+				if datumID >= len(listSimVar) {
+					esc.logf(LogError, "unknown datum ID %d", datumID)
+					break
+				}
+				simVar := listSimVar[datumID]
 				size := simVar.GetSize()
 				if position+size > int(pcbData) {
 					esc.logf(LogError, "slice bounds out of range")
 					break
 				}
 				simVar.data = buf[position : position+size]
-				returnSimVar[i] = simVar
+				returnSimVar = append(returnSimVar, simVar)
 				position = position + size
 			}
+			if traffic != nil {
+				traffic.send(traffic.add(recv.dwObjectID, recv.dwentrynumber, recv.dwoutof, returnSimVar))
+				continue
+			}
+			cSimVar, throttled := esc.throttle(recv.dwDefineID)
+			if throttled {
+				continue
+			}
 			select {
-			case esc.listChan[recv.dwDefineID] <- returnSimVar:
-			case <-time.After(esc.delay):
+			case cSimVar <- returnSimVar:
+			case <-time.After(esc.updateDelay()):
 			}
-			go func() {
-				time.Sleep(esc.delay)
-				esc.sc.RequestDataOnSimObjectType(uint32(0), recv.dwDefineID, uint32(0), uint32(0))
-			}()
 
 		default:
 			esc.logf(LogInfo, "%#v\n", recvInfo)
 		}
 	}
-	esc.sc.Close()
+	esc.stop(errClosed)
 	esc.cOpen <- false
 }
 
+// DataRequest is how often the sim sends the SimVars of ConnectToSimVarRequest
+type DataRequest struct {
+	// Period is one of SIMCONNECT_PERIOD_ONCE, _VISUAL_FRAME, _SIM_FRAME or _SECOND
+	Period uint32
+	// Flags is a combination of SIMCONNECT_DATA_REQUEST_FLAG_*
+	Flags uint32
+	// Origin is the number of periods before the first data
+	Origin uint32
+	// Interval is the number of periods skipped between two data
+	Interval uint32
+	// Limit is the number of data sent, unlimited when zero
+	Limit uint32
+}
+
 // ConnectToSimVar return a chan. This chan return an array when updating they SimVars in order of argument of this function
 func (esc *EasySimConnect) ConnectToSimVar(listSimVar ...SimVar) (<-chan []SimVar, error) {
+	return esc.ConnectToSimVarEvery(esc.updateDelay(), listSimVar...)
+}
+
+// ConnectToSimVarEvery is ConnectToSimVar with its own update interval instead of the delay of SetDelay.
+// Whole seconds are requested with SIMCONNECT_PERIOD_SECOND, shorter intervals with SIMCONNECT_PERIOD_SIM_FRAME
+// throttled to interval. The delay is used when interval is zero
+func (esc *EasySimConnect) ConnectToSimVarEvery(interval time.Duration, listSimVar ...SimVar) (<-chan []SimVar, error) {
+	req, throttle := esc.requestEvery(interval)
+	_, c, err := esc.connectToSimVar(req, throttle, listSimVar)
+	return c, err
+}
+
+// ConnectToSimVarChanged is ConnectToSimVarEvery sending only the SimVars which changed by more than their
+// Epsilon, nothing is sent while they don't change. Intervals shorter than a second are not throttled,
+// the SimVars are compared on every sim frame
+func (esc *EasySimConnect) ConnectToSimVarChanged(interval time.Duration, listSimVar ...SimVar) (<-chan []SimVar, error) {
+	req, _ := esc.requestEvery(interval)
+	req.Flags = SIMCONNECT_DATA_REQUEST_FLAG_CHANGED | SIMCONNECT_DATA_REQUEST_FLAG_TAGGED
+	_, c, err := esc.connectToSimVar(req, 0, listSimVar)
+	return c, err
+}
+
+// requestEvery return the request and the throttle of an update interval
+func (esc *EasySimConnect) requestEvery(interval time.Duration) (DataRequest, time.Duration) {
+	if interval <= 0 {
+		interval = esc.updateDelay()
+	}
+	if interval >= time.Second && interval%time.Second == 0 {
+		return DataRequest{Period: SIMCONNECT_PERIOD_SECOND, Interval: uint32(interval/time.Second) - 1}, 0
+	}
+	return DataRequest{Period: SIMCONNECT_PERIOD_SIM_FRAME}, interval
+}
+
+// ConnectToSimVarRequest is ConnectToSimVar with the period of the data chosen by req
+func (esc *EasySimConnect) ConnectToSimVarRequest(req DataRequest, listSimVar ...SimVar) (<-chan []SimVar, error) {
+	_, c, err := esc.connectToSimVar(req, 0, listSimVar)
+	return c, err
+}
+
+// connectToSimVar add a definition of listSimVar and request its data, it return the define ID and the chan
+func (esc *EasySimConnect) connectToSimVar(req DataRequest, throttle time.Duration, listSimVar []SimVar) (uint32, <-chan []SimVar, error) {
+	defineID, err := esc.addDefinition(listSimVar, throttle, nil)
+	if err != nil {
+		return 0, nil, err
+	}
+	err, _ = esc.sc.RequestDataOnSimObject(defineID, defineID, SIMCONNECT_OBJECT_ID_USER, req.Period, req.Flags, req.Origin, req.Interval, req.Limit)
+	if err != nil {
+		return 0, nil, fmt.Errorf("Error in RequestDataOnSimObject : %#v", err)
+	}
+	esc.listMu.RLock()
+	defer esc.listMu.RUnlock()
+	return defineID, esc.listChan[defineID], nil
+}
+
+// removeDefinition stop the data request of defineID and clear its definition, the data still received for it
+// are dropped
+func (esc *EasySimConnect) removeDefinition(defineID uint32) {
+	var traffic *traffic
+	esc.listMu.Lock()
+	if int(defineID) < len(esc.listSimVar) {
+		traffic = esc.listTraffic[defineID]
+		esc.listSimVar[defineID] = nil
+		esc.listTraffic[defineID] = nil
+	}
+	esc.listMu.Unlock()
+	if !esc.alive.Load() {
+		return
+	}
+	// the traffic is requested once per interval, there is no periodic request to stop
+	if traffic == nil {
+		esc.expect(func() (error, uint32) {
+			return esc.sc.RequestDataOnSimObject(defineID, defineID, SIMCONNECT_OBJECT_ID_USER, SIMCONNECT_PERIOD_NEVER, 0, 0, 0, 0)
+		})
+	}
+	esc.expect(func() (error, uint32) { return esc.sc.ClearDataDefinition(defineID) })
+}
+
+// addDefinition add listSimVar to a new data definition, its data are sent to traffic when not nil
+func (esc *EasySimConnect) addDefinition(listSimVar []SimVar, throttle time.Duration, traffic *traffic) (uint32, error) {
+	// the define ID is reserved, the SimVars are set once the sim accepted them all
+	esc.listMu.Lock()
 	defineID := uint32(len(esc.listSimVar))
+	esc.listSimVar = append(esc.listSimVar, nil)
+	esc.listChan = append(esc.listChan, make(chan []SimVar))
+	esc.listThrottle = append(esc.listThrottle, throttle)
+	esc.listLast = append(esc.listLast, time.Time{})
+	esc.listTraffic = append(esc.listTraffic, traffic)
+	esc.listMu.Unlock()
+
 	addedSimVar := make([]SimVar, 0)
 	for i, simVar := range listSimVar {
-		err, id := esc.sc.AddToDataDefinition(defineID, simVar.getNameForDataDefinition(), simVar.getUnitForDataDefinition(), simVar.GetDatumType(), 0, uint32(i))
+		exception, err := esc.check(func() (error, uint32) {
+			return esc.sc.AddToDataDefinition(defineID, simVar.getNameForDataDefinition(), simVar.getUnitForDataDefinition(), simVar.GetDatumType(), simVar.Epsilon, uint32(i))
+		})
 		if err != nil {
 			esc.logf(LogInfo, "Error add SimVar ( %s ) in AddToDataDefinition error : %#v", simVar.Name, err)
-			return nil, fmt.Errorf(
+			return 0, fmt.Errorf(
 				"Error add SimVar ( %s ) in AddToDataDefinition error : %#v",
 				simVar.Name,
 				err,
 			)
 		}
-		var exception *SIMCONNECT_RECV_EXCEPTION
-		select {
-		case exception = <-esc.cException:
-		case <-time.After(100 * time.Millisecond):
-		}
-		if exception != nil && exception.dwSendID == id {
-			return nil, fmt.Errorf(
+		if exception != nil {
+			return 0, fmt.Errorf(
 				"Error add SimVar ( %s ) in AddToDataDefinition : %s. Please control name ( %s ) and unit ( %s )",
 				simVar.Name,
 				getTextException(exception.dwException),
@@ -237,11 +483,33 @@ func (esc *EasySimConnect) ConnectToSimVar(listSimVar ...SimVar) (<-chan []SimVa
 		}
 		addedSimVar = append(addedSimVar, simVar)
 	}
-	esc.listSimVar = append(esc.listSimVar, addedSimVar)
-	chanSimVar := make(chan []SimVar)
-	esc.listChan = append(esc.listChan, chanSimVar)
-	esc.sc.RequestDataOnSimObjectType(uint32(0), defineID, uint32(0), uint32(0))
-	return chanSimVar, nil
+	esc.listMu.Lock()
+	esc.listSimVar[defineID] = addedSimVar
+	esc.listMu.Unlock()
+	return defineID, nil
+}
+
+// definition return the SimVars and the traffic of a data definition, false when defineID is unknown
+func (esc *EasySimConnect) definition(defineID uint32) ([]SimVar, *traffic, bool) {
+	esc.listMu.RLock()
+	defer esc.listMu.RUnlock()
+	if int(defineID) >= len(esc.listSimVar) || esc.listSimVar[defineID] == nil {
+		return nil, nil, false
+	}
+	return esc.listSimVar[defineID], esc.listTraffic[defineID], true
+}
+
+// throttle return the chan of a data definition, true when its data are dropped. Frame based requests are
+// throttled to the interval of ConnectToSimVarEvery
+func (esc *EasySimConnect) throttle(defineID uint32) (chan []SimVar, bool) {
+	esc.listMu.Lock()
+	defer esc.listMu.Unlock()
+	now := time.Now()
+	if now.Sub(esc.listLast[defineID]) < esc.listThrottle[defineID] {
+		return nil, true
+	}
+	esc.listLast[defineID] = now
+	return esc.listChan[defineID], false
 }
 
 // ConnectToSimVarObject return a chan. This chan return an array when updating they SimVars in order of argument of this function
@@ -289,33 +557,75 @@ func (esc *EasySimConnect) SetSimVarInterfaceInSim(iFace interface{}) error {
 
 // SetSimObject edit the SimVar in the simulator
 func (esc *EasySimConnect) SetSimObject(simVar SimVar) {
-	defineID := uint32(1 << 30)
-	err, _ := esc.sc.AddToDataDefinition(defineID, simVar.Name, simVar.getUnitForDataDefinition(), simVar.GetDatumType(), 0, 0)
-	if err != nil {
-		esc.logf(LogInfo, "Error add SimVar ( %s ) in AddToDataDefinition error : %#v", simVar.Name, err)
-		return
+	if err := esc.SetSimObjectOn(SIMCONNECT_OBJECT_ID_USER, simVar); err != nil {
+		esc.logf(LogInfo, "%s", err)
 	}
-	//esc.listSimVar = append(esc.listSimVar, []*SimVar{&simVar})
-	err, _ = esc.sc.SetDataOnSimObject(defineID, SIMCONNECT_OBJECT_ID_USER, 0, 0, uint32(len(simVar.data)), simVar.data)
+}
+
+// SetSimObjectOn edit the SimVar of the object objectID, like an AI object created with EasySimConnect. Each call
+// uses its own definition so concurrent calls do not mix their SimVars, the exception of an unknown objectID is
+// logged
+func (esc *EasySimConnect) SetSimObjectOn(objectID uint32, simVar SimVar) error {
+	defineID := esc.nextRequestID()
+	err := esc.expect(func() (error, uint32) {
+		return esc.sc.AddToDataDefinition(defineID, simVar.Name, simVar.getUnitForDataDefinition(), simVar.GetDatumType(), 0, 0)
+	})
 	if err != nil {
-		esc.logf(LogInfo, "Error add SimVar ( %s ) in SetDataOnSimObject error : %#v", simVar.Name, err)
-		return
+		return fmt.Errorf("Error add SimVar ( %s ) in AddToDataDefinition error : %#v", simVar.Name, err)
 	}
-	err, _ = esc.sc.ClearDataDefinition(uint32(defineID))
+	defer func() {
+		err := esc.expect(func() (error, uint32) { return esc.sc.ClearDataDefinition(defineID) })
+		if err != nil {
+			esc.logf(LogInfo, "Error add SimVar ( %s ) in ClearDataDefinition error : %#v", simVar.Name, err)
+		}
+	}()
+	err = esc.expect(func() (error, uint32) {
+		return esc.sc.SetDataOnSimObject(defineID, objectID, 0, 0, uint32(len(simVar.data)), simVar.data)
+	})
 	if err != nil {
-		esc.logf(LogInfo, "Error add SimVar ( %s ) in ClearDataDefinition error : %#v", simVar.Name, err)
-		return
+		return fmt.Errorf("Error add SimVar ( %s ) in SetDataOnSimObject error : %#v", simVar.Name, err)
 	}
+	return nil
 }
-func (esc *EasySimConnect) connectSysEvent(name SystemEvent, cb func(interface{})) {
+
+// addEvent register the callback of a new client event and return its ID
+func (esc *EasySimConnect) addEvent(cb func(interface{})) uint32 {
+	esc.listMu.Lock()
+	defer esc.listMu.Unlock()
 	esc.indexEvent++
 	esc.listEvent[esc.indexEvent] = cb
-	err, _ := esc.sc.SubscribeToSystemEvent(uint32(esc.indexEvent), name)
+	return esc.indexEvent
+}
+
+// event return the callback of a client event
+func (esc *EasySimConnect) event(eventID uint32) (func(interface{}), bool) {
+	esc.listMu.RLock()
+	defer esc.listMu.RUnlock()
+	cb, found := esc.listEvent[eventID]
+	return cb, found
+}
+
+func (esc *EasySimConnect) connectSysEvent(name SystemEvent, cb func(interface{})) {
+	eventID := esc.addEvent(cb)
+	err, _ := esc.sc.SubscribeToSystemEvent(eventID, name)
 	if err != nil {
 		esc.logf(LogInfo, "Error connect to Event %s in ConnectSysEventCrashed error : %#v", name, err)
 	}
 }
 
+// ConnectSysEvent1sec Request a notification every second while the sim is running. A notification is dropped
+// when the previous one was not read.
+func (esc *EasySimConnect) ConnectSysEvent1sec() <-chan bool {
+	c := make(chan bool, 1)
+	esc.connectSysEvent(SystemEvent1sec, func(data interface{}) {
+		select {
+		case c <- true:
+		default:
+		}
+	})
+	return c
+}
+
 // ConnectSysEventCrashed Request a notification if the user aircraft crashes.
 func (esc *EasySimConnect) ConnectSysEventCrashed() <-chan bool {
 	c := make(chan bool)
@@ -417,11 +727,10 @@ func (esc *EasySimConnect) ConnectSysEventFlightPlanActivated() <-chan string {
 // ime is in second and return chan a confirmation for the simulator
 func (esc *EasySimConnect) ShowText(str string, time float32, color PrintColor) (<-chan int, error) {
 	cReturn := make(chan int)
-	esc.indexEvent++
-	esc.listEvent[esc.indexEvent] = func(data interface{}) {
+	eventID := esc.addEvent(func(data interface{}) {
 		cReturn <- int(data.(SIMCONNECT_RECV_EVENT).dwData)
-	}
-	err, _ := esc.sc.Text(uint32(color), time, esc.indexEvent, str)
+	})
+	err, _ := esc.sc.Text(uint32(color), time, eventID, str)
 	return cReturn, err
 }
 func (esc *EasySimConnect) runSimEvent(simEvent SimEvent) {
@@ -430,6 +739,8 @@ func (esc *EasySimConnect) runSimEvent(simEvent SimEvent) {
 
 // NewSimEvent return new instance of SimEvent and you can run SimEvent.Run()
 func (esc *EasySimConnect) NewSimEvent(simEventStr KeySimEvent) SimEvent {
+	esc.listMu.Lock()
+	defer esc.listMu.Unlock()
 	instance, found := esc.listSimEvent[simEventStr]
 	if found {
 		return instance
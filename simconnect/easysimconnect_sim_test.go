@@ -0,0 +1,805 @@
+package simconnect_test
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/flysim-apps/simgo/simconnect"
+	"github.com/flysim-apps/simgo/simconnect/simtest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func connectSim(t *testing.T) (*simtest.Server, *simconnect.EasySimConnect) {
+	srv, err := simtest.NewServer()
+	require.NoError(t, err)
+	t.Cleanup(func() { srv.Close() })
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	esc := simconnect.NewEasySimConnectRemote(ctx, srv.Addr())
+	c, err := esc.Connect("simgo-test")
+	require.NoError(t, err)
+	select {
+	case open := <-c:
+		require.True(t, open)
+	case <-time.After(2 * time.Second):
+		t.Fatal("connection not confirmed")
+	}
+	return srv, esc
+}
+
+func TestSimConnectToSimVar(t *testing.T) {
+	srv, esc := connectSim(t)
+	srv.Aircraft.Set("PLANE ALTITUDE", 1500)
+
+	cSimVar, err := esc.ConnectToSimVar(simconnect.SimVarPlaneAltitude())
+	require.NoError(t, err)
+	select {
+	case vars := <-cSimVar:
+		require.Len(t, vars, 1)
+		f, err := vars[0].GetFloat64()
+		require.NoError(t, err)
+		assert.Equal(t, 1500.0, f)
+	case <-time.After(2 * time.Second):
+		t.Fatal("no SimVar received")
+	}
+}
+
+func TestSimConnectToSimVarUnrecognized(t *testing.T) {
+	srv, esc := connectSim(t)
+	srv.Strict = true
+
+	_, err := esc.ConnectToSimVar(simconnect.SimVarPlaneAltitude())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "SIMCONNECT_EXCEPTION_NAME_UNRECOGNIZED")
+}
+
+func TestSimConnectToSimVarRequest(t *testing.T) {
+	srv, esc := connectSim(t)
+	srv.Aircraft.Set("PLANE ALTITUDE", 1500)
+
+	limited, err := esc.ConnectToSimVarRequest(simconnect.DataRequest{
+		Period:   simconnect.SIMCONNECT_PERIOD_SIM_FRAME,
+		Origin:   2,
+		Interval: 1,
+		Limit:    3,
+	}, simconnect.SimVarPlaneAltitude())
+	require.NoError(t, err)
+	for i := 0; i < 3; i++ {
+		select {
+		case vars := <-limited:
+			f, err := vars[0].GetFloat64()
+			require.NoError(t, err)
+			assert.Equal(t, 1500.0, f)
+		case <-time.After(2 * time.Second):
+			t.Fatalf("data %d not received", i)
+		}
+	}
+	select {
+	case <-limited:
+		t.Fatal("data received after the limit")
+	case <-time.After(300 * time.Millisecond):
+	}
+
+	once, err := esc.ConnectToSimVarRequest(simconnect.DataRequest{Period: simconnect.SIMCONNECT_PERIOD_ONCE}, simconnect.SimVarPlaneAltitude())
+	require.NoError(t, err)
+	select {
+	case <-once:
+	case <-time.After(2 * time.Second):
+		t.Fatal("data not received")
+	}
+}
+
+func TestSimConnectToSimVarEvery(t *testing.T) {
+	srv, esc := connectSim(t)
+	srv.Aircraft.Set("PLANE ALTITUDE", 1500)
+
+	c, err := esc.ConnectToSimVarEvery(200*time.Millisecond, simconnect.SimVarPlaneAltitude())
+	require.NoError(t, err)
+	<-c
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		select {
+		case <-c:
+		case <-time.After(2 * time.Second):
+			t.Fatal("no SimVar received")
+		}
+	}
+	assert.GreaterOrEqual(t, time.Since(start), 550*time.Millisecond, "sim frames are throttled to the interval")
+}
+
+func TestSimConnectToSimVarChanged(t *testing.T) {
+	type report struct {
+		Altitude float64 `sim:"PLANE ALTITUDE" simUnit:"feet" epsilon:"10"`
+		Heading  float64 `sim:"PLANE HEADING DEGREES TRUE" simUnit:"degrees"`
+	}
+	srv, esc := connectSim(t)
+	srv.Aircraft.Set("PLANE ALTITUDE", 1500)
+	srv.Aircraft.Set("PLANE HEADING DEGREES TRUE", 90)
+
+	simVars, err := simconnect.SimVarGenerator(report{})
+	require.NoError(t, err)
+	assert.Equal(t, float32(10), simVars[0].Epsilon)
+	c, err := esc.ConnectToSimVarChanged(50*time.Millisecond, simVars...)
+	require.NoError(t, err)
+
+	next := func() []simconnect.SimVar {
+		t.Helper()
+		select {
+		case vars := <-c:
+			return vars
+		case <-time.After(2 * time.Second):
+			t.Fatal("no SimVar received")
+		}
+		return nil
+	}
+	assert.Len(t, next(), 2, "the first data has every SimVar")
+
+	srv.Aircraft.Set("PLANE ALTITUDE", 1505)
+	select {
+	case vars := <-c:
+		t.Fatalf("change under epsilon received: %v", vars)
+	case <-time.After(300 * time.Millisecond):
+	}
+
+	srv.Aircraft.Set("PLANE ALTITUDE", 1520)
+	vars := next()
+	require.Len(t, vars, 1)
+	assert.Equal(t, "PLANE ALTITUDE", vars[0].Name)
+	f, err := vars[0].GetFloat64()
+	require.NoError(t, err)
+	assert.Equal(t, 1520.0, f)
+
+	srv.Aircraft.Set("PLANE HEADING DEGREES TRUE", 91)
+	vars = next()
+	require.Len(t, vars, 1)
+	assert.Equal(t, "PLANE HEADING DEGREES TRUE", vars[0].Name)
+}
+
+func TestSimConnectToTraffic(t *testing.T) {
+	srv, esc := connectSim(t)
+	srv.Aircraft.Set("PLANE LATITUDE", 48.0)
+	srv.Aircraft.Set("PLANE LONGITUDE", 2.0)
+	near := simtest.NewAircraft()
+	near.Set("PLANE LATITUDE", 48.01)
+	near.Set("PLANE LONGITUDE", 2.0)
+	far := simtest.NewAircraft()
+	far.Set("PLANE LATITUDE", 50.0)
+	far.Set("PLANE LONGITUDE", 2.0)
+	nearID := srv.AddObject(simconnect.SIMCONNECT_SIMOBJECT_TYPE_AIRCRAFT, near)
+	srv.AddObject(simconnect.SIMCONNECT_SIMOBJECT_TYPE_AIRCRAFT, far)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	boatsCtx, stopBoats := context.WithCancel(ctx)
+	boats, err := esc.ConnectToTraffic(boatsCtx, simconnect.SIMCONNECT_SIMOBJECT_TYPE_BOAT, 10000, 50*time.Millisecond, simconnect.SimVarPlaneLatitude())
+	require.NoError(t, err)
+	aircraft, err := esc.ConnectToTraffic(ctx, simconnect.SIMCONNECT_SIMOBJECT_TYPE_AIRCRAFT, 10000, 50*time.Millisecond, simconnect.SimVarPlaneLatitude())
+	require.NoError(t, err)
+
+	next := func(c <-chan simconnect.TrafficDiff) simconnect.TrafficDiff {
+		t.Helper()
+		select {
+		case diff := <-c:
+			return diff
+		case <-time.After(2 * time.Second):
+			t.Fatal("no traffic received")
+		}
+		return simconnect.TrafficDiff{}
+	}
+	assert.Empty(t, next(boats).Snapshot)
+	_, defs := srv.DataRequests()
+	stopBoats()
+	assert.Eventually(t, func() bool {
+		_, open := <-boats
+		_, left := srv.DataRequests()
+		return !open && left == defs-1
+	}, 2*time.Second, 10*time.Millisecond)
+
+	diff := next(aircraft)
+	require.Len(t, diff.Added, 2)
+	assert.Equal(t, nearID, diff.Added[1].ObjectID)
+	assert.Len(t, diff.Snapshot, 2)
+
+	near.Set("PLANE LATITUDE", 48.02)
+	diff = next(aircraft)
+	assert.Empty(t, diff.Added)
+	require.Len(t, diff.Updated, 1)
+	assert.Equal(t, nearID, diff.Updated[0].ObjectID)
+	lat, err := diff.Updated[0].SimVars[0].GetFloat64()
+	require.NoError(t, err)
+	assert.Equal(t, 48.02, lat)
+
+	srv.RemoveObject(nearID)
+	diff = next(aircraft)
+	assert.Equal(t, []uint32{nearID}, diff.Removed)
+	assert.Len(t, diff.Snapshot, 1)
+}
+
+func TestSimAIObject(t *testing.T) {
+	srv, esc := connectSim(t)
+	srv.Titles = []string{"Airbus A320 Neo Asobo", "Windsock"}
+	srv.Aircraft.Set("PLANE LATITUDE", 48.0)
+	srv.Aircraft.Set("PLANE LONGITUDE", 2.0)
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	objectID, err := esc.AICreateSimulatedObject(ctx, "Windsock", simconnect.SIMCONNECT_DATA_INITPOSITION{
+		Latitude:  48.001,
+		Longitude: 2.0,
+		OnGround:  1,
+	})
+	require.NoError(t, err)
+	object, found := srv.Object(objectID)
+	require.True(t, found)
+	assert.Equal(t, 48.001, object.Float64("PLANE LATITUDE"))
+
+	aircraftID, err := esc.AICreateParkedATCAircraft(ctx, "Airbus A320 Neo Asobo", "F-GKXA", "LFPG")
+	require.NoError(t, err)
+	assert.NotEqual(t, objectID, aircraftID)
+	require.NoError(t, esc.AISetAircraftFlightPlan(aircraftID, "LFPG-EGLL"))
+
+	simVar := simconnect.SimVarPlaneAltitude()
+	simVar.SetFloat64(120)
+	require.NoError(t, esc.SetSimObjectOn(objectID, simVar))
+	assert.Eventually(t, func() bool {
+		return object.Float64("PLANE ALTITUDE") == 120
+	}, 2*time.Second, 10*time.Millisecond)
+
+	require.NoError(t, esc.AIRemoveObject(objectID))
+	assert.Eventually(t, func() bool {
+		_, found := srv.Object(objectID)
+		return !found
+	}, 2*time.Second, 10*time.Millisecond)
+}
+
+func TestSimAIObjectFailed(t *testing.T) {
+	srv, esc := connectSim(t)
+	srv.Titles = []string{"Windsock"}
+
+	_, err := esc.AICreateNonATCAircraft(context.Background(), "Unknown", "F-GKXA", simconnect.SIMCONNECT_DATA_INITPOSITION{})
+	assert.ErrorContains(t, err, "AICreateNonATCAircraft")
+}
+
+func TestSimAIObjectUnknown(t *testing.T) {
+	srv, esc := connectSim(t)
+	srv.Aircraft.Set("PLANE ALTITUDE", 0.0)
+
+	assert.ErrorContains(t, esc.AIRemoveObject(12345), "SIMCONNECT_EXCEPTION_UNRECOGNIZED_ID")
+	assert.ErrorContains(t, esc.AIReleaseControl(12345), "AIReleaseControl")
+	assert.ErrorContains(t, esc.AISetAircraftFlightPlan(12345, "LFPG-EGLL"), "AISetAircraftFlightPlan")
+	simVar := simconnect.SimVarPlaneAltitude()
+	simVar.SetFloat64(120)
+	require.NoError(t, esc.SetSimObjectOn(12345, simVar))
+
+	simVar.SetFloat64(150)
+	require.NoError(t, esc.SetSimObjectOn(simconnect.SIMCONNECT_OBJECT_ID_USER, simVar))
+	assert.Eventually(t, func() bool {
+		return srv.Aircraft.Float64("PLANE ALTITUDE") == 150
+	}, 2*time.Second, 10*time.Millisecond)
+	assert.True(t, esc.IsAlive())
+}
+
+func TestSimSetSimObjectOnConcurrent(t *testing.T) {
+	srv, esc := connectSim(t)
+	srv.Aircraft.Set("PLANE ALTITUDE", 0.0)
+	srv.Aircraft.Set("PLANE LATITUDE", 0.0)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			simVar := simconnect.SimVarPlaneAltitude()
+			simVar.SetFloat64(120)
+			assert.NoError(t, esc.SetSimObjectOn(simconnect.SIMCONNECT_OBJECT_ID_USER, simVar))
+		}()
+		go func() {
+			defer wg.Done()
+			simVar := simconnect.SimVarPlaneLatitude()
+			simVar.SetFloat64(48.5)
+			assert.NoError(t, esc.SetSimObjectOn(simconnect.SIMCONNECT_OBJECT_ID_USER, simVar))
+		}()
+	}
+	wg.Wait()
+	assert.Eventually(t, func() bool {
+		return srv.Aircraft.Float64("PLANE ALTITUDE") == 120 && srv.Aircraft.Float64("PLANE LATITUDE") == 48.5
+	}, 2*time.Second, 10*time.Millisecond)
+	assert.True(t, esc.IsAlive())
+}
+
+func TestSimWeatherObservation(t *testing.T) {
+	srv, esc := connectSim(t)
+	srv.AddStation("LFPG", 49.0097, 2.5479, "LFPG 181030Z 27015KT 9999 BKN040 12/08 Q1008")
+	srv.AddStation("EGLL", 51.4700, -0.4543, "EGLL 181050Z 23010KT 8000 OVC012 10/09 Q1011")
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	metar, err := esc.WeatherRequestObservationAtStation(ctx, "EGLL")
+	require.NoError(t, err)
+	assert.Equal(t, "EGLL", metar.Station)
+	assert.Equal(t, 8000.0, metar.Visibility)
+	assert.Equal(t, 1011.0, metar.QNH)
+
+	metar, err = esc.WeatherRequestObservationAtNearestStation(ctx, 48.86, 2.35)
+	require.NoError(t, err)
+	assert.Equal(t, "LFPG", metar.Station)
+	assert.Equal(t, 270, metar.WindDirection)
+
+	metar, err = esc.WeatherRequestInterpolatedObservation(ctx, 51.5, -0.1, 3000)
+	require.NoError(t, err)
+	assert.Equal(t, "GLOB", metar.Station)
+	assert.Equal(t, []simconnect.MetarCloud{{Cover: "OVC", Base: 1200}}, metar.Clouds)
+}
+
+func TestSimWeatherObservationUnknown(t *testing.T) {
+	srv, esc := connectSim(t)
+	srv.AddStation("EGLL", 51.4700, -0.4543, "EGLL 181050Z 23010KT 8000 OVC012 10/09 Q1011")
+	srv.Aircraft.Set("PLANE ALTITUDE", 1500)
+	cSimVar, err := esc.ConnectToSimVar(simconnect.SimVarPlaneAltitude())
+	require.NoError(t, err)
+
+	_, err = esc.WeatherRequestObservationAtStation(context.Background(), "KSEA")
+	assert.ErrorContains(t, err, "SIMCONNECT_EXCEPTION_WEATHER_UNABLE_TO_GET_OBSERVATION")
+
+	// the exception fails the request only
+	assert.True(t, esc.IsAlive())
+	for i := 0; i < 2; i++ {
+		select {
+		case <-cSimVar:
+		case <-time.After(2 * time.Second):
+			t.Fatal("SimVars stopped after the exception")
+		}
+	}
+	_, err = esc.WeatherRequestObservationAtStation(context.Background(), "EGLL")
+	assert.NoError(t, err)
+}
+
+func TestSimFacilities(t *testing.T) {
+	srv, esc := connectSim(t)
+	srv.Aircraft.Set("PLANE LATITUDE", 48.0)
+	srv.Aircraft.Set("PLANE LONGITUDE", 2.0)
+	for i := 0; i < 40; i++ {
+		srv.AddFacility(simconnect.FacilityAirport{Ident: fmt.Sprintf("LF%02d", i), Region: "LF", Latitude: 48.0 + float64(i)/100, Longitude: 2.0})
+	}
+	srv.AddFacility(simconnect.FacilityAirport{Ident: "EGLL", Region: "EG", Latitude: 51.47, Longitude: -0.45})
+	vor := simconnect.FacilityVOR{
+		FacilityNDB: simconnect.FacilityNDB{
+			FacilityWaypoint: simconnect.FacilityWaypoint{
+				FacilityAirport: simconnect.FacilityAirport{Ident: "PGS", Region: "LF", Latitude: 48.05, Longitude: 2.1, Altitude: 90},
+				MagVar:          -1.5,
+			},
+			Frequency: 117250000,
+		},
+		Flags:           simconnect.SIMCONNECT_RECV_ID_VOR_LIST_HAS_NAV_SIGNAL | simconnect.SIMCONNECT_RECV_ID_VOR_LIST_HAS_DME,
+		Localizer:       270,
+		GlideLat:        48.06,
+		GlideLon:        2.11,
+		GlideAlt:        95,
+		GlideSlopeAngle: 3,
+	}
+	srv.AddFacility(vor)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	airports, err := esc.RequestAirports(ctx)
+	require.NoError(t, err)
+	assert.Len(t, airports, 40)
+	assert.Equal(t, "LF39", airports[39].Ident)
+	assert.Equal(t, "LF", airports[39].Region)
+	vors, err := esc.RequestVORs(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []simconnect.FacilityVOR{vor}, vors)
+	ndbs, err := esc.RequestNDBs(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, ndbs)
+
+	facilities, err := esc.SubscribeToFacilities(ctx, simconnect.SIMCONNECT_FACILITY_LIST_TYPE_AIRPORT, 50*time.Millisecond)
+	require.NoError(t, err)
+	next := func() simconnect.FacilityDiff {
+		t.Helper()
+		select {
+		case diff := <-facilities:
+			return diff
+		case <-ctx.Done():
+			t.Fatal("no facilities received")
+		}
+		return simconnect.FacilityDiff{}
+	}
+	diff := next()
+	assert.Len(t, diff.Added, 40)
+	assert.Len(t, diff.Snapshot, 40)
+
+	srv.AddFacility(simconnect.FacilityAirport{Ident: "LFPG", Region: "LF", Latitude: 48.5, Longitude: 2.3})
+	diff = next()
+	assert.Equal(t, []simconnect.Facility{simconnect.FacilityAirport{Ident: "LFPG", Region: "LF", Latitude: 48.5, Longitude: 2.3}}, diff.Added)
+	assert.Len(t, diff.Snapshot, 41)
+
+	srv.Aircraft.Set("PLANE LATITUDE", 51.4)
+	srv.Aircraft.Set("PLANE LONGITUDE", -0.4)
+	diff = next()
+	for len(diff.Removed) == 0 {
+		diff = next()
+	}
+	assert.Len(t, diff.Removed, 41)
+	assert.Contains(t, diff.Snapshot, "EG/EGLL")
+}
+
+func TestSimFacilitiesUnknownList(t *testing.T) {
+	srv, esc := connectSim(t)
+	srv.Aircraft.Set("PLANE LATITUDE", 48.0)
+	srv.Aircraft.Set("PLANE LONGITUDE", 2.0)
+	srv.AddFacility(simconnect.FacilityAirport{Ident: "LFPG", Region: "LF", Latitude: 48.5, Longitude: 2.3})
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	_, err := esc.RequestFacilitiesList(ctx, simconnect.SIMCONNECT_FACILITY_LIST_TYPE_COUNT)
+	assert.ErrorContains(t, err, "SIMCONNECT_EXCEPTION_OUT_OF_BOUNDS")
+	_, err = esc.SubscribeToFacilities(ctx, simconnect.SIMCONNECT_FACILITY_LIST_TYPE_COUNT, 0)
+	assert.ErrorContains(t, err, "SIMCONNECT_EXCEPTION_OUT_OF_BOUNDS")
+	assert.True(t, esc.IsAlive())
+	airports, err := esc.RequestAirports(ctx)
+	require.NoError(t, err)
+	assert.Len(t, airports, 1)
+}
+
+func TestSimAirportData(t *testing.T) {
+	srv, esc := connectSim(t)
+	airport := simconnect.FacilityAirportData{
+		ICAO: "LFPO", Region: "LF", Name: "Paris Orly", Latitude: 48.7233, Longitude: 2.3794, Altitude: 89,
+		Runways: []simconnect.FacilityRunway{{
+			Latitude: 48.7300, Longitude: 2.3300, Altitude: 89, Heading: 75, Length: 3320, Width: 45,
+			PrimaryNumber: 8, SecondaryNumber: 26, PrimaryThreshold: 300,
+		}, {
+			Latitude: 48.7200, Longitude: 2.3700, Altitude: 89, Heading: 62, Length: 2400, Width: 60,
+			PrimaryNumber: 6, SecondaryNumber: 24,
+		}},
+		Parkings: []simconnect.FacilityParking{
+			{Type: 8, Name: 12, Number: 1, Heading: 180, Radius: 30, BiasX: 100, BiasZ: -50},
+			{Type: 8, Name: 12, Number: 2, Heading: 180, Radius: 30, BiasX: 160, BiasZ: -50},
+		},
+		Frequencies: []simconnect.FacilityFrequency{{Type: 9, Frequency: 118700000, Name: "ORLY TOWER"}},
+	}
+	srv.AddAirportData(airport)
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	data, err := esc.RequestAirportData(ctx, "LFPO", simconnect.AirportDataRequest{Runways: true, Parkings: true, Frequencies: true})
+	require.NoError(t, err)
+	assert.Equal(t, airport, *data)
+
+	data, err = esc.RequestAirportData(ctx, "LFPO", simconnect.AirportDataRequest{Frequencies: true})
+	require.NoError(t, err)
+	assert.Equal(t, "Paris Orly", data.Name)
+	assert.Empty(t, data.Runways)
+	assert.Equal(t, airport.Frequencies, data.Frequencies)
+
+	_, err = esc.RequestAirportData(ctx, "LFXX", simconnect.AirportDataRequest{Runways: true})
+	assert.ErrorContains(t, err, "LFXX not found")
+}
+
+func TestSimSubscribe(t *testing.T) {
+	type position struct {
+		Altitude float64 `sim:"PLANE ALTITUDE" simUnit:"feet"`
+	}
+	type heading struct {
+		Heading float64 `sim:"PLANE HEADING DEGREES TRUE" simUnit:"degrees"`
+	}
+	srv, esc := connectSim(t)
+	srv.Aircraft.Set("PLANE ALTITUDE", 1500)
+	srv.Aircraft.Set("PLANE HEADING DEGREES TRUE", 180)
+	esc.SetDelay(50 * time.Millisecond)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	positions, err := simconnect.Subscribe[position](ctx, esc)
+	require.NoError(t, err)
+	headings, err := simconnect.Subscribe[heading](ctx, esc)
+	require.NoError(t, err)
+
+	for i := 0; i < 2; i++ {
+		select {
+		case p := <-positions:
+			assert.Equal(t, 1500.0, p.Altitude)
+		case h := <-headings:
+			assert.Equal(t, 180.0, h.Heading)
+		case <-time.After(2 * time.Second):
+			t.Fatal("no report received")
+		}
+	}
+
+	_, err = simconnect.Subscribe[struct{ Name string }](ctx, esc)
+	assert.Error(t, err)
+
+	// the requests are stopped and the definitions cleared, the chans closed
+	cancel()
+	for range positions {
+	}
+	for range headings {
+	}
+	assert.Eventually(t, func() bool {
+		requests, defs := srv.DataRequests()
+		return requests == 0 && defs == 0
+	}, 2*time.Second, 10*time.Millisecond)
+	assert.True(t, esc.IsAlive())
+}
+
+func TestSimConnectSysEventCrashed(t *testing.T) {
+	srv, esc := connectSim(t)
+
+	crashed := esc.ConnectSysEventCrashed()
+	require.Eventually(t, func() bool {
+		return srv.Subscribers(simconnect.SystemEventCrashed) == 1
+	}, 2*time.Second, 10*time.Millisecond)
+	srv.Crash()
+	select {
+	case <-crashed:
+	case <-time.After(2 * time.Second):
+		t.Fatal("crash not received")
+	}
+}
+
+func TestSimSetSimObject(t *testing.T) {
+	srv, esc := connectSim(t)
+
+	simVar := simconnect.SimVarPlaneAltitude()
+	simVar.SetFloat64(3000)
+	esc.SetSimObject(simVar)
+	assert.Eventually(t, func() bool {
+		return srv.Aircraft.Float64("PLANE ALTITUDE") == 3000
+	}, 2*time.Second, 10*time.Millisecond)
+}
+
+func TestSimEvent(t *testing.T) {
+	srv, esc := connectSim(t)
+	srv.Aircraft.OnEvent("PARKING_BRAKES", func(a *simtest.Aircraft, data uint32) {
+		a.Set("BRAKE PARKING POSITION", 1)
+	})
+
+	select {
+	case <-esc.NewSimEvent(simconnect.KeyParkingBrakes).Run():
+	case <-time.After(2 * time.Second):
+		t.Fatal("event not confirmed")
+	}
+	assert.Equal(t, 1.0, srv.Aircraft.Float64("BRAKE PARKING POSITION"))
+	assert.Equal(t, []simtest.Event{{Name: "PARKING_BRAKES", Data: 0}}, srv.Events())
+}
+
+func TestSimClientData(t *testing.T) {
+	type gauge struct {
+		Mode    int32
+		Armed   bool
+		_       [3]byte
+		Heading float64
+	}
+	srv, esc := connectSim(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	require.NoError(t, esc.CreateClientData("simgo.gauge", 16, false))
+	assert.Error(t, simconnect.SetClientData(esc, "simgo.gauge", struct{ Name string }{"A320"}))
+	require.NoError(t, simconnect.SetClientData(esc, "simgo.gauge", gauge{Mode: 2, Armed: true, Heading: 270}))
+	value, err := simconnect.ReadClientData[gauge](ctx, esc, "simgo.gauge")
+	require.NoError(t, err)
+	assert.Equal(t, gauge{Mode: 2, Armed: true, Heading: 270}, value)
+
+	c, err := simconnect.SubscribeClientData[gauge](ctx, esc, "simgo.gauge")
+	require.NoError(t, err)
+	assert.Equal(t, gauge{Mode: 2, Armed: true, Heading: 270}, <-c)
+	data := srv.ClientData("simgo.gauge")
+	data[0] = 3
+	srv.SetClientData("simgo.gauge", data)
+	assert.Equal(t, gauge{Mode: 3, Armed: true, Heading: 270}, <-c)
+	srv.SetClientData("simgo.gauge", data)
+	require.NoError(t, simconnect.SetClientData(esc, "simgo.gauge", gauge{Mode: 1, Heading: 90}))
+	assert.Equal(t, gauge{Mode: 1, Heading: 90}, <-c)
+
+	_, err = simconnect.ReadClientData[gauge](ctx, esc, "simgo.missing")
+	assert.ErrorContains(t, err, "RequestClientData")
+	assert.ErrorContains(t, esc.CreateClientData("simgo.gauge", 16, false), "SIMCONNECT_EXCEPTION_ALREADY_CREATED")
+	require.NoError(t, simconnect.SetClientData(esc, "simgo.missing", gauge{Mode: 1}))
+	_, err = simconnect.SubscribeClientData[gauge](ctx, esc, "simgo.missing")
+	require.NoError(t, err)
+	require.NoError(t, simconnect.SetClientData(esc, "simgo.gauge", gauge{Mode: 4, Heading: 90}))
+	assert.Equal(t, gauge{Mode: 4, Heading: 90}, <-c)
+	assert.True(t, esc.IsAlive())
+}
+
+func TestSimLVars(t *testing.T) {
+	srv, esc := connectSim(t)
+	srv.StartMobiFlight()
+	srv.SetLVar("A32NX_SEAT_BELT", 1)
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	lvars, err := simconnect.NewLVarClient(ctx, esc, "simgo")
+	require.NoError(t, err)
+	updates := lvars.Updates(ctx)
+	require.NoError(t, lvars.Register("A32NX_SEAT_BELT", "(L:A32NX_NO_SMOKING)"))
+	received := map[string]float64{}
+	for len(received) < 2 {
+		select {
+		case lvar := <-updates:
+			received[lvar.Name] = lvar.Value
+		case <-ctx.Done():
+			t.Fatal("L:vars not received")
+		}
+	}
+	assert.Equal(t, map[string]float64{"A32NX_SEAT_BELT": 1, "A32NX_NO_SMOKING": 0}, received)
+
+	srv.SetLVar("A32NX_NO_SMOKING", 2)
+	assert.Equal(t, simconnect.LVar{Name: "A32NX_NO_SMOKING", Value: 2}, <-updates)
+	value, found := lvars.Get("L:A32NX_NO_SMOKING")
+	assert.True(t, found)
+	assert.Equal(t, 2.0, value)
+
+	require.NoError(t, lvars.Set("A32NX_SEAT_BELT", 0))
+	assert.Equal(t, simconnect.LVar{Name: "A32NX_SEAT_BELT", Value: 0}, <-updates)
+	require.NoError(t, lvars.Set("A32NX_SEAT_BELT", 0.5))
+	assert.Equal(t, simconnect.LVar{Name: "A32NX_SEAT_BELT", Value: 0.5}, <-updates)
+	value, _ = srv.LVar("A32NX_SEAT_BELT")
+	assert.Equal(t, 0.5, value)
+}
+
+func TestSimLVarsWithoutModule(t *testing.T) {
+	_, esc := connectSim(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+
+	_, err := simconnect.NewLVarClient(ctx, esc, "simgo")
+	assert.ErrorContains(t, err, "MobiFlight")
+}
+
+func TestSimCalculatorCode(t *testing.T) {
+	srv, esc := connectSim(t)
+	srv.StartMobiFlight()
+	srv.SetLVar("A32NX_SEAT_BELT", 1)
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	lvars, err := simconnect.NewLVarClient(ctx, esc, "simgo")
+	require.NoError(t, err)
+	require.NoError(t, lvars.ExecuteCalculatorCode("(L:A32NX_SEAT_BELT, bool) ! (>L:A32NX_SEAT_BELT) 3 (>K:FLAPS_SET)"))
+	assert.Eventually(t, func() bool {
+		value, _ := srv.LVar("A32NX_SEAT_BELT")
+		return value == 0 && len(srv.Events()) == 1
+	}, time.Second, 10*time.Millisecond)
+	assert.Equal(t, []simtest.Event{{Name: "FLAPS_SET", Data: 3}}, srv.Events())
+
+	require.NoError(t, lvars.FireHEvent("(>H:A320_Neo_CDU_1_BTN_A)"))
+	require.NoError(t, lvars.FireHEvent("A32NX_FCU_AP_1_PUSH"))
+	assert.Eventually(t, func() bool {
+		return len(srv.HEvents()) == 2
+	}, time.Second, 10*time.Millisecond)
+	assert.Equal(t, []string{"A320_Neo_CDU_1_BTN_A", "A32NX_FCU_AP_1_PUSH"}, srv.HEvents())
+}
+
+func TestSimInputEvents(t *testing.T) {
+	srv, esc := connectSim(t)
+	for i := 0; i < 20; i++ {
+		srv.AddInputEvent(fmt.Sprintf("LIGHTING_CABIN_%d", i), 0, "")
+	}
+	heading := srv.AddInputEvent("AUTOPILOT_KNOB_HEADING", 90, "FLOAT64")
+	srv.AddInputEvent("XPNDR_CODE", "7000", "STRING")
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	catalog, err := esc.EnumerateInputEvents(ctx)
+	require.NoError(t, err)
+	assert.Len(t, catalog, 22)
+	event, found := catalog.Get("autopilot_knob_heading")
+	require.True(t, found)
+	assert.Equal(t, simconnect.InputEvent{Name: "AUTOPILOT_KNOB_HEADING", Hash: heading, Type: simconnect.SIMCONNECT_INPUT_EVENT_TYPE_DOUBLE}, event)
+	xpndr, found := catalog.Get("XPNDR_CODE")
+	require.True(t, found)
+
+	value, err := esc.GetInputEvent(ctx, event.Hash)
+	require.NoError(t, err)
+	assert.Equal(t, simconnect.InputEventValue{Hash: heading, Double: 90}, value)
+	value, err = esc.GetInputEvent(ctx, xpndr.Hash)
+	require.NoError(t, err)
+	assert.Equal(t, "7000", value.String)
+	params, err := esc.EnumerateInputEventParams(ctx, event.Hash)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"FLOAT64"}, params)
+
+	changes, err := esc.SubscribeInputEvent(ctx, event.Hash)
+	require.NoError(t, err)
+	require.NoError(t, esc.SetInputEvent(event.Hash, 270))
+	assert.Equal(t, 270.0, (<-changes).Double)
+	srv.SetInputEvent("AUTOPILOT_KNOB_HEADING", 180)
+	assert.Equal(t, 180.0, (<-changes).Double)
+	require.NoError(t, esc.SetInputEventString(xpndr.Hash, "7700"))
+	assert.Eventually(t, func() bool {
+		code, _ := srv.InputEvent("XPNDR_CODE")
+		return code == "7700"
+	}, time.Second, 10*time.Millisecond)
+}
+
+func TestSimInputEventParamsConcurrent(t *testing.T) {
+	srv, esc := connectSim(t)
+	heading := srv.AddInputEvent("AUTOPILOT_KNOB_HEADING", 90, "FLOAT64")
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	cancelled, cancelNow := context.WithCancel(ctx)
+	cancelNow()
+	_, err := esc.EnumerateInputEventParams(cancelled, heading)
+	assert.Error(t, err)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			params, err := esc.EnumerateInputEventParams(ctx, heading)
+			assert.NoError(t, err)
+			assert.Equal(t, []string{"FLOAT64"}, params)
+		}()
+	}
+	wg.Wait()
+}
+
+func TestSimInputEventsUnknown(t *testing.T) {
+	srv, esc := connectSim(t)
+	heading := srv.AddInputEvent("AUTOPILOT_KNOB_HEADING", 90, "FLOAT64")
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	_, err := esc.GetInputEvent(ctx, 12345)
+	assert.ErrorContains(t, err, "SIMCONNECT_EXCEPTION_UNRECOGNIZED_ID")
+	_, err = esc.EnumerateInputEventParams(ctx, 12345)
+	assert.ErrorContains(t, err, "SIMCONNECT_EXCEPTION_UNRECOGNIZED_ID")
+	_, err = esc.SubscribeInputEvent(ctx, 12345)
+	assert.ErrorContains(t, err, "SIMCONNECT_EXCEPTION_UNRECOGNIZED_ID")
+	require.NoError(t, esc.SetInputEvent(12345, 1))
+
+	value, err := esc.GetInputEvent(ctx, heading)
+	require.NoError(t, err)
+	assert.Equal(t, 90.0, value.Double)
+	assert.True(t, esc.IsAlive())
+}
+
+func TestSimMenu(t *testing.T) {
+	srv, esc := connectSim(t)
+	clicked := make(chan string, 4)
+
+	flight, err := esc.AddMenuItem("PassCargo", nil)
+	require.NoError(t, err)
+	_, err = flight.AddSubItem("Start PassCargo flight", func() { clicked <- "start" })
+	require.NoError(t, err)
+	pirep, err := flight.AddSubItem("Send PIREP", func() { clicked <- "pirep" })
+	require.NoError(t, err)
+	_, err = esc.AddMenuItem("Reset tracking", func() { clicked <- "reset" })
+	require.NoError(t, err)
+	_, err = pirep.AddSubItem("Nested", nil)
+	assert.Error(t, err)
+	assert.Eventually(t, func() bool {
+		return assert.ObjectsAreEqual(map[string][]string{
+			"PassCargo":      {"Start PassCargo flight", "Send PIREP"},
+			"Reset tracking": {},
+		}, srv.Menu())
+	}, time.Second, 10*time.Millisecond)
+
+	require.True(t, srv.ClickMenuItem("PassCargo", "Send PIREP"))
+	assert.Equal(t, "pirep", <-clicked)
+	require.True(t, srv.ClickMenuItem("Reset tracking"))
+	assert.Equal(t, "reset", <-clicked)
+	assert.False(t, srv.ClickMenuItem("PassCargo", "Unknown"))
+
+	require.NoError(t, pirep.Delete())
+	require.NoError(t, pirep.Delete())
+	assert.Eventually(t, func() bool {
+		return len(srv.Menu()["PassCargo"]) == 1
+	}, time.Second, 10*time.Millisecond)
+	assert.False(t, srv.ClickMenuItem("PassCargo", "Send PIREP"))
+
+	<-esc.Close()
+	assert.Eventually(t, func() bool {
+		return len(srv.Menu()) == 0
+	}, time.Second, 10*time.Millisecond)
+}
@@ -0,0 +1,240 @@
+package simconnect
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeTransport is an in-memory Transport without the optional interfaces, messages pushed are returned by
+// GetNextDispatch
+type fakeTransport struct {
+	mu       sync.Mutex
+	packetID uint32
+	calls    []string
+	queue    chan []byte
+}
+
+func newFakeTransport() *fakeTransport {
+	return &fakeTransport{queue: make(chan []byte, 16)}
+}
+
+func (f *fakeTransport) call(name string) (uint32, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.packetID++
+	f.calls = append(f.calls, name)
+	return f.packetID, nil
+}
+
+func (f *fakeTransport) called(name string) bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, c := range f.calls {
+		if c == name {
+			return true
+		}
+	}
+	return false
+}
+
+func (f *fakeTransport) push(id uint32, fields ...interface{}) {
+	body := new(bytes.Buffer)
+	for _, field := range fields {
+		binary.Write(body, binary.LittleEndian, field)
+	}
+	buf := make([]byte, 12, 12+body.Len())
+	binary.LittleEndian.PutUint32(buf[0:], uint32(12+body.Len()))
+	binary.LittleEndian.PutUint32(buf[8:], id)
+	f.queue <- append(buf, body.Bytes()...)
+}
+
+func (f *fakeTransport) Open(appTitle string) error {
+	_, err := f.call("Open")
+	return err
+}
+func (f *fakeTransport) Close() error {
+	_, err := f.call("Close")
+	return err
+}
+func (f *fakeTransport) GetNextDispatch() ([]byte, error) {
+	select {
+	case buf := <-f.queue:
+		return buf, nil
+	default:
+		return nil, errors.New("empty")
+	}
+}
+func (f *fakeTransport) MapClientEventToSimEvent(EventID uint32, EventName string) (uint32, error) {
+	return f.call("MapClientEventToSimEvent")
+}
+func (f *fakeTransport) TransmitClientEvent(ObjectID uint32, EventID uint32, dwData uint32, GroupID uint32, Flags uint32) (uint32, error) {
+	return f.call("TransmitClientEvent")
+}
+func (f *fakeTransport) AddClientEventToNotificationGroup(GroupID uint32, EventID uint32, bMaskable bool) (uint32, error) {
+	return f.call("AddClientEventToNotificationGroup")
+}
+func (f *fakeTransport) SetNotificationGroupPriority(GroupID uint32, uPriority uint32) (uint32, error) {
+	return f.call("SetNotificationGroupPriority")
+}
+func (f *fakeTransport) AddToDataDefinition(DefineID uint32, DatumName string, UnitsName string, DatumType uint32, fEpsilon float32, DatumID uint32) (uint32, error) {
+	return f.call("AddToDataDefinition")
+}
+func (f *fakeTransport) ClearDataDefinition(DefineID uint32) (uint32, error) {
+	return f.call("ClearDataDefinition")
+}
+func (f *fakeTransport) RequestDataOnSimObject(RequestID uint32, DefineID uint32, ObjectID uint32, Period uint32, Flags uint32, origin uint32, interval uint32, limit uint32) (uint32, error) {
+	return f.call("RequestDataOnSimObject")
+}
+func (f *fakeTransport) RequestDataOnSimObjectType(RequestID uint32, DefineID uint32, dwRadiusMeters uint32, t uint32) (uint32, error) {
+	return f.call("RequestDataOnSimObjectType")
+}
+func (f *fakeTransport) SetDataOnSimObject(DefineID uint32, ObjectID uint32, Flags uint32, ArrayCount uint32, cbUnitSize uint32, pDataSet []byte) (uint32, error) {
+	return f.call("SetDataOnSimObject")
+}
+func (f *fakeTransport) MapInputEventToClientEvent(GroupID uint32, szInputDefinition string, DownEventID uint32, DownValue uint32, UpEventID uint32, UpValue uint32, bMaskable bool) (uint32, error) {
+	return f.call("MapInputEventToClientEvent")
+}
+func (f *fakeTransport) SetInputGroupState(GroupID uint32, dwState uint32) (uint32, error) {
+	return f.call("SetInputGroupState")
+}
+func (f *fakeTransport) SubscribeToSystemEvent(EventID uint32, SystemEventName string) (uint32, error) {
+	return f.call("SubscribeToSystemEvent")
+}
+func (f *fakeTransport) Text(t uint32, fTimeSeconds float32, EventID uint32, pDataSet []byte) (uint32, error) {
+	return f.call("Text")
+}
+
+// fakeFacilities is a fakeTransport with FacilitiesTransport
+type fakeFacilities struct {
+	*fakeTransport
+}
+
+func (f fakeFacilities) SubscribeToFacilities(t uint32, RequestID uint32) (uint32, error) {
+	return f.call("SubscribeToFacilities")
+}
+func (f fakeFacilities) UnsubscribeToFacilities(t uint32) (uint32, error) {
+	return f.call("UnsubscribeToFacilities")
+}
+func (f fakeFacilities) RequestFacilitiesList(t uint32, RequestID uint32) (uint32, error) {
+	return f.call("RequestFacilitiesList")
+}
+func (f fakeFacilities) AddToFacilityDefinition(DefineID uint32, FieldName string) (uint32, error) {
+	return f.call("AddToFacilityDefinition")
+}
+func (f fakeFacilities) RequestFacilityData(DefineID uint32, RequestID uint32, ICAO string, Region string) (uint32, error) {
+	return f.call("RequestFacilityData")
+}
+
+// sent return the packet ID of the last call name
+func (f *fakeTransport) sent(name string) (uint32, bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for i := len(f.calls) - 1; i >= 0; i-- {
+		if f.calls[i] == name {
+			return uint32(i + 1), true
+		}
+	}
+	return 0, false
+}
+
+func connectFake(t *testing.T) (*EasySimConnect, *fakeTransport) {
+	ft := newFakeTransport()
+	return connectFakeWith(t, ft, ft), ft
+}
+
+// connectFakeWith connect to tr, which is ft with optional interfaces
+func connectFakeWith(t *testing.T, ft *fakeTransport, tr Transport) *EasySimConnect {
+	esc := NewEasySimConnectWithTransport(context.Background(), tr)
+	c, err := esc.Connect("test")
+	require.NoError(t, err)
+	ft.push(SIMCONNECT_RECV_ID_OPEN, make([]byte, 256+10*4))
+	select {
+	case ok := <-c:
+		require.True(t, ok)
+	case <-time.After(2 * time.Second):
+		t.Fatal("open not dispatched")
+	}
+	return esc
+}
+
+func TestRunDispatchSimVar(t *testing.T) {
+	esc, ft := connectFake(t)
+	defer esc.Close()
+
+	cSimVar, err := esc.ConnectToSimVar(SimVarPlaneAltitude())
+	require.NoError(t, err)
+	assert.True(t, ft.called("AddToDataDefinition"))
+	assert.True(t, ft.called("RequestDataOnSimObject"))
+
+	// request, object, define, flags, entry, out of, define count
+	ft.push(SIMCONNECT_RECV_ID_SIMOBJECT_DATA_BYTYPE, []uint32{0, 0, 0, 0, 1, 1, 1}, float64(1234.5))
+	select {
+	case vars := <-cSimVar:
+		require.Len(t, vars, 1)
+		f, err := vars[0].GetFloat64()
+		require.NoError(t, err)
+		assert.Equal(t, 1234.5, f)
+	case <-time.After(2 * time.Second):
+		t.Fatal("simvar not dispatched")
+	}
+}
+
+func TestRunDispatchSysEvent(t *testing.T) {
+	esc, ft := connectFake(t)
+	defer esc.Close()
+
+	paused := esc.ConnectSysEventPause()
+	assert.True(t, ft.called("SubscribeToSystemEvent"))
+
+	// group, event, data
+	ft.push(SIMCONNECT_RECV_ID_EVENT, []uint32{0, esc.indexEvent, 1})
+	select {
+	case p := <-paused:
+		assert.True(t, p)
+	case <-time.After(2 * time.Second):
+		t.Fatal("event not dispatched")
+	}
+}
+
+func TestTransportOptionalFeatures(t *testing.T) {
+	sc := NewSimConnectWithTransport(newFakeTransport())
+	err, _ := sc.AIRemoveObject(1, 2)
+	assert.ErrorIs(t, err, ErrTransportUnsupported)
+	assert.ErrorContains(t, err, "AITransport")
+	err, _ = sc.MenuAddItem("simgo", 1, 0)
+	assert.ErrorIs(t, err, ErrTransportUnsupported)
+
+	err, id := sc.ClearDataDefinition(1)
+	assert.NoError(t, err)
+	assert.Equal(t, uint32(1), id)
+}
+
+func TestRunDispatchUnknownException(t *testing.T) {
+	esc, ft := connectFake(t)
+	defer esc.Close()
+
+	errs := make(chan error, 1)
+	go func() {
+		_, err := esc.request(context.Background(), "RequestDataOnSimObject", func(requestID uint32) (error, uint32) {
+			return esc.sc.RequestDataOnSimObject(requestID, 0, SIMCONNECT_OBJECT_ID_USER, SIMCONNECT_PERIOD_ONCE, 0, 0, 0, 0)
+		})
+		errs <- err
+	}()
+	// exception, send ID of no pending call, index
+	ft.push(SIMCONNECT_RECV_ID_EXCEPTION, []uint32{SIMCONNECT_EXCEPTION_ERROR, 999, 0})
+	select {
+	case err := <-errs:
+		assert.ErrorContains(t, err, "connection closed by SIMCONNECT_EXCEPTION_ERROR")
+	case <-time.After(2 * time.Second):
+		t.Fatal("pending request not failed")
+	}
+	assert.False(t, esc.IsAlive())
+}
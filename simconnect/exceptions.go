@@ -82,3 +82,9 @@ func getTextException(i uint32) string {
 		return "Unknow exception"
 	}
 }
+
+// fatalException return true for the exceptions ending the session whichever call caused them: the connection
+// is not opened, the protocol versions do not match or the exception is unknown
+func fatalException(exception uint32) bool {
+	return exception == SIMCONNECT_EXCEPTION_UNOPENED || exception == SIMCONNECT_EXCEPTION_VERSION_MISMATCH || exception > SIMCONNECT_EXCEPTION_OBJECT_SCHEDULE
+}
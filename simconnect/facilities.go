@@ -0,0 +1,299 @@
+package simconnect
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+	"unsafe"
+)
+
+// Facility is a FacilityAirport, FacilityWaypoint, FacilityNDB or FacilityVOR
+type Facility interface {
+	// Key identify the facility by its region and ident
+	Key() string
+}
+
+// FacilityAirport is an airport of RequestAirports
+type FacilityAirport struct {
+	// Ident is the ICAO code, Region the ICAO region sent by MSFS only
+	Ident     string
+	Region    string
+	Latitude  float64 // degrees
+	Longitude float64 // degrees
+	Altitude  float64 // meters
+}
+
+// Key identify the facility by its region and ident
+func (f FacilityAirport) Key() string {
+	return f.Region + "/" + f.Ident
+}
+
+// FacilityWaypoint is a waypoint of RequestWaypoints
+type FacilityWaypoint struct {
+	FacilityAirport
+	MagVar float32 // degrees
+}
+
+// FacilityNDB is a NDB of RequestNDBs
+type FacilityNDB struct {
+	FacilityWaypoint
+	Frequency uint32 // Hz
+}
+
+// FacilityVOR is a VOR or an ILS of RequestVORs
+type FacilityVOR struct {
+	FacilityNDB
+	// Flags is a combination of SIMCONNECT_RECV_ID_VOR_LIST_HAS_*
+	Flags     uint32
+	Localizer float32 // degrees
+	// GlideLat, GlideLon (degrees) and GlideAlt (meters) are the location of the glide slope
+	GlideLat        float64
+	GlideLon        float64
+	GlideAlt        float64
+	GlideSlopeAngle float32 // degrees
+}
+
+// FacilityDiff is the change of the facilities in the reality bubble
+type FacilityDiff struct {
+	Added   []Facility
+	Removed []Facility
+	// Snapshot is every facility in the reality bubble by Key
+	Snapshot map[string]Facility
+}
+
+// sizes of the packed SIMCONNECT_DATA_FACILITY_* structures
+const (
+	facilityAirportSize  = 9 + 3*8
+	facilityWaypointSize = facilityAirportSize + 4
+	facilityNDBSize      = facilityWaypointSize + 4
+	facilityVORSize      = facilityNDBSize + 4 + 4 + 3*8 + 4
+)
+
+// convBytesToFacilities decode a SIMCONNECT_RECV_*_LIST, it return the request ID, the entry number, the number
+// of entries and the facilities
+func convBytesToFacilities(id uint32, buf []byte) (uint32, uint32, uint32, []Facility) {
+	var recv SIMCONNECT_RECV_FACILITIES_LIST
+	header := int(unsafe.Sizeof(recv))
+	if len(buf) < header {
+		return 0, 0, 0, nil
+	}
+	requestID := binary.LittleEndian.Uint32(buf[12:])
+	size := binary.LittleEndian.Uint32(buf[16:])
+	entry := binary.LittleEndian.Uint32(buf[20:])
+	outof := binary.LittleEndian.Uint32(buf[24:])
+	entrySize := map[uint32]int{
+		SIMCONNECT_RECV_ID_AIRPORT_LIST:  facilityAirportSize,
+		SIMCONNECT_RECV_ID_WAYPOINT_LIST: facilityWaypointSize,
+		SIMCONNECT_RECV_ID_NDB_LIST:      facilityNDBSize,
+		SIMCONNECT_RECV_ID_VOR_LIST:      facilityVORSize,
+	}[id]
+
+	list := make([]Facility, 0, size)
+	for i := 0; i < int(size) && header+(i+1)*entrySize <= len(buf); i++ {
+		d := buf[header+i*entrySize:]
+		f64 := func(pos int) float64 { return math.Float64frombits(binary.LittleEndian.Uint64(d[pos:])) }
+		f32 := func(pos int) float32 { return math.Float32frombits(binary.LittleEndian.Uint32(d[pos:])) }
+		airport := FacilityAirport{Latitude: f64(9), Longitude: f64(17), Altitude: f64(25)}
+		airport.Ident, airport.Region = convIcao(d[:9])
+		if id == SIMCONNECT_RECV_ID_AIRPORT_LIST {
+			list = append(list, airport)
+			continue
+		}
+		waypoint := FacilityWaypoint{airport, f32(33)}
+		if id == SIMCONNECT_RECV_ID_WAYPOINT_LIST {
+			list = append(list, waypoint)
+			continue
+		}
+		ndb := FacilityNDB{waypoint, binary.LittleEndian.Uint32(d[37:])}
+		if id == SIMCONNECT_RECV_ID_NDB_LIST {
+			list = append(list, ndb)
+			continue
+		}
+		list = append(list, FacilityVOR{ndb, binary.LittleEndian.Uint32(d[41:]), f32(45), f64(49), f64(57), f64(65), f32(73)})
+	}
+	return requestID, entry, outof, list
+}
+
+// convIcao split the 9 bytes ICAO of a facility. MSFS send the ident on 6 bytes then the region on 3 bytes,
+// FSX the ICAO on 9 bytes
+func convIcao(b []byte) (string, string) {
+	for i, c := range b[:6] {
+		if c == 0 {
+			return string(b[:i]), convStrToGoString(append(b[6:9:9], 0))
+		}
+	}
+	return convStrToGoString(append(b[:9:9], 0)), ""
+}
+
+// facilitiesList assemble the entries of a facility list, the list is sent to its subscription or to the
+// request waiting for it after the last entry
+func (esc *EasySimConnect) facilitiesList(id uint32, buf []byte) {
+	requestID, entry, outof, list := convBytesToFacilities(id, buf)
+	esc.facilityChunks[requestID] = append(esc.facilityChunks[requestID], list...)
+	if entry+1 < outof {
+		return
+	}
+	list = esc.facilityChunks[requestID]
+	delete(esc.facilityChunks, requestID)
+
+	esc.requestMu.Lock()
+	c, found := esc.listFacilities[requestID]
+	esc.requestMu.Unlock()
+	if !found {
+		esc.reply(requestID, list)
+		return
+	}
+	select {
+	case c <- list:
+	case <-time.After(esc.updateDelay()):
+	}
+}
+
+// RequestFacilitiesList return the facilities of listType (SIMCONNECT_FACILITY_LIST_TYPE_AIRPORT, _WAYPOINT,
+// _NDB or _VOR) in the reality bubble
+func (esc *EasySimConnect) RequestFacilitiesList(ctx context.Context, listType uint32) ([]Facility, error) {
+	list, err := esc.request(ctx, "RequestFacilitiesList", func(requestID uint32) (error, uint32) {
+		return esc.sc.RequestFacilitiesList(listType, requestID)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return list.([]Facility), nil
+}
+
+// RequestAirports return the airports in the reality bubble
+func (esc *EasySimConnect) RequestAirports(ctx context.Context) ([]FacilityAirport, error) {
+	return requestFacilities[FacilityAirport](ctx, esc, SIMCONNECT_FACILITY_LIST_TYPE_AIRPORT)
+}
+
+// RequestWaypoints return the waypoints in the reality bubble
+func (esc *EasySimConnect) RequestWaypoints(ctx context.Context) ([]FacilityWaypoint, error) {
+	return requestFacilities[FacilityWaypoint](ctx, esc, SIMCONNECT_FACILITY_LIST_TYPE_WAYPOINT)
+}
+
+// RequestNDBs return the NDBs in the reality bubble
+func (esc *EasySimConnect) RequestNDBs(ctx context.Context) ([]FacilityNDB, error) {
+	return requestFacilities[FacilityNDB](ctx, esc, SIMCONNECT_FACILITY_LIST_TYPE_NDB)
+}
+
+// RequestVORs return the VORs and ILS in the reality bubble
+func (esc *EasySimConnect) RequestVORs(ctx context.Context) ([]FacilityVOR, error) {
+	return requestFacilities[FacilityVOR](ctx, esc, SIMCONNECT_FACILITY_LIST_TYPE_VOR)
+}
+
+func requestFacilities[T Facility](ctx context.Context, esc *EasySimConnect, listType uint32) ([]T, error) {
+	list, err := esc.RequestFacilitiesList(ctx, listType)
+	if err != nil {
+		return nil, err
+	}
+	typed := make([]T, 0, len(list))
+	for _, f := range list {
+		if t, ok := f.(T); ok {
+			typed = append(typed, t)
+		}
+	}
+	return typed, nil
+}
+
+// SubscribeToFacilities return a chan receiving the facilities of listType entering and leaving the reality
+// bubble until ctx is done or the connection is closed, a failed request of the list is logged and retried at the
+// next interval. The first FacilityDiff adds every facility already in the bubble. The simulator only
+// notifies the entering facilities, the list is requested every interval (the delay of SetDelay when zero) to
+// find the leaving ones. A single subscription by listType is supported by SimConnect
+func (esc *EasySimConnect) SubscribeToFacilities(ctx context.Context, listType uint32, interval time.Duration) (<-chan FacilityDiff, error) {
+	if interval <= 0 {
+		interval = esc.updateDelay()
+	}
+	snapshot, err := esc.RequestFacilitiesList(ctx, listType)
+	if err != nil {
+		return nil, err
+	}
+	entering := make(chan []Facility, 16)
+	requestID := esc.nextRequestID()
+	esc.requestMu.Lock()
+	esc.listFacilities[requestID] = entering
+	esc.requestMu.Unlock()
+	err = esc.expect(func() (error, uint32) { return esc.sc.SubscribeToFacilities(listType, requestID) })
+	if err != nil {
+		esc.requestMu.Lock()
+		delete(esc.listFacilities, requestID)
+		esc.requestMu.Unlock()
+		return nil, fmt.Errorf("Error in SubscribeToFacilities : %#v", err)
+	}
+
+	c := make(chan FacilityDiff, 1)
+	known := make(map[string]Facility)
+	c <- *diffFacilities(known, snapshot, true)
+	go func() {
+		defer close(c)
+		defer func() {
+			esc.requestMu.Lock()
+			delete(esc.listFacilities, requestID)
+			esc.requestMu.Unlock()
+			if esc.alive.Load() {
+				esc.expect(func() (error, uint32) { return esc.sc.UnsubscribeToFacilities(listType) })
+			}
+		}()
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			var diff *FacilityDiff
+			select {
+			case <-ctx.Done():
+				return
+			case list := <-entering:
+				diff = diffFacilities(known, list, false)
+			case <-ticker.C:
+				list, err := esc.RequestFacilitiesList(ctx, listType)
+				if err != nil {
+					if ctx.Err() != nil || !esc.alive.Load() {
+						return
+					}
+					esc.logf(LogInfo, "Error in facilities subscription : %s", err)
+					continue
+				}
+				diff = diffFacilities(known, list, true)
+			}
+			if len(diff.Added)+len(diff.Removed) == 0 {
+				continue
+			}
+			select {
+			case c <- *diff:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return c, nil
+}
+
+// diffFacilities add list to known and return the change. The facilities of known missing from list are
+// removed when list is the whole bubble
+func diffFacilities(known map[string]Facility, list []Facility, whole bool) *FacilityDiff {
+	diff := &FacilityDiff{}
+	listed := make(map[string]struct{}, len(list))
+	for _, f := range list {
+		listed[f.Key()] = struct{}{}
+		if _, found := known[f.Key()]; !found {
+			known[f.Key()] = f
+			diff.Added = append(diff.Added, f)
+		}
+	}
+	if whole {
+		for key, f := range known {
+			if _, found := listed[key]; !found {
+				delete(known, key)
+				diff.Removed = append(diff.Removed, f)
+			}
+		}
+		sort.Slice(diff.Removed, func(i, j int) bool { return diff.Removed[i].Key() < diff.Removed[j].Key() })
+	}
+	diff.Snapshot = make(map[string]Facility, len(known))
+	for key, f := range known {
+		diff.Snapshot[key] = f
+	}
+	return diff
+}
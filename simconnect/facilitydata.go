@@ -0,0 +1,309 @@
+package simconnect
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"unsafe"
+)
+
+// AirportDataRequest select the children of the airport fetched by RequestAirportData
+type AirportDataRequest struct {
+	Runways     bool
+	Parkings    bool
+	Frequencies bool
+}
+
+// FacilityAirportData is an airport of RequestAirportData with the children selected by its AirportDataRequest
+type FacilityAirportData struct {
+	ICAO      string
+	Region    string
+	Name      string
+	Latitude  float64 // degrees
+	Longitude float64 // degrees
+	Altitude  float64 // meters
+	MagVar    float32 // degrees
+
+	Runways     []FacilityRunway
+	Parkings    []FacilityParking
+	Frequencies []FacilityFrequency
+}
+
+// FacilityRunway is a runway of an airport, the primary direction is the runway flown on Heading
+type FacilityRunway struct {
+	// Latitude, Longitude (degrees) and Altitude (meters) are the center of the runway
+	Latitude  float64
+	Longitude float64
+	Altitude  float64
+	Heading   float32 // degrees true
+	Length    float32 // meters
+	Width     float32 // meters
+	Surface   int32
+	// PrimaryNumber and SecondaryNumber are 1 to 36 (37 to 44 for N, NE to NW), the designators 0 for none then
+	// L, R, C, water, A and B
+	PrimaryNumber       int32
+	PrimaryDesignator   int32
+	SecondaryNumber     int32
+	SecondaryDesignator int32
+	// PrimaryThreshold and SecondaryThreshold are the lengths of the displaced thresholds in meters
+	PrimaryThreshold   float32
+	SecondaryThreshold float32
+}
+
+// FacilityParking is a parking spot or a gate of an airport
+type FacilityParking struct {
+	Type   int32
+	Name   int32
+	Suffix int32
+	Number uint32
+	// Heading is in degrees true, Radius in meters
+	Heading float32
+	Radius  float32
+	// BiasX and BiasZ are the position east and north of the airport in meters
+	BiasX float32
+	BiasZ float32
+}
+
+// FacilityFrequency is a COM frequency of an airport
+type FacilityFrequency struct {
+	Type      int32
+	Frequency int32 // Hz
+	Name      string
+}
+
+// facilityFields are the fields requested for each object type, in the order of their data
+var facilityFields = map[uint32][]string{
+	SIMCONNECT_FACILITY_DATA_AIRPORT:      {"ICAO", "REGION", "NAME", "LATITUDE", "LONGITUDE", "ALTITUDE", "MAGVAR"},
+	SIMCONNECT_FACILITY_DATA_RUNWAY:       {"LATITUDE", "LONGITUDE", "ALTITUDE", "HEADING", "LENGTH", "WIDTH", "SURFACE", "PRIMARY_NUMBER", "PRIMARY_DESIGNATOR", "SECONDARY_NUMBER", "SECONDARY_DESIGNATOR"},
+	SIMCONNECT_FACILITY_DATA_PAVEMENT:     {"LENGTH"},
+	SIMCONNECT_FACILITY_DATA_TAXI_PARKING: {"TYPE", "NAME", "SUFFIX", "NUMBER", "HEADING", "RADIUS", "BIAS_X", "BIAS_Z"},
+	SIMCONNECT_FACILITY_DATA_FREQUENCY:    {"TYPE", "FREQUENCY", "NAME"},
+}
+
+// airportDefinition return the fields of the facility definition of req
+func airportDefinition(req AirportDataRequest) []string {
+	object := func(name string, objectType uint32, children ...string) []string {
+		fields := append([]string{"OPEN " + name}, facilityFields[objectType]...)
+		fields = append(fields, children...)
+		return append(fields, "CLOSE "+name)
+	}
+	var children []string
+	if req.Runways {
+		children = append(children, object("RUNWAY", SIMCONNECT_FACILITY_DATA_RUNWAY,
+			append(object("PRIMARY_THRESHOLD", SIMCONNECT_FACILITY_DATA_PAVEMENT),
+				object("SECONDARY_THRESHOLD", SIMCONNECT_FACILITY_DATA_PAVEMENT)...)...)...)
+	}
+	if req.Parkings {
+		children = append(children, object("TAXI_PARKING", SIMCONNECT_FACILITY_DATA_TAXI_PARKING)...)
+	}
+	if req.Frequencies {
+		children = append(children, object("FREQUENCY", SIMCONNECT_FACILITY_DATA_FREQUENCY)...)
+	}
+	return object("AIRPORT", SIMCONNECT_FACILITY_DATA_AIRPORT, children...)
+}
+
+// facilityReader decode the packed fields of a SIMCONNECT_RECV_FACILITY_DATA, missing fields read as zero
+type facilityReader struct {
+	buf []byte
+	pos int
+}
+
+func (r *facilityReader) next(size int) []byte {
+	if r.pos+size > len(r.buf) {
+		r.pos = len(r.buf)
+		return make([]byte, size)
+	}
+	b := r.buf[r.pos : r.pos+size]
+	r.pos += size
+	return b
+}
+
+func (r *facilityReader) float64() float64 {
+	return math.Float64frombits(binary.LittleEndian.Uint64(r.next(8)))
+}
+
+func (r *facilityReader) float32() float32 {
+	return math.Float32frombits(binary.LittleEndian.Uint32(r.next(4)))
+}
+
+func (r *facilityReader) int32() int32 {
+	return int32(binary.LittleEndian.Uint32(r.next(4)))
+}
+
+func (r *facilityReader) uint32() uint32 {
+	return binary.LittleEndian.Uint32(r.next(4))
+}
+
+func (r *facilityReader) string(size int) string {
+	return convStrToGoString(append(r.next(size)[:size:size], 0))
+}
+
+// airportData is an airport being received, by unique request ID of its runways
+type airportData struct {
+	airport    *FacilityAirportData
+	runways    map[uint32]int
+	thresholds map[uint32]int
+}
+
+// facilityDataItem add an object of a SIMCONNECT_RECV_FACILITY_DATA to the airport of its request
+func (esc *EasySimConnect) facilityDataItem(buf []byte) {
+	var recv SIMCONNECT_RECV_FACILITY_DATA
+	header := int(unsafe.Offsetof(recv.Data))
+	if len(buf) < header {
+		return
+	}
+	requestID := binary.LittleEndian.Uint32(buf[12:])
+	uniqueID := binary.LittleEndian.Uint32(buf[16:])
+	parentID := binary.LittleEndian.Uint32(buf[20:])
+	objectType := binary.LittleEndian.Uint32(buf[24:])
+	r := &facilityReader{buf: buf[header:]}
+
+	if objectType == SIMCONNECT_FACILITY_DATA_AIRPORT {
+		a := &FacilityAirportData{ICAO: r.string(8), Region: r.string(8), Name: r.string(32)}
+		a.Latitude, a.Longitude, a.Altitude, a.MagVar = r.float64(), r.float64(), r.float64(), r.float32()
+		esc.facilityData[requestID] = &airportData{a, make(map[uint32]int), make(map[uint32]int)}
+		return
+	}
+	d, found := esc.facilityData[requestID]
+	if !found {
+		esc.logf(LogInfo, "Ignored facility data of request %d", requestID)
+		return
+	}
+	switch objectType {
+	case SIMCONNECT_FACILITY_DATA_RUNWAY:
+		d.runways[uniqueID] = len(d.airport.Runways)
+		d.airport.Runways = append(d.airport.Runways, FacilityRunway{
+			r.float64(), r.float64(), r.float64(), r.float32(), r.float32(), r.float32(),
+			r.int32(), r.int32(), r.int32(), r.int32(), r.int32(), 0, 0,
+		})
+	case SIMCONNECT_FACILITY_DATA_PAVEMENT:
+		i, found := d.runways[parentID]
+		if !found {
+			return
+		}
+		// the primary threshold is defined before the secondary threshold
+		if d.thresholds[parentID] == 0 {
+			d.airport.Runways[i].PrimaryThreshold = r.float32()
+		} else {
+			d.airport.Runways[i].SecondaryThreshold = r.float32()
+		}
+		d.thresholds[parentID]++
+	case SIMCONNECT_FACILITY_DATA_TAXI_PARKING:
+		d.airport.Parkings = append(d.airport.Parkings, FacilityParking{
+			r.int32(), r.int32(), r.int32(), r.uint32(), r.float32(), r.float32(), r.float32(), r.float32(),
+		})
+	case SIMCONNECT_FACILITY_DATA_FREQUENCY:
+		d.airport.Frequencies = append(d.airport.Frequencies, FacilityFrequency{r.int32(), r.int32(), r.string(64)})
+	}
+}
+
+// facilityDataEnd send the airport of a request, nil when the simulator sent no airport
+func (esc *EasySimConnect) facilityDataEnd(requestID uint32) {
+	var airport *FacilityAirportData
+	if d, found := esc.facilityData[requestID]; found {
+		airport = d.airport
+		delete(esc.facilityData, requestID)
+	}
+	esc.reply(requestID, airport)
+}
+
+// airportDefinitionID return the facility definition of req, it is added on first use
+func (esc *EasySimConnect) airportDefinitionID(req AirportDataRequest) (uint32, error) {
+	esc.requestMu.Lock()
+	defer esc.requestMu.Unlock()
+	if defineID, found := esc.facilityDefinitions[req]; found {
+		return defineID, nil
+	}
+	esc.indexRequest++
+	defineID := esc.indexRequest
+	for _, field := range airportDefinition(req) {
+		err := esc.expect(func() (error, uint32) { return esc.sc.AddToFacilityDefinition(defineID, field) })
+		if err != nil {
+			return 0, fmt.Errorf("Error in AddToFacilityDefinition : %#v", err)
+		}
+	}
+	esc.facilityDefinitions[req] = defineID
+	return defineID, nil
+}
+
+// RequestAirportData return the airport icao with the runways, parkings and frequencies selected by req
+func (esc *EasySimConnect) RequestAirportData(ctx context.Context, icao string, req AirportDataRequest) (*FacilityAirportData, error) {
+	defineID, err := esc.airportDefinitionID(req)
+	if err != nil {
+		return nil, err
+	}
+	airport, err := esc.request(ctx, "RequestFacilityData", func(requestID uint32) (error, uint32) {
+		return esc.sc.RequestFacilityData(defineID, requestID, icao, "")
+	})
+	if err != nil {
+		return nil, err
+	}
+	if airport.(*FacilityAirportData) == nil {
+		return nil, fmt.Errorf("Error in RequestFacilityData : airport %s not found", icao)
+	}
+	return airport.(*FacilityAirportData), nil
+}
+
+// runwayIdent return the ident of a runway direction like 09L
+func runwayIdent(number, designator int32) string {
+	ident := fmt.Sprintf("%02d", number)
+	if number > 36 && number <= 44 {
+		ident = [...]string{"N", "NE", "E", "SE", "S", "SW", "W", "NW"}[number-37]
+	}
+	if designator > 0 && designator <= 6 {
+		ident += [...]string{"L", "R", "C", "W", "A", "B"}[designator-1]
+	}
+	return ident
+}
+
+// PrimaryIdent return the ident of the primary direction like 09L
+func (r FacilityRunway) PrimaryIdent() string {
+	return runwayIdent(r.PrimaryNumber, r.PrimaryDesignator)
+}
+
+// SecondaryIdent return the ident of the secondary direction like 27R
+func (r FacilityRunway) SecondaryIdent() string {
+	return runwayIdent(r.SecondaryNumber, r.SecondaryDesignator)
+}
+
+// PrimaryThresholdPosition return the latitude and the longitude in degrees of the landing threshold of the
+// primary direction
+func (r FacilityRunway) PrimaryThresholdPosition() (float64, float64) {
+	return offset(r.Latitude, r.Longitude, float64(r.Heading), float64(r.PrimaryThreshold-r.Length/2))
+}
+
+// SecondaryThresholdPosition return the latitude and the longitude in degrees of the landing threshold of the
+// secondary direction
+func (r FacilityRunway) SecondaryThresholdPosition() (float64, float64) {
+	return offset(r.Latitude, r.Longitude, float64(r.Heading), float64(r.Length/2-r.SecondaryThreshold))
+}
+
+// ParkingPosition return the latitude and the longitude in degrees of a parking of the airport
+func (a *FacilityAirportData) ParkingPosition(p FacilityParking) (float64, float64) {
+	lat, lon := offset(a.Latitude, a.Longitude, 0, float64(p.BiasZ))
+	return offset(lat, lon, 90, float64(p.BiasX))
+}
+
+// ParkingAt return the parking whose radius contains lat, lon in degrees
+func (a *FacilityAirportData) ParkingAt(lat, lon float64) (FacilityParking, bool) {
+	for _, p := range a.Parkings {
+		pLat, pLon := a.ParkingPosition(p)
+		north := (lat - pLat) * math.Pi / 180 * earthRadius
+		east := (lon - pLon) * math.Pi / 180 * earthRadius * math.Cos(pLat*math.Pi/180)
+		if math.Hypot(north, east) <= float64(p.Radius) {
+			return p, true
+		}
+	}
+	return FacilityParking{}, false
+}
+
+const earthRadius = 6371000
+
+// offset move lat, lon in degrees by distance meters on heading in degrees true
+func offset(lat, lon, heading, distance float64) (float64, float64) {
+	rad := heading * math.Pi / 180
+	lat2 := lat + distance*math.Cos(rad)/earthRadius*180/math.Pi
+	lon2 := lon + distance*math.Sin(rad)/(earthRadius*math.Cos(lat*math.Pi/180))*180/math.Pi
+	return lat2, lon2
+}
@@ -0,0 +1,67 @@
+package simconnect
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunwayIdent(t *testing.T) {
+	r := FacilityRunway{PrimaryNumber: 9, PrimaryDesignator: 1, SecondaryNumber: 27, SecondaryDesignator: 2}
+	assert.Equal(t, "09L", r.PrimaryIdent())
+	assert.Equal(t, "27R", r.SecondaryIdent())
+	assert.Equal(t, "NE", runwayIdent(38, 0))
+}
+
+func TestRunwayThresholdPosition(t *testing.T) {
+	r := FacilityRunway{Latitude: 48, Longitude: 2, Heading: 90, Length: 3000, PrimaryThreshold: 500}
+	lat, lon := r.PrimaryThresholdPosition()
+	assert.InDelta(t, 48, lat, 1e-9)
+	assert.InDelta(t, 2-1000/(earthRadius*0.66913)*57.29578, lon, 1e-5)
+	lat, lon = r.SecondaryThresholdPosition()
+	assert.InDelta(t, 48, lat, 1e-9)
+	assert.InDelta(t, 2+1500/(earthRadius*0.66913)*57.29578, lon, 1e-5)
+}
+
+func TestParkingAt(t *testing.T) {
+	a := &FacilityAirportData{Latitude: 48, Longitude: 2, Parkings: []FacilityParking{
+		{Number: 1, Radius: 20, BiasX: 100, BiasZ: 0},
+		{Number: 2, Radius: 20, BiasX: 150, BiasZ: 0},
+	}}
+	lat, lon := a.ParkingPosition(a.Parkings[1])
+	p, found := a.ParkingAt(lat+0.0001, lon)
+	assert.True(t, found)
+	assert.Equal(t, uint32(2), p.Number)
+	_, found = a.ParkingAt(48.01, 2)
+	assert.False(t, found)
+}
+
+func TestRequestAirportDataException(t *testing.T) {
+	ft := newFakeTransport()
+	esc := connectFakeWith(t, ft, fakeFacilities{ft})
+	defer esc.Close()
+
+	errs := make(chan error, 1)
+	go func() {
+		_, err := esc.RequestAirportData(context.Background(), "LFXX", AirportDataRequest{Runways: true})
+		errs <- err
+	}()
+	var sendID uint32
+	require.Eventually(t, func() bool {
+		id, found := ft.sent("RequestFacilityData")
+		sendID = id
+		return found
+	}, 2*time.Second, 10*time.Millisecond)
+	// exception, send ID, index
+	ft.push(SIMCONNECT_RECV_ID_EXCEPTION, []uint32{SIMCONNECT_EXCEPTION_ERROR, sendID, 0})
+	select {
+	case err := <-errs:
+		assert.ErrorContains(t, err, "RequestFacilityData : SIMCONNECT_EXCEPTION_ERROR")
+	case <-time.After(2 * time.Second):
+		t.Fatal("request not failed")
+	}
+	assert.True(t, esc.IsAlive())
+}
@@ -0,0 +1,273 @@
+package simconnect
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+)
+
+// Layout of the input event messages, SimConnect.h is packed
+const (
+	inputEventDescriptorSize = 76 // char Name[64], UINT64 Hash, SIMCONNECT_INPUT_EVENT_TYPE eType
+	inputEventListOffset     = 28 // SIMCONNECT_RECV_LIST_TEMPLATE
+)
+
+// InputEvent describe an input event of the aircraft, a cockpit control like AUTOPILOT_KNOB_HEADING. Type is
+// SIMCONNECT_INPUT_EVENT_TYPE_DOUBLE or SIMCONNECT_INPUT_EVENT_TYPE_STRING
+type InputEvent struct {
+	Name string
+	Hash uint64
+	Type uint32
+}
+
+// InputEvents is the catalog of the input events of the aircraft
+type InputEvents []InputEvent
+
+// Get return the input event named name, the case is ignored
+func (l InputEvents) Get(name string) (InputEvent, bool) {
+	for _, e := range l {
+		if strings.EqualFold(e.Name, name) {
+			return e, true
+		}
+	}
+	return InputEvent{}, false
+}
+
+// Find return the input events whose name contains every word of query, the case is ignored.
+// Find("knob heading") finds AUTOPILOT_KNOB_HEADING
+func (l InputEvents) Find(query string) InputEvents {
+	words := strings.Fields(strings.ToUpper(query))
+	found := make(InputEvents, 0)
+	for _, e := range l {
+		name := strings.ToUpper(e.Name)
+		match := true
+		for _, w := range words {
+			match = match && strings.Contains(name, w)
+		}
+		if match {
+			found = append(found, e)
+		}
+	}
+	return found
+}
+
+// InputEventValue is the value of an input event, Double or String depending on Type
+type InputEventValue struct {
+	Hash   uint64
+	Type   uint32
+	Double float64
+	String string
+}
+
+// convInputEventValue decode the value of an input event
+func convInputEventValue(hash uint64, t uint32, buf []byte) InputEventValue {
+	value := InputEventValue{Hash: hash, Type: t}
+	if t == SIMCONNECT_INPUT_EVENT_TYPE_STRING {
+		value.String = convStrToGoString(buf)
+	} else if len(buf) >= 8 {
+		value.Double = math.Float64frombits(binary.LittleEndian.Uint64(buf))
+	}
+	return value
+}
+
+// convBytesToInputEvents decode a SIMCONNECT_RECV_ENUMERATE_INPUT_EVENTS
+func convBytesToInputEvents(buf []byte) (requestID, entry, outof uint32, list InputEvents) {
+	requestID = binary.LittleEndian.Uint32(buf[12:])
+	size := binary.LittleEndian.Uint32(buf[16:])
+	entry = binary.LittleEndian.Uint32(buf[20:])
+	outof = binary.LittleEndian.Uint32(buf[24:])
+	list = make(InputEvents, 0, size)
+	for i := 0; i < int(size); i++ {
+		offset := inputEventListOffset + i*inputEventDescriptorSize
+		if offset+inputEventDescriptorSize > len(buf) {
+			break
+		}
+		d := buf[offset:]
+		list = append(list, InputEvent{
+			Name: convStrToGoString(d[:64]),
+			Hash: binary.LittleEndian.Uint64(d[64:]),
+			Type: binary.LittleEndian.Uint32(d[72:]),
+		})
+	}
+	return requestID, entry, outof, list
+}
+
+// inputEvents accumulate the chunks of a SIMCONNECT_RECV_ENUMERATE_INPUT_EVENTS and reply with the catalog
+func (esc *EasySimConnect) inputEvents(buf []byte) {
+	requestID, entry, outof, list := convBytesToInputEvents(buf)
+	esc.inputEventChunks[requestID] = append(esc.inputEventChunks[requestID], list...)
+	if entry+1 < outof {
+		return
+	}
+	list = esc.inputEventChunks[requestID]
+	delete(esc.inputEventChunks, requestID)
+	esc.reply(requestID, list)
+}
+
+// inputEventChanged send the value of a SIMCONNECT_RECV_SUBSCRIBE_INPUT_EVENT to the subscriptions of the event
+func (esc *EasySimConnect) inputEventChanged(buf []byte) {
+	hash := binary.LittleEndian.Uint64(buf[12:])
+	value := convInputEventValue(hash, binary.LittleEndian.Uint32(buf[20:]), buf[24:])
+	esc.requestMu.Lock()
+	listen := make([]chan InputEventValue, 0, len(esc.listInputEvents[hash]))
+	for c := range esc.listInputEvents[hash] {
+		listen = append(listen, c)
+	}
+	esc.requestMu.Unlock()
+	for _, c := range listen {
+		select {
+		case c <- value:
+		case <-time.After(esc.updateDelay()):
+		}
+	}
+}
+
+// inputEventParams reply to EnumerateInputEventParams, the SIMCONNECT_RECV_ENUMERATE_INPUT_EVENT_PARAMS
+// carries the hash of the event, not the request ID, so it answers the oldest request of the hash
+func (esc *EasySimConnect) inputEventParams(buf []byte) {
+	hash := binary.LittleEndian.Uint64(buf[12:])
+	esc.requestMu.Lock()
+	requests := esc.inputEventParamRequests[hash]
+	if len(requests) == 0 {
+		esc.requestMu.Unlock()
+		return
+	}
+	requestID := requests[0]
+	esc.forgetInputEventParams(hash, requestID)
+	esc.requestMu.Unlock()
+	params := make([]string, 0)
+	for _, p := range strings.Split(convStrToGoString(buf[20:]), ";") {
+		if p = strings.TrimSpace(p); p != "" {
+			params = append(params, p)
+		}
+	}
+	esc.reply(requestID, params)
+}
+
+// EnumerateInputEvents return the catalog of the input events of the aircraft (MSFS SU13 and later)
+func (esc *EasySimConnect) EnumerateInputEvents(ctx context.Context) (InputEvents, error) {
+	list, err := esc.request(ctx, "EnumerateInputEvents", func(requestID uint32) (error, uint32) {
+		return esc.sc.EnumerateInputEvents(requestID)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return list.(InputEvents), nil
+}
+
+// GetInputEvent return the current value of the input event hash
+func (esc *EasySimConnect) GetInputEvent(ctx context.Context, hash uint64) (InputEventValue, error) {
+	value, err := esc.request(ctx, "GetInputEvent", func(requestID uint32) (error, uint32) {
+		return esc.sc.GetInputEvent(requestID, hash)
+	})
+	if err != nil {
+		return InputEventValue{}, err
+	}
+	v := value.(InputEventValue)
+	v.Hash = hash
+	return v, nil
+}
+
+// SetInputEvent set the value of a SIMCONNECT_INPUT_EVENT_TYPE_DOUBLE input event, the exception of an unknown
+// hash is logged
+func (esc *EasySimConnect) SetInputEvent(hash uint64, value float64) error {
+	buf := binary.LittleEndian.AppendUint64(nil, math.Float64bits(value))
+	err := esc.expect(func() (error, uint32) { return esc.sc.SetInputEvent(hash, uint32(len(buf)), buf) })
+	if err != nil {
+		return fmt.Errorf("Error in SetInputEvent : %#v", err)
+	}
+	return nil
+}
+
+// SetInputEventString set the value of a SIMCONNECT_INPUT_EVENT_TYPE_STRING input event, the exception of an
+// unknown hash is logged
+func (esc *EasySimConnect) SetInputEventString(hash uint64, value string) error {
+	buf := append([]byte(value), 0)
+	err := esc.expect(func() (error, uint32) { return esc.sc.SetInputEvent(hash, uint32(len(buf)), buf) })
+	if err != nil {
+		return fmt.Errorf("Error in SetInputEvent : %#v", err)
+	}
+	return nil
+}
+
+// SubscribeInputEvent return a chan receiving the value of the input event hash each time it changes. The
+// subscription stops and the chan is no longer fed when ctx is done
+func (esc *EasySimConnect) SubscribeInputEvent(ctx context.Context, hash uint64) (<-chan InputEventValue, error) {
+	c := make(chan InputEventValue, 1)
+	esc.requestMu.Lock()
+	listen, found := esc.listInputEvents[hash]
+	if !found {
+		listen = make(map[chan InputEventValue]struct{})
+		esc.listInputEvents[hash] = listen
+	}
+	listen[c] = struct{}{}
+	esc.requestMu.Unlock()
+	unsubscribe := func() {
+		esc.requestMu.Lock()
+		delete(listen, c)
+		last := len(listen) == 0
+		if last {
+			delete(esc.listInputEvents, hash)
+		}
+		esc.requestMu.Unlock()
+		if last && esc.alive.Load() {
+			esc.expect(func() (error, uint32) { return esc.sc.UnsubscribeInputEvent(hash) })
+		}
+	}
+	if !found {
+		exception, err := esc.check(func() (error, uint32) { return esc.sc.SubscribeInputEvent(hash) })
+		if err != nil {
+			unsubscribe()
+			return nil, fmt.Errorf("Error in SubscribeInputEvent : %#v", err)
+		}
+		if exception != nil {
+			unsubscribe()
+			return nil, fmt.Errorf("Error in SubscribeInputEvent : %s", getTextException(exception.dwException))
+		}
+	}
+	go func() {
+		<-ctx.Done()
+		unsubscribe()
+	}()
+	return c, nil
+}
+
+// forgetInputEventParams remove requestID from the EnumerateInputEventParams requests of hash, requestMu is held
+func (esc *EasySimConnect) forgetInputEventParams(hash uint64, requestID uint32) {
+	requests := esc.inputEventParamRequests[hash]
+	for i, id := range requests {
+		if id == requestID {
+			requests = append(requests[:i:i], requests[i+1:]...)
+			break
+		}
+	}
+	if len(requests) == 0 {
+		delete(esc.inputEventParamRequests, hash)
+		return
+	}
+	esc.inputEventParamRequests[hash] = requests
+}
+
+// EnumerateInputEventParams return the types of the parameters of the input event hash
+func (esc *EasySimConnect) EnumerateInputEventParams(ctx context.Context, hash uint64) ([]string, error) {
+	var sent uint32
+	defer func() {
+		esc.requestMu.Lock()
+		esc.forgetInputEventParams(hash, sent)
+		esc.requestMu.Unlock()
+	}()
+	params, err := esc.request(ctx, "EnumerateInputEventParams", func(requestID uint32) (error, uint32) {
+		sent = requestID
+		esc.requestMu.Lock()
+		esc.inputEventParamRequests[hash] = append(esc.inputEventParamRequests[hash], requestID)
+		esc.requestMu.Unlock()
+		return esc.sc.EnumerateInputEventParams(hash)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return params.([]string), nil
+}
@@ -0,0 +1,43 @@
+package simconnect
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInputEventsFind(t *testing.T) {
+	catalog := InputEvents{
+		{Name: "AUTOPILOT_KNOB_HEADING", Hash: 1},
+		{Name: "AUTOPILOT_KNOB_ALTITUDE", Hash: 2},
+		{Name: "HEADING_BUG_SET", Hash: 3},
+	}
+	assert.Equal(t, InputEvents{catalog[0]}, catalog.Find("knob heading"))
+	assert.Equal(t, InputEvents{catalog[0], catalog[2]}, catalog.Find("HEADING"))
+	assert.Empty(t, catalog.Find("flaps"))
+	assert.Equal(t, catalog, catalog.Find(""))
+
+	e, found := catalog.Get("heading_bug_set")
+	assert.True(t, found)
+	assert.Equal(t, uint64(3), e.Hash)
+	_, found = catalog.Get("HEADING")
+	assert.False(t, found)
+}
+
+func TestConvBytesToInputEvents(t *testing.T) {
+	buf := make([]byte, inputEventListOffset+2*inputEventDescriptorSize)
+	for i, v := range []uint32{0, 0, 0, 7, 2, 1, 3} {
+		buf[i*4] = byte(v)
+	}
+	copy(buf[inputEventListOffset:], "FLAPS_LEVER")
+	buf[inputEventListOffset+64] = 42
+	copy(buf[inputEventListOffset+inputEventDescriptorSize:], "XPNDR_CODE")
+	buf[inputEventListOffset+inputEventDescriptorSize+72] = SIMCONNECT_INPUT_EVENT_TYPE_STRING
+
+	requestID, entry, outof, list := convBytesToInputEvents(buf)
+	assert.Equal(t, []uint32{7, 1, 3}, []uint32{requestID, entry, outof})
+	assert.Equal(t, InputEvents{
+		{Name: "FLAPS_LEVER", Hash: 42, Type: SIMCONNECT_INPUT_EVENT_TYPE_DOUBLE},
+		{Name: "XPNDR_CODE", Type: SIMCONNECT_INPUT_EVENT_TYPE_STRING},
+	}, list)
+}
@@ -0,0 +1,231 @@
+package simconnect
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// The MobiFlight WASM module runs in the simulator and reads and writes local variables on behalf of SimConnect
+// clients. Commands are strings written into the <client>.Command client data area, answers are written by the
+// module into <client>.Response and the registered L:vars are floats in <client>.LVars, in registration order
+const (
+	mobiFlightClient = "MobiFlight"
+	// mobiFlightMessageSize is the size of the command and response areas
+	mobiFlightMessageSize = 1024
+	// mobiFlightMaxLVars is the number of floats of the LVars area
+	mobiFlightMaxLVars = SIMCONNECT_CLIENTDATA_MAX_SIZE / 4
+	// mobiFlightTimeout is the delay for the module to register a client
+	mobiFlightTimeout = 5 * time.Second
+)
+
+// mobiFlightMessage is the content of a command or response area, a null terminated string
+type mobiFlightMessage [mobiFlightMessageSize]byte
+
+func newMobiFlightMessage(s string) mobiFlightMessage {
+	var m mobiFlightMessage
+	copy(m[:mobiFlightMessageSize-1], s)
+	return m
+}
+
+func (m mobiFlightMessage) String() string {
+	return convStrToGoString(m[:])
+}
+
+// LVar is the value of a local variable
+type LVar struct {
+	Name  string
+	Value float64
+}
+
+// LVarClient reads and writes the local variables (L:vars) of the aircraft, runs calculator code and fires
+// H:events through the MobiFlight WASM module, it must be installed in the Community folder of MSFS
+type LVarClient struct {
+	esc  *EasySimConnect
+	ctx  context.Context
+	name string
+
+	mu sync.Mutex
+	// lvars are the registered L:vars by name, index is their position in the LVars area
+	lvars  map[string]*lvarState
+	count  int
+	last   string
+	listen map[chan LVar]struct{}
+}
+
+type lvarState struct {
+	index    int
+	value    float64
+	received bool
+}
+
+// NewLVarClient register the client name with the MobiFlight WASM module and return the L:var client. The name must
+// be unique among the clients of the module. The client stops when ctx is done
+func NewLVarClient(ctx context.Context, esc *EasySimConnect, name string) (*LVarClient, error) {
+	if name == "" || strings.ContainsAny(name, ". ") {
+		return nil, fmt.Errorf("invalid MobiFlight client name %q", name)
+	}
+	waitCtx, cancel := context.WithTimeout(ctx, mobiFlightTimeout)
+	defer cancel()
+	responses, err := SubscribeClientData[mobiFlightMessage](waitCtx, esc, mobiFlightClient+".Response")
+	if err != nil {
+		return nil, err
+	}
+	command := "MF.Clients.Add." + name
+	if err := SetClientData(esc, mobiFlightClient+".Command", newMobiFlightMessage(command)); err != nil {
+		return nil, err
+	}
+	for done := false; !done; {
+		select {
+		case response := <-responses:
+			done = response.String() == command+".Finished"
+		case <-waitCtx.Done():
+			return nil, fmt.Errorf("MobiFlight WASM module not responding : %w", waitCtx.Err())
+		}
+	}
+
+	lc := &LVarClient{
+		esc:    esc,
+		ctx:    ctx,
+		name:   name,
+		lvars:  make(map[string]*lvarState),
+		listen: make(map[chan LVar]struct{}),
+	}
+	// the L:vars registered by a previous session of the client are dropped
+	if err := lc.command("MF.SimVars.Clear"); err != nil {
+		return nil, err
+	}
+	return lc, nil
+}
+
+// command write a command into the command area of the client. The module is notified only when the area
+// changes, a command repeated is preceded by a dummy one
+func (lc *LVarClient) command(command string) error {
+	if command == lc.last {
+		if err := SetClientData(lc.esc, lc.name+".Command", newMobiFlightMessage("MF.DummyCmd")); err != nil {
+			return err
+		}
+	}
+	lc.last = command
+	return SetClientData(lc.esc, lc.name+".Command", newMobiFlightMessage(command))
+}
+
+// lvarName return the name of an L:var without the L: prefix, A32NX_SEAT_BELT for (L:A32NX_SEAT_BELT)
+func lvarName(name string) string {
+	name = strings.TrimSuffix(strings.TrimPrefix(name, "("), ")")
+	return strings.TrimPrefix(name, "L:")
+}
+
+// Register ask the module to follow the L:vars, their values are received each time they change.
+// Registering an L:var again does nothing
+func (lc *LVarClient) Register(names ...string) error {
+	lc.mu.Lock()
+	defer lc.mu.Unlock()
+	for _, name := range names {
+		name = lvarName(name)
+		if _, found := lc.lvars[name]; found {
+			continue
+		}
+		if lc.count >= mobiFlightMaxLVars {
+			return fmt.Errorf("too many L:vars, %d max", mobiFlightMaxLVars)
+		}
+		if err := lc.command("MF.SimVars.Add.(L:" + name + ")"); err != nil {
+			return err
+		}
+		state := &lvarState{index: lc.count}
+		lc.count++
+		lc.lvars[name] = state
+		if err := lc.follow(name, state.index); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// follow request the float of index in the LVars area each time it changes
+func (lc *LVarClient) follow(name string, index int) error {
+	clientDataID, err := lc.esc.MapClientDataNameToID(lc.name + ".LVars")
+	if err != nil {
+		return err
+	}
+	defineID := lc.esc.nextRequestID()
+	datumType := int32(SIMCONNECT_CLIENTDATATYPE_FLOAT32)
+	err = lc.esc.expect(func() (error, uint32) {
+		return lc.esc.sc.AddToClientDataDefinition(defineID, uint32(index*4), uint32(datumType), 0, SIMCONNECT_UNUSED)
+	})
+	if err != nil {
+		return fmt.Errorf("Error in AddToClientDataDefinition : %#v", err)
+	}
+	cData, err := lc.esc.subscribeClientData(lc.ctx, clientDataID, defineID, SIMCONNECT_CLIENT_DATA_REQUEST_FLAG_CHANGED)
+	if err != nil {
+		return err
+	}
+	go func() {
+		for data := range cData {
+			if len(data) < 4 {
+				continue
+			}
+			lc.update(name, float64(math.Float32frombits(binary.LittleEndian.Uint32(data))))
+		}
+	}()
+	return nil
+}
+
+// update record the value of an L:var and send it to the listeners
+func (lc *LVarClient) update(name string, value float64) {
+	lc.mu.Lock()
+	if state, found := lc.lvars[name]; found {
+		state.value, state.received = value, true
+	}
+	listen := make([]chan LVar, 0, len(lc.listen))
+	for c := range lc.listen {
+		listen = append(listen, c)
+	}
+	lc.mu.Unlock()
+	for _, c := range listen {
+		select {
+		case c <- LVar{name, value}:
+		case <-time.After(lc.esc.updateDelay()):
+			lc.esc.logf(LogWarn, "L:var %s dropped, updates are not read", name)
+		}
+	}
+}
+
+// Get return the last value received of a registered L:var, false while no value was received
+func (lc *LVarClient) Get(name string) (float64, bool) {
+	lc.mu.Lock()
+	defer lc.mu.Unlock()
+	state, found := lc.lvars[lvarName(name)]
+	if !found || !state.received {
+		return 0, false
+	}
+	return state.value, true
+}
+
+// Set write the value of an L:var, it does not need to be registered
+func (lc *LVarClient) Set(name string, value float64) error {
+	return lc.ExecuteCalculatorCode(strconv.FormatFloat(value, 'f', -1, 64) + " (>L:" + lvarName(name) + ")")
+}
+
+// Updates return a chan receiving the registered L:vars each time they change. Nothing is sent once ctx is done
+func (lc *LVarClient) Updates(ctx context.Context) <-chan LVar {
+	c := make(chan LVar, 16)
+	lc.mu.Lock()
+	lc.listen[c] = struct{}{}
+	lc.mu.Unlock()
+	go func() {
+		select {
+		case <-ctx.Done():
+		case <-lc.ctx.Done():
+		}
+		lc.mu.Lock()
+		delete(lc.listen, c)
+		lc.mu.Unlock()
+	}()
+	return c
+}
@@ -0,0 +1,128 @@
+package simconnect
+
+import (
+	"errors"
+	"fmt"
+)
+
+// MenuItem is an entry of the Add-ons menu of the simulator or a sub item of one. The menu items are removed
+// from the simulator by EasySimConnect.Close
+type MenuItem struct {
+	Text    string
+	esc     *EasySimConnect
+	eventID uint32
+	parent  *MenuItem
+	onClick func()
+	// subItems are guarded by esc.requestMu
+	subItems []*MenuItem
+}
+
+// AddMenuItem add an item to the Add-ons menu of the simulator. onClick runs in its own goroutine each time the
+// pilot selects the item, it can be nil for an item holding sub items.
+// MSFS marks SimConnect_MenuAddItem and SimConnect_MenuAddSubItem as deprecated and does not show the items,
+// they are for FSX and Prepar3D
+func (esc *EasySimConnect) AddMenuItem(text string, onClick func()) (*MenuItem, error) {
+	item := esc.newMenuItem(text, nil, onClick)
+	if err, _ := esc.sc.MenuAddItem(text, item.eventID, 0); err != nil {
+		esc.forgetMenuItem(item)
+		return nil, fmt.Errorf("Error in MenuAddItem : %#v", err)
+	}
+	return item, nil
+}
+
+// AddSubItem add a sub item to a menu item. onClick runs in its own goroutine each time the pilot selects it
+func (m *MenuItem) AddSubItem(text string, onClick func()) (*MenuItem, error) {
+	if m.parent != nil {
+		return nil, errors.New("a menu sub item has no sub item")
+	}
+	item := m.esc.newMenuItem(text, m, onClick)
+	if err, _ := m.esc.sc.MenuAddSubItem(m.eventID, text, item.eventID, 0); err != nil {
+		m.esc.forgetMenuItem(item)
+		return nil, fmt.Errorf("Error in MenuAddSubItem : %#v", err)
+	}
+	return item, nil
+}
+
+// Delete remove the menu item and its sub items from the simulator, deleting it again does nothing
+func (m *MenuItem) Delete() error {
+	if !m.esc.forgetMenuItem(m) {
+		return nil
+	}
+	if m.parent == nil {
+		if err, _ := m.esc.sc.MenuDeleteItem(m.eventID); err != nil {
+			return fmt.Errorf("Error in MenuDeleteItem : %#v", err)
+		}
+		return nil
+	}
+	if err, _ := m.esc.sc.MenuDeleteSubItem(m.parent.eventID, m.eventID); err != nil {
+		return fmt.Errorf("Error in MenuDeleteSubItem : %#v", err)
+	}
+	return nil
+}
+
+// newMenuItem register a menu item, its client event ID is taken from the request IDs so it does not collide
+// with the other client events
+func (esc *EasySimConnect) newMenuItem(text string, parent *MenuItem, onClick func()) *MenuItem {
+	item := &MenuItem{Text: text, esc: esc, eventID: esc.nextRequestID(), parent: parent, onClick: onClick}
+	esc.requestMu.Lock()
+	defer esc.requestMu.Unlock()
+	esc.menuItems[item.eventID] = item
+	if parent != nil {
+		parent.subItems = append(parent.subItems, item)
+	}
+	return item
+}
+
+// forgetMenuItem unregister a menu item and its sub items, false when it was not registered
+func (esc *EasySimConnect) forgetMenuItem(item *MenuItem) bool {
+	esc.requestMu.Lock()
+	defer esc.requestMu.Unlock()
+	if _, found := esc.menuItems[item.eventID]; !found {
+		return false
+	}
+	delete(esc.menuItems, item.eventID)
+	for _, sub := range item.subItems {
+		delete(esc.menuItems, sub.eventID)
+	}
+	item.subItems = nil
+	if item.parent != nil {
+		for i, sub := range item.parent.subItems {
+			if sub == item {
+				item.parent.subItems = append(item.parent.subItems[:i], item.parent.subItems[i+1:]...)
+				break
+			}
+		}
+	}
+	return true
+}
+
+// menuSelected run the callback of the menu item of a SIMCONNECT_RECV_EVENT, false when eventID is not a menu item
+func (esc *EasySimConnect) menuSelected(eventID uint32) bool {
+	esc.requestMu.Lock()
+	item, found := esc.menuItems[eventID]
+	esc.requestMu.Unlock()
+	if !found {
+		return false
+	}
+	if item.onClick != nil {
+		go item.onClick()
+	}
+	return true
+}
+
+// deleteMenuItems remove every menu item from the simulator
+func (esc *EasySimConnect) deleteMenuItems() {
+	esc.requestMu.Lock()
+	items := make([]*MenuItem, 0)
+	for _, item := range esc.menuItems {
+		if item.parent == nil {
+			items = append(items, item)
+		}
+	}
+	esc.requestMu.Unlock()
+	for _, item := range items {
+		if err := item.Delete(); err != nil {
+			esc.logf(LogWarn, "Menu item %s not deleted : %v", item.Text, err)
+		}
+	}
+}
@@ -0,0 +1,637 @@
+package simconnect
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net"
+	"sync"
+	"time"
+)
+
+// SimConnect wire protocol, the one used by remote clients configured with SimConnect.cfg (Protocol=IPv4)
+const (
+	netProtocolVersion = 5 // KittyHawk (FS2020)
+	netHeaderSize      = 16
+	netPacketMask      = 0xF0000000
+	netMaxPacketSize   = 1 << 24
+	netQueueSize       = 256
+)
+
+// Client packet types of the SimConnect wire protocol
+const (
+	netPacketOpen                                      = 0x01
+	netPacketMapClientEventToSimEvent                  = 0x04
+	netPacketTransmitClientEvent                       = 0x05
+	netPacketSetSystemEventState                       = 0x06
+	netPacketAddClientEventToNotificationGroup         = 0x07
+	netPacketRemoveClientEvent                         = 0x08
+	netPacketSetNotificationGroupPriority              = 0x09
+	netPacketClearNotificationGroup                    = 0x0A
+	netPacketRequestNotificationGroup                  = 0x0B
+	netPacketAddToDataDefinition                       = 0x0C
+	netPacketClearDataDefinition                       = 0x0D
+	netPacketRequestDataOnSimObject                    = 0x0E
+	netPacketRequestDataOnSimObjectType                = 0x0F
+	netPacketSetDataOnSimObject                        = 0x10
+	netPacketMapInputEventToClientEvent                = 0x11
+	netPacketSetInputGroupPriority                     = 0x12
+	netPacketRemoveInputEvent                          = 0x13
+	netPacketClearInputGroup                           = 0x14
+	netPacketSetInputGroupState                        = 0x15
+	netPacketRequestReservedKey                        = 0x16
+	netPacketSubscribeToSystemEvent                    = 0x17
+	netPacketUnsubscribeFromSystemEvent                = 0x18
+	netPacketWeatherRequestInterpolatedObservation     = 0x19
+	netPacketWeatherRequestObservationAtStation        = 0x1A
+	netPacketWeatherRequestObservationAtNearestStation = 0x1B
+	netPacketAICreateParkedATCAircraft                 = 0x27
+	netPacketAICreateEnrouteATCAircraft                = 0x28
+	netPacketAICreateNonATCAircraft                    = 0x29
+	netPacketAICreateSimulatedObject                   = 0x2A
+	netPacketAIReleaseControl                          = 0x2B
+	netPacketAIRemoveObject                            = 0x2C
+	netPacketAISetAircraftFlightPlan                   = 0x2D
+	netPacketMenuAddItem                               = 0x31
+	netPacketMenuDeleteItem                            = 0x32
+	netPacketMenuAddSubItem                            = 0x33
+	netPacketMenuDeleteSubItem                         = 0x34
+	netPacketMapClientDataNameToID                     = 0x37
+	netPacketCreateClientData                          = 0x38
+	netPacketAddToClientDataDefinition                 = 0x39
+	netPacketClearClientDataDefinition                 = 0x3A
+	netPacketRequestClientData                         = 0x3B
+	netPacketSetClientData                             = 0x3C
+	netPacketText                                      = 0x40
+	netPacketSubscribeToFacilities                     = 0x41
+	netPacketUnsubscribeToFacilities                   = 0x42
+	netPacketRequestFacilitiesList                     = 0x43
+	// the packets of MSFS follow the order of the functions in its SimConnect.h
+	netPacketAddToFacilityDefinition   = 0x45
+	netPacketRequestFacilityData       = 0x46
+	netPacketEnumerateInputEvents      = 0x4F
+	netPacketGetInputEvent             = 0x50
+	netPacketSetInputEvent             = 0x51
+	netPacketSubscribeInputEvent       = 0x52
+	netPacketUnsubscribeInputEvent     = 0x53
+	netPacketEnumerateInputEventParams = 0x54
+)
+
+var errNetNoDispatch = errors.New("no message in dispatch queue")
+
+var (
+	_ Transport            = (*NetSC)(nil)
+	_ WeatherTransport     = (*NetSC)(nil)
+	_ AITransport          = (*NetSC)(nil)
+	_ FacilitiesTransport  = (*NetSC)(nil)
+	_ ClientDataTransport  = (*NetSC)(nil)
+	_ InputEventsTransport = (*NetSC)(nil)
+	_ MenuTransport        = (*NetSC)(nil)
+)
+
+// NetSC is a pure Go client of the SimConnect TCP protocol.
+// It is the Transport used in place of SimConnect.dll when the simulator runs on another host.
+type NetSC struct {
+	address  string
+	timeout  time.Duration
+	conn     net.Conn
+	mu       sync.Mutex
+	packetID uint32
+	queue    chan []byte
+	done     chan struct{}
+	once     sync.Once
+}
+
+// NewNetSC create a SimConnect network client for address (host:port). The connection is established on Open.
+func NewNetSC(address string) *NetSC {
+	return &NetSC{
+		address: address,
+		timeout: 5 * time.Second,
+		queue:   make(chan []byte, netQueueSize),
+		done:    make(chan struct{}),
+	}
+}
+
+// netPacket build the body of a client packet
+type netPacket struct {
+	buf []byte
+}
+
+func (p *netPacket) putUint32(v uint32) {
+	p.buf = binary.LittleEndian.AppendUint32(p.buf, v)
+}
+
+func (p *netPacket) putInt32(v int32) {
+	p.putUint32(uint32(v))
+}
+
+func (p *netPacket) putBool(b bool) {
+	if b {
+		p.putUint32(1)
+	} else {
+		p.putUint32(0)
+	}
+}
+
+func (p *netPacket) putFloat32(v float32) {
+	p.putUint32(math.Float32bits(v))
+}
+
+func (p *netPacket) putUint64(v uint64) {
+	p.buf = binary.LittleEndian.AppendUint64(p.buf, v)
+}
+
+func (p *netPacket) putFloat64(v float64) {
+	p.buf = binary.LittleEndian.AppendUint64(p.buf, math.Float64bits(v))
+}
+
+// putString write a fixed size zero terminated string
+func (p *netPacket) putString(s string, size int) {
+	b := make([]byte, size)
+	copy(b[:size-1], s)
+	p.buf = append(p.buf, b...)
+}
+
+func (p *netPacket) putBytes(b []byte) {
+	p.buf = append(p.buf, b...)
+}
+
+func (p *netPacket) putInitPosition(pos SIMCONNECT_DATA_INITPOSITION) {
+	p.putFloat64(pos.Latitude)
+	p.putFloat64(pos.Longitude)
+	p.putFloat64(pos.Altitude)
+	p.putFloat64(pos.Pitch)
+	p.putFloat64(pos.Bank)
+	p.putFloat64(pos.Heading)
+	p.putUint32(pos.OnGround)
+	p.putUint32(pos.Airspeed)
+}
+
+// send write a client packet and return its packet ID, the dwSendID of the exceptions it causes. The ID is
+// assigned under the write lock so a concurrent send can not change it
+func (n *NetSC) send(packetType uint32, p *netPacket) (uint32, error) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if n.conn == nil {
+		return 0, errors.New("Not connected")
+	}
+	n.packetID++
+	buf := make([]byte, netHeaderSize, netHeaderSize+len(p.buf))
+	binary.LittleEndian.PutUint32(buf[0:], uint32(netHeaderSize+len(p.buf)))
+	binary.LittleEndian.PutUint32(buf[4:], netProtocolVersion)
+	binary.LittleEndian.PutUint32(buf[8:], netPacketMask|packetType)
+	binary.LittleEndian.PutUint32(buf[12:], n.packetID)
+	buf = append(buf, p.buf...)
+	_, err := n.conn.Write(buf)
+	return n.packetID, err
+}
+
+func (n *NetSC) readLoop(conn net.Conn) {
+	defer n.push(netQuitPacket())
+	header := make([]byte, 4)
+	for {
+		if _, err := io.ReadFull(conn, header); err != nil {
+			return
+		}
+		size := binary.LittleEndian.Uint32(header)
+		if size < 12 || size > netMaxPacketSize {
+			return
+		}
+		buf := make([]byte, size)
+		copy(buf, header)
+		if _, err := io.ReadFull(conn, buf[4:]); err != nil {
+			return
+		}
+		if !n.push(buf) {
+			return
+		}
+	}
+}
+
+func (n *NetSC) push(buf []byte) bool {
+	select {
+	case n.queue <- buf:
+		return true
+	case <-n.done:
+		return false
+	}
+}
+
+// netQuitPacket is delivered when the server goes away so the dispatcher stops like with the DLL
+func netQuitPacket() []byte {
+	buf := make([]byte, 12)
+	binary.LittleEndian.PutUint32(buf[0:], 12)
+	binary.LittleEndian.PutUint32(buf[4:], netProtocolVersion)
+	binary.LittleEndian.PutUint32(buf[8:], SIMCONNECT_RECV_ID_QUIT)
+	return buf
+}
+
+// Open dial the server and send the Open packet. The answer is received by GetNextDispatch
+func (n *NetSC) Open(appTitle string) error {
+	conn, err := net.DialTimeout("tcp", n.address, n.timeout)
+	if err != nil {
+		return fmt.Errorf("dial SimConnect server %s: %w", n.address, err)
+	}
+	n.mu.Lock()
+	n.conn = conn
+	n.mu.Unlock()
+	go n.readLoop(conn)
+
+	p := &netPacket{}
+	p.putString(appTitle, 256)
+	p.putUint32(0)
+	p.putBytes([]byte{0, 'X', 'S', 'C'})
+	p.putUint32(11)    // version major
+	p.putUint32(0)     // version minor
+	p.putUint32(62651) // build major
+	p.putUint32(3)     // build minor
+	_, err = n.send(netPacketOpen, p)
+	return err
+}
+
+// Close close the connection to the server
+func (n *NetSC) Close() error {
+	n.once.Do(func() { close(n.done) })
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if n.conn == nil {
+		return nil
+	}
+	err := n.conn.Close()
+	n.conn = nil
+	return err
+}
+
+// GetNextDispatch return the next received message or an error when the queue is empty
+func (n *NetSC) GetNextDispatch() ([]byte, error) {
+	select {
+	case buf := <-n.queue:
+		return buf, nil
+	default:
+		return nil, errNetNoDispatch
+	}
+}
+
+func (n *NetSC) MapClientEventToSimEvent(EventID uint32, EventName string) (uint32, error) {
+	p := &netPacket{}
+	p.putUint32(EventID)
+	p.putString(EventName, 256)
+	return n.send(netPacketMapClientEventToSimEvent, p)
+}
+
+func (n *NetSC) TransmitClientEvent(ObjectID uint32, EventID uint32, dwData uint32, GroupID uint32, Flags uint32) (uint32, error) {
+	p := &netPacket{}
+	p.putUint32(ObjectID)
+	p.putUint32(EventID)
+	p.putUint32(dwData)
+	p.putUint32(GroupID)
+	p.putUint32(Flags)
+	return n.send(netPacketTransmitClientEvent, p)
+}
+
+func (n *NetSC) AddClientEventToNotificationGroup(GroupID uint32, EventID uint32, bMaskable bool) (uint32, error) {
+	p := &netPacket{}
+	p.putUint32(GroupID)
+	p.putUint32(EventID)
+	p.putBool(bMaskable)
+	return n.send(netPacketAddClientEventToNotificationGroup, p)
+}
+
+func (n *NetSC) SetNotificationGroupPriority(GroupID uint32, uPriority uint32) (uint32, error) {
+	p := &netPacket{}
+	p.putUint32(GroupID)
+	p.putUint32(uPriority)
+	return n.send(netPacketSetNotificationGroupPriority, p)
+}
+
+func (n *NetSC) AddToDataDefinition(DefineID uint32, DatumName string, UnitsName string, DatumType uint32, fEpsilon float32, DatumID uint32) (uint32, error) {
+	p := &netPacket{}
+	p.putUint32(DefineID)
+	p.putString(DatumName, 256)
+	p.putString(UnitsName, 256)
+	p.putUint32(DatumType)
+	p.putFloat32(fEpsilon)
+	p.putUint32(DatumID)
+	return n.send(netPacketAddToDataDefinition, p)
+}
+
+func (n *NetSC) ClearDataDefinition(DefineID uint32) (uint32, error) {
+	p := &netPacket{}
+	p.putUint32(DefineID)
+	return n.send(netPacketClearDataDefinition, p)
+}
+
+func (n *NetSC) RequestDataOnSimObject(RequestID uint32, DefineID uint32, ObjectID uint32, Period uint32, Flags uint32, origin uint32, interval uint32, limit uint32) (uint32, error) {
+	p := &netPacket{}
+	p.putUint32(RequestID)
+	p.putUint32(DefineID)
+	p.putUint32(ObjectID)
+	p.putUint32(Period)
+	p.putUint32(Flags)
+	p.putUint32(origin)
+	p.putUint32(interval)
+	p.putUint32(limit)
+	return n.send(netPacketRequestDataOnSimObject, p)
+}
+
+func (n *NetSC) RequestDataOnSimObjectType(RequestID uint32, DefineID uint32, dwRadiusMeters uint32, t uint32) (uint32, error) {
+	p := &netPacket{}
+	p.putUint32(RequestID)
+	p.putUint32(DefineID)
+	p.putUint32(dwRadiusMeters)
+	p.putUint32(t)
+	return n.send(netPacketRequestDataOnSimObjectType, p)
+}
+
+func (n *NetSC) SetDataOnSimObject(DefineID uint32, ObjectID uint32, Flags uint32, ArrayCount uint32, cbUnitSize uint32, pDataSet []byte) (uint32, error) {
+	p := &netPacket{}
+	p.putUint32(DefineID)
+	p.putUint32(ObjectID)
+	p.putUint32(Flags)
+	p.putUint32(ArrayCount)
+	p.putUint32(cbUnitSize)
+	p.putBytes(pDataSet)
+	return n.send(netPacketSetDataOnSimObject, p)
+}
+
+func (n *NetSC) MapInputEventToClientEvent(GroupID uint32, szInputDefinition string, DownEventID uint32, DownValue uint32, UpEventID uint32, UpValue uint32, bMaskable bool) (uint32, error) {
+	p := &netPacket{}
+	p.putUint32(GroupID)
+	p.putString(szInputDefinition, 256)
+	p.putUint32(DownEventID)
+	p.putUint32(DownValue)
+	p.putUint32(UpEventID)
+	p.putUint32(UpValue)
+	p.putBool(bMaskable)
+	return n.send(netPacketMapInputEventToClientEvent, p)
+}
+
+func (n *NetSC) SetInputGroupState(GroupID uint32, dwState uint32) (uint32, error) {
+	p := &netPacket{}
+	p.putUint32(GroupID)
+	p.putUint32(dwState)
+	return n.send(netPacketSetInputGroupState, p)
+}
+
+func (n *NetSC) SubscribeToSystemEvent(EventID uint32, SystemEventName string) (uint32, error) {
+	p := &netPacket{}
+	p.putUint32(EventID)
+	p.putString(SystemEventName, 256)
+	return n.send(netPacketSubscribeToSystemEvent, p)
+}
+
+func (n *NetSC) MenuAddItem(szMenuItem string, MenuEventID uint32, dwData uint32) (uint32, error) {
+	p := &netPacket{}
+	p.putString(szMenuItem, 256)
+	p.putUint32(MenuEventID)
+	p.putUint32(dwData)
+	return n.send(netPacketMenuAddItem, p)
+}
+
+func (n *NetSC) MenuDeleteItem(MenuEventID uint32) (uint32, error) {
+	p := &netPacket{}
+	p.putUint32(MenuEventID)
+	return n.send(netPacketMenuDeleteItem, p)
+}
+
+func (n *NetSC) MenuAddSubItem(MenuEventID uint32, szMenuItem string, SubMenuEventID uint32, dwData uint32) (uint32, error) {
+	p := &netPacket{}
+	p.putUint32(MenuEventID)
+	p.putString(szMenuItem, 256)
+	p.putUint32(SubMenuEventID)
+	p.putUint32(dwData)
+	return n.send(netPacketMenuAddSubItem, p)
+}
+
+func (n *NetSC) MenuDeleteSubItem(MenuEventID uint32, SubMenuEventID uint32) (uint32, error) {
+	p := &netPacket{}
+	p.putUint32(MenuEventID)
+	p.putUint32(SubMenuEventID)
+	return n.send(netPacketMenuDeleteSubItem, p)
+}
+
+func (n *NetSC) Text(t uint32, fTimeSeconds float32, EventID uint32, pDataSet []byte) (uint32, error) {
+	p := &netPacket{}
+	p.putUint32(t)
+	p.putFloat32(fTimeSeconds)
+	p.putUint32(EventID)
+	p.putUint32(uint32(len(pDataSet)))
+	p.putBytes(pDataSet)
+	return n.send(netPacketText, p)
+}
+
+func (n *NetSC) WeatherRequestInterpolatedObservation(RequestID uint32, lat float32, lon float32, alt float32) (uint32, error) {
+	p := &netPacket{}
+	p.putUint32(RequestID)
+	p.putFloat32(lat)
+	p.putFloat32(lon)
+	p.putFloat32(alt)
+	return n.send(netPacketWeatherRequestInterpolatedObservation, p)
+}
+
+func (n *NetSC) WeatherRequestObservationAtStation(RequestID uint32, szICAO string) (uint32, error) {
+	p := &netPacket{}
+	p.putUint32(RequestID)
+	p.putString(szICAO, 5)
+	return n.send(netPacketWeatherRequestObservationAtStation, p)
+}
+
+func (n *NetSC) WeatherRequestObservationAtNearestStation(RequestID uint32, lat float32, lon float32) (uint32, error) {
+	p := &netPacket{}
+	p.putUint32(RequestID)
+	p.putFloat32(lat)
+	p.putFloat32(lon)
+	return n.send(netPacketWeatherRequestObservationAtNearestStation, p)
+}
+
+func (n *NetSC) AICreateParkedATCAircraft(szContainerTitle string, szTailNumber string, szAirportID string, RequestID uint32) (uint32, error) {
+	p := &netPacket{}
+	p.putString(szContainerTitle, 256)
+	p.putString(szTailNumber, 12)
+	p.putString(szAirportID, 5)
+	p.putUint32(RequestID)
+	return n.send(netPacketAICreateParkedATCAircraft, p)
+}
+
+func (n *NetSC) AICreateEnrouteATCAircraft(szContainerTitle string, szTailNumber string, iFlightNumber int32, szFlightPlanPath string, dFlightPlanPosition float64, bTouchAndGo bool, RequestID uint32) (uint32, error) {
+	p := &netPacket{}
+	p.putString(szContainerTitle, 256)
+	p.putString(szTailNumber, 12)
+	p.putInt32(iFlightNumber)
+	p.putString(szFlightPlanPath, 260)
+	p.putFloat64(dFlightPlanPosition)
+	p.putBool(bTouchAndGo)
+	p.putUint32(RequestID)
+	return n.send(netPacketAICreateEnrouteATCAircraft, p)
+}
+
+func (n *NetSC) AICreateNonATCAircraft(szContainerTitle string, szTailNumber string, InitPos SIMCONNECT_DATA_INITPOSITION, RequestID uint32) (uint32, error) {
+	p := &netPacket{}
+	p.putString(szContainerTitle, 256)
+	p.putString(szTailNumber, 12)
+	p.putInitPosition(InitPos)
+	p.putUint32(RequestID)
+	return n.send(netPacketAICreateNonATCAircraft, p)
+}
+
+func (n *NetSC) AICreateSimulatedObject(szContainerTitle string, InitPos SIMCONNECT_DATA_INITPOSITION, RequestID uint32) (uint32, error) {
+	p := &netPacket{}
+	p.putString(szContainerTitle, 256)
+	p.putInitPosition(InitPos)
+	p.putUint32(RequestID)
+	return n.send(netPacketAICreateSimulatedObject, p)
+}
+
+func (n *NetSC) AIReleaseControl(ObjectID uint32, RequestID uint32) (uint32, error) {
+	p := &netPacket{}
+	p.putUint32(ObjectID)
+	p.putUint32(RequestID)
+	return n.send(netPacketAIReleaseControl, p)
+}
+
+func (n *NetSC) AIRemoveObject(ObjectID uint32, RequestID uint32) (uint32, error) {
+	p := &netPacket{}
+	p.putUint32(ObjectID)
+	p.putUint32(RequestID)
+	return n.send(netPacketAIRemoveObject, p)
+}
+
+func (n *NetSC) AISetAircraftFlightPlan(ObjectID uint32, szFlightPlanPath string, RequestID uint32) (uint32, error) {
+	p := &netPacket{}
+	p.putUint32(ObjectID)
+	p.putString(szFlightPlanPath, 260)
+	p.putUint32(RequestID)
+	return n.send(netPacketAISetAircraftFlightPlan, p)
+}
+
+func (n *NetSC) SubscribeToFacilities(t uint32, RequestID uint32) (uint32, error) {
+	p := &netPacket{}
+	p.putUint32(t)
+	p.putUint32(RequestID)
+	return n.send(netPacketSubscribeToFacilities, p)
+}
+
+func (n *NetSC) UnsubscribeToFacilities(t uint32) (uint32, error) {
+	p := &netPacket{}
+	p.putUint32(t)
+	return n.send(netPacketUnsubscribeToFacilities, p)
+}
+
+func (n *NetSC) RequestFacilitiesList(t uint32, RequestID uint32) (uint32, error) {
+	p := &netPacket{}
+	p.putUint32(t)
+	p.putUint32(RequestID)
+	return n.send(netPacketRequestFacilitiesList, p)
+}
+
+func (n *NetSC) AddToFacilityDefinition(DefineID uint32, FieldName string) (uint32, error) {
+	p := &netPacket{}
+	p.putUint32(DefineID)
+	p.putString(FieldName, 256)
+	return n.send(netPacketAddToFacilityDefinition, p)
+}
+
+func (n *NetSC) RequestFacilityData(DefineID uint32, RequestID uint32, ICAO string, Region string) (uint32, error) {
+	p := &netPacket{}
+	p.putUint32(DefineID)
+	p.putUint32(RequestID)
+	p.putString(ICAO, 16)
+	p.putString(Region, 16)
+	return n.send(netPacketRequestFacilityData, p)
+}
+
+func (n *NetSC) EnumerateInputEvents(RequestID uint32) (uint32, error) {
+	p := &netPacket{}
+	p.putUint32(RequestID)
+	return n.send(netPacketEnumerateInputEvents, p)
+}
+
+func (n *NetSC) GetInputEvent(RequestID uint32, Hash uint64) (uint32, error) {
+	p := &netPacket{}
+	p.putUint32(RequestID)
+	p.putUint64(Hash)
+	return n.send(netPacketGetInputEvent, p)
+}
+
+func (n *NetSC) SetInputEvent(Hash uint64, cbUnitSize uint32, Value []byte) (uint32, error) {
+	p := &netPacket{}
+	p.putUint64(Hash)
+	p.putUint32(cbUnitSize)
+	p.putBytes(Value)
+	return n.send(netPacketSetInputEvent, p)
+}
+
+func (n *NetSC) SubscribeInputEvent(Hash uint64) (uint32, error) {
+	p := &netPacket{}
+	p.putUint64(Hash)
+	return n.send(netPacketSubscribeInputEvent, p)
+}
+
+func (n *NetSC) UnsubscribeInputEvent(Hash uint64) (uint32, error) {
+	p := &netPacket{}
+	p.putUint64(Hash)
+	return n.send(netPacketUnsubscribeInputEvent, p)
+}
+
+func (n *NetSC) EnumerateInputEventParams(Hash uint64) (uint32, error) {
+	p := &netPacket{}
+	p.putUint64(Hash)
+	return n.send(netPacketEnumerateInputEventParams, p)
+}
+
+func (n *NetSC) MapClientDataNameToID(szClientDataName string, ClientDataID uint32) (uint32, error) {
+	p := &netPacket{}
+	p.putString(szClientDataName, 256)
+	p.putUint32(ClientDataID)
+	return n.send(netPacketMapClientDataNameToID, p)
+}
+
+func (n *NetSC) CreateClientData(ClientDataID uint32, dwSize uint32, Flags uint32) (uint32, error) {
+	p := &netPacket{}
+	p.putUint32(ClientDataID)
+	p.putUint32(dwSize)
+	p.putUint32(Flags)
+	return n.send(netPacketCreateClientData, p)
+}
+
+func (n *NetSC) AddToClientDataDefinition(DefineID uint32, dwOffset uint32, dwSizeOrType uint32, fEpsilon float32, DatumID uint32) (uint32, error) {
+	p := &netPacket{}
+	p.putUint32(DefineID)
+	p.putUint32(dwOffset)
+	p.putUint32(dwSizeOrType)
+	p.putFloat32(fEpsilon)
+	p.putUint32(DatumID)
+	return n.send(netPacketAddToClientDataDefinition, p)
+}
+
+func (n *NetSC) ClearClientDataDefinition(DefineID uint32) (uint32, error) {
+	p := &netPacket{}
+	p.putUint32(DefineID)
+	return n.send(netPacketClearClientDataDefinition, p)
+}
+
+func (n *NetSC) RequestClientData(ClientDataID uint32, RequestID uint32, DefineID uint32, Period uint32, Flags uint32, origin uint32, interval uint32, limit uint32) (uint32, error) {
+	p := &netPacket{}
+	p.putUint32(ClientDataID)
+	p.putUint32(RequestID)
+	p.putUint32(DefineID)
+	p.putUint32(Period)
+	p.putUint32(Flags)
+	p.putUint32(origin)
+	p.putUint32(interval)
+	p.putUint32(limit)
+	return n.send(netPacketRequestClientData, p)
+}
+
+func (n *NetSC) SetClientData(ClientDataID uint32, DefineID uint32, Flags uint32, dwReserved uint32, cbUnitSize uint32, pDataSet []byte) (uint32, error) {
+	p := &netPacket{}
+	p.putUint32(ClientDataID)
+	p.putUint32(DefineID)
+	p.putUint32(Flags)
+	p.putUint32(dwReserved)
+	p.putUint32(cbUnitSize)
+	p.putBytes(pDataSet)
+	return n.send(netPacketSetClientData, p)
+}
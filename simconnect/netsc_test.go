@@ -0,0 +1,134 @@
+package simconnect
+
+import (
+	"encoding/binary"
+	"io"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func nextDispatch(t *testing.T, n *NetSC) []byte {
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		buf, err := n.GetNextDispatch()
+		if err == nil {
+			return buf
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatal("no message received")
+	return nil
+}
+
+func TestNetSCOpen(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer l.Close()
+
+	received := make(chan []byte, 1)
+	go func() {
+		conn, err := l.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		header := make([]byte, netHeaderSize)
+		if _, err := io.ReadFull(conn, header); err != nil {
+			return
+		}
+		packet := make([]byte, binary.LittleEndian.Uint32(header))
+		copy(packet, header)
+		if _, err := io.ReadFull(conn, packet[netHeaderSize:]); err != nil {
+			return
+		}
+		received <- packet
+
+		open := make([]byte, 12+256+10*4)
+		binary.LittleEndian.PutUint32(open[0:], uint32(len(open)))
+		binary.LittleEndian.PutUint32(open[4:], netProtocolVersion)
+		binary.LittleEndian.PutUint32(open[8:], SIMCONNECT_RECV_ID_OPEN)
+		copy(open[12:], "KittyHawk")
+		conn.Write(open)
+	}()
+
+	n := NewNetSC(l.Addr().String())
+	require.NoError(t, n.Open("simgo"))
+	defer n.Close()
+
+	packet := <-received
+	assert.Equal(t, uint32(netPacketMask|netPacketOpen), binary.LittleEndian.Uint32(packet[8:]))
+	assert.Equal(t, uint32(1), binary.LittleEndian.Uint32(packet[12:]))
+	assert.Equal(t, "simgo", convStrToGoString(packet[netHeaderSize:netHeaderSize+256]))
+
+	buf := nextDispatch(t, n)
+	assert.Equal(t, uint32(SIMCONNECT_RECV_ID_OPEN), binary.LittleEndian.Uint32(buf[8:]))
+	assert.Equal(t, "KittyHawk", convStrToGoString(buf[12:12+256]))
+
+	// server closed the connection
+	buf = nextDispatch(t, n)
+	assert.Equal(t, uint32(SIMCONNECT_RECV_ID_QUIT), binary.LittleEndian.Uint32(buf[8:]))
+}
+
+func TestNetSCSendPacketID(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer l.Close()
+
+	const calls = 50
+	// packet ID of each ClearDataDefinition by its define ID, as read by the server
+	received := make(chan map[uint32]uint32, 1)
+	go func() {
+		conn, err := l.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		ids := make(map[uint32]uint32)
+		for len(ids) < calls {
+			header := make([]byte, netHeaderSize)
+			if _, err := io.ReadFull(conn, header); err != nil {
+				return
+			}
+			body := make([]byte, binary.LittleEndian.Uint32(header)-netHeaderSize)
+			if _, err := io.ReadFull(conn, body); err != nil {
+				return
+			}
+			if binary.LittleEndian.Uint32(header[8:]) == netPacketMask|netPacketClearDataDefinition {
+				ids[binary.LittleEndian.Uint32(body)] = binary.LittleEndian.Uint32(header[12:])
+			}
+		}
+		received <- ids
+	}()
+
+	n := NewNetSC(l.Addr().String())
+	require.NoError(t, n.Open("simgo"))
+	defer n.Close()
+
+	sent := make(map[uint32]uint32)
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for i := uint32(0); i < calls; i++ {
+		wg.Add(1)
+		go func(defineID uint32) {
+			defer wg.Done()
+			id, err := n.ClearDataDefinition(defineID)
+			assert.NoError(t, err)
+			mu.Lock()
+			sent[defineID] = id
+			mu.Unlock()
+		}(i)
+	}
+	wg.Wait()
+
+	select {
+	case ids := <-received:
+		assert.Equal(t, ids, sent)
+	case <-time.After(2 * time.Second):
+		t.Fatal("packets not received")
+	}
+}
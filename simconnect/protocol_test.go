@@ -0,0 +1,248 @@
+package simconnect_test
+
+import (
+	"context"
+	"encoding/binary"
+	"io"
+	"math"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/flysim-apps/simgo/simconnect"
+	"github.com/flysim-apps/simgo/simconnect/simtest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// The fixtures below are laid out by hand from the structures of SimConnect.h (packed, little endian) and the
+// header of the client packets, independently of the constants of netsc.go and simtest/protocol.go. Both sides
+// of the protocol are checked against them.
+
+func dwords(v ...uint32) []byte {
+	b := make([]byte, 0, 4*len(v))
+	for _, i := range v {
+		b = binary.LittleEndian.AppendUint32(b, i)
+	}
+	return b
+}
+
+func doubles(v ...float64) []byte {
+	b := make([]byte, 0, 8*len(v))
+	for _, f := range v {
+		b = binary.LittleEndian.AppendUint64(b, math.Float64bits(f))
+	}
+	return b
+}
+
+// chars is a zero terminated char[size]
+func chars(s string, size int) []byte {
+	b := make([]byte, size)
+	copy(b, s)
+	return b
+}
+
+func join(parts ...[]byte) []byte {
+	b := make([]byte, 0)
+	for _, p := range parts {
+		b = append(b, p...)
+	}
+	return b
+}
+
+// clientPacket is a client packet: dwSize, dwVersion (5 for MSFS), 0xF0000000 | packet type, packet ID, body
+func clientPacket(packetType, packetID uint32, body []byte) []byte {
+	return join(dwords(uint32(16+len(body)), 5, 0xF0000000|packetType, packetID), body)
+}
+
+// recv is a SIMCONNECT_RECV: dwSize, dwVersion, dwID followed by the fields of the message
+func recv(id uint32, body []byte) []byte {
+	return join(dwords(uint32(12+len(body)), 5, id), body)
+}
+
+// fixtureOpen is the Open packet (0x01): szApplicationName[256], dwReserved, the 4 bytes of the alias and
+// the SimConnect version 11.0.62651.3
+func fixtureOpen(name string) []byte {
+	return clientPacket(0x01, 1, join(chars(name, 256), dwords(0), []byte{0, 'X', 'S', 'C'}, dwords(11, 0, 62651, 3)))
+}
+
+// fixtureAddToDataDefinition is the AddToDataDefinition packet (0x0C): DefineID, DatumName[256],
+// UnitsName[256], DatumType, fEpsilon, DatumID
+func fixtureAddToDataDefinition(packetID, defineID uint32, name, unit string) []byte {
+	return clientPacket(0x0C, packetID, join(dwords(defineID), chars(name, 256), chars(unit, 256), dwords(4, 0, 0)))
+}
+
+// fixtureRequestDataOnSimObject is the RequestDataOnSimObject packet (0x0E): RequestID, DefineID, ObjectID,
+// Period, Flags, origin, interval, limit. Period 1 is SIMCONNECT_PERIOD_ONCE
+func fixtureRequestDataOnSimObject(packetID, requestID, defineID uint32) []byte {
+	return clientPacket(0x0E, packetID, dwords(requestID, defineID, 0, 1, 0, 0, 0, 0))
+}
+
+// fixtureRequestFacilitiesList is the RequestFacilitiesList packet (0x43): type, RequestID
+func fixtureRequestFacilitiesList(packetID, listType, requestID uint32) []byte {
+	return clientPacket(0x43, packetID, dwords(listType, requestID))
+}
+
+// fixtureRecvOpen is SIMCONNECT_RECV_OPEN (SIMCONNECT_RECV_ID_OPEN 2): szApplicationName[256], the application
+// and SimConnect versions and 2 reserved DWORDs
+func fixtureRecvOpen(name string) []byte {
+	return recv(2, join(chars(name, 256), dwords(11, 0, 62651, 3, 11, 0, 62651, 3, 0, 0)))
+}
+
+// fixtureRecvSimObjectData is SIMCONNECT_RECV_SIMOBJECT_DATA (SIMCONNECT_RECV_ID_SIMOBJECT_DATA 8): dwRequestID,
+// dwObjectID, dwDefineID, dwFlags, dwentrynumber, dwoutof, dwDefineCount then the FLOAT64 datum
+func fixtureRecvSimObjectData(requestID, defineID uint32, value float64) []byte {
+	return recv(8, join(dwords(requestID, 1, defineID, 0, 1, 1, 1), doubles(value)))
+}
+
+// fixtureRecvException is SIMCONNECT_RECV_EXCEPTION (SIMCONNECT_RECV_ID_EXCEPTION 1): dwException, dwSendID,
+// dwIndex. SIMCONNECT_EXCEPTION_NAME_UNRECOGNIZED is 7
+func fixtureRecvException(sendID uint32) []byte {
+	return recv(1, dwords(7, sendID, 2))
+}
+
+// fixtureRecvAirportList is SIMCONNECT_RECV_AIRPORT_LIST (SIMCONNECT_RECV_ID_AIRPORT_LIST 18), the
+// SIMCONNECT_RECV_FACILITIES_LIST template: dwRequestID, dwArraySize, dwEntryNumber, dwOutOf then the
+// SIMCONNECT_DATA_FACILITY_AIRPORT array: ident[6], region[3], Latitude, Longitude, Altitude
+func fixtureRecvAirportList(requestID uint32) []byte {
+	return recv(18, join(dwords(requestID, 1, 0, 1), chars("LFPG", 6), chars("LF", 3), doubles(49.0097, 2.5479, 119)))
+}
+
+// readPacket read a packet starting with its dwSize
+func readPacket(t *testing.T, conn net.Conn) []byte {
+	t.Helper()
+	conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	size := make([]byte, 4)
+	_, err := io.ReadFull(conn, size)
+	require.NoError(t, err)
+	packet := make([]byte, binary.LittleEndian.Uint32(size))
+	copy(packet, size)
+	_, err = io.ReadFull(conn, packet[4:])
+	require.NoError(t, err)
+	return packet
+}
+
+func TestProtocolFixturesEmulator(t *testing.T) {
+	srv, err := simtest.NewServer()
+	require.NoError(t, err)
+	defer srv.Close()
+	srv.Strict = true
+	srv.Aircraft.Set("PLANE ALTITUDE", 1200)
+	srv.Aircraft.Set("PLANE LATITUDE", 49.0)
+	srv.Aircraft.Set("PLANE LONGITUDE", 2.55)
+	srv.AddFacility(simconnect.FacilityAirport{Ident: "LFPG", Region: "LF", Latitude: 49.0097, Longitude: 2.5479, Altitude: 119})
+
+	conn, err := net.Dial("tcp", srv.Addr())
+	require.NoError(t, err)
+	defer conn.Close()
+
+	_, err = conn.Write(fixtureOpen("simgo-test"))
+	require.NoError(t, err)
+	assert.Equal(t, fixtureRecvOpen(srv.Name), readPacket(t, conn))
+
+	_, err = conn.Write(fixtureAddToDataDefinition(2, 3, "PLANE ALTITUDE", "feet"))
+	require.NoError(t, err)
+	_, err = conn.Write(fixtureRequestDataOnSimObject(3, 4, 3))
+	require.NoError(t, err)
+	assert.Equal(t, fixtureRecvSimObjectData(4, 3, 1200), readPacket(t, conn))
+
+	_, err = conn.Write(fixtureAddToDataDefinition(4, 5, "NOT A SIMVAR", "feet"))
+	require.NoError(t, err)
+	assert.Equal(t, fixtureRecvException(4), readPacket(t, conn))
+
+	_, err = conn.Write(fixtureRequestFacilitiesList(5, 0, 6))
+	require.NoError(t, err)
+	assert.Equal(t, fixtureRecvAirportList(6), readPacket(t, conn))
+}
+
+func TestProtocolFixturesNetSC(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer l.Close()
+
+	// the server answers the packets of the client with the fixtures, taking the IDs chosen by the client
+	// from the packets which match the fixture of their type
+	unexpected := make(chan []byte, 16)
+	go func() {
+		conn, err := l.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		for {
+			size := make([]byte, 4)
+			if _, err := io.ReadFull(conn, size); err != nil {
+				return
+			}
+			packet := make([]byte, binary.LittleEndian.Uint32(size))
+			copy(packet, size)
+			if _, err := io.ReadFull(conn, packet[4:]); err != nil {
+				return
+			}
+			packetID := binary.LittleEndian.Uint32(packet[12:])
+			field := func(i int) uint32 { return binary.LittleEndian.Uint32(packet[16+4*i:]) }
+			var reply, want []byte
+			switch binary.LittleEndian.Uint32(packet[8:]) &^ 0xF0000000 {
+			case 0x01:
+				want, reply = fixtureOpen("simgo-test"), fixtureRecvOpen("KittyHawk")
+			case 0x0C:
+				if string(packet[20:32]) == "NOT A SIMVAR" {
+					reply = fixtureRecvException(packetID)
+				}
+			case 0x0E:
+				want = fixtureRequestDataOnSimObject(packetID, field(0), field(1))
+				if field(3) != 1 {
+					// the periodic requests of ConnectToSimVar
+					want = packet
+				}
+				reply = fixtureRecvSimObjectData(field(0), field(1), 1200)
+			case 0x43:
+				want, reply = fixtureRequestFacilitiesList(packetID, 0, field(1)), fixtureRecvAirportList(field(1))
+			}
+			if want != nil && string(want) != string(packet) {
+				unexpected <- packet
+			}
+			if reply != nil {
+				conn.Write(reply)
+			}
+		}
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	esc := simconnect.NewEasySimConnectRemote(ctx, l.Addr().String())
+	c, err := esc.Connect("simgo-test")
+	require.NoError(t, err)
+	select {
+	case open := <-c:
+		require.True(t, open)
+	case <-ctx.Done():
+		t.Fatal("connection not confirmed")
+	}
+
+	cSimVar, err := esc.ConnectToSimVar(simconnect.SimVarPlaneAltitude())
+	require.NoError(t, err)
+	select {
+	case vars := <-cSimVar:
+		altitude, err := vars[0].GetFloat64()
+		require.NoError(t, err)
+		assert.Equal(t, 1200.0, altitude)
+	case <-ctx.Done():
+		t.Fatal("no data received")
+	}
+
+	_, err = esc.ConnectToSimVar(simconnect.SimVar{Name: "NOT A SIMVAR", Unit: "feet"})
+	assert.ErrorContains(t, err, "SIMCONNECT_EXCEPTION_NAME_UNRECOGNIZED")
+
+	airports, err := esc.RequestAirports(ctx)
+	require.NoError(t, err)
+	require.Len(t, airports, 1)
+	assert.Equal(t, simconnect.FacilityAirport{Ident: "LFPG", Region: "LF", Latitude: 49.0097, Longitude: 2.5479, Altitude: 119}, airports[0])
+	assert.True(t, esc.IsAlive())
+
+	select {
+	case packet := <-unexpected:
+		t.Fatalf("packet not matching its fixture: % x", packet)
+	default:
+	}
+}
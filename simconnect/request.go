@@ -0,0 +1,161 @@
+package simconnect
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// firstRequestID is the first request ID of the calls answered by request ID, the data requests use the IDs
+// of their definition
+const firstRequestID = 1 << 20
+
+// exceptionDelay is how long a call without answer waits for the exception telling it failed
+const exceptionDelay = 100 * time.Millisecond
+
+// exceptionWindow is how long a late exception of a call without answer is still expected after exceptionDelay,
+// it is logged instead of closing the connection
+const exceptionWindow = 10 * time.Second
+
+// errClosed is the error of the pending requests when the connection is closed by Close
+var errClosed = errors.New("connection closed")
+
+// reply deliver the answer of a request sent with request
+func (esc *EasySimConnect) reply(requestID uint32, value interface{}) {
+	esc.requestMu.Lock()
+	c, found := esc.listRequest[requestID]
+	delete(esc.listRequest, requestID)
+	esc.requestMu.Unlock()
+	if !found {
+		esc.logf(LogInfo, "Ignored reply %#v of request %d", value, requestID)
+		return
+	}
+	c <- value
+}
+
+// send make a call whose exception is routed to the returned chan instead of closing the connection, until
+// forget is called with its packet ID. The call is made under the lock so its exception can not be dispatched
+// before its packet ID is known
+func (esc *EasySimConnect) send(call func() (error, uint32)) (uint32, <-chan *SIMCONNECT_RECV_EXCEPTION, error) {
+	c := make(chan *SIMCONNECT_RECV_EXCEPTION, 1)
+	esc.sendMu.Lock()
+	defer esc.sendMu.Unlock()
+	err, sendID := call()
+	if err != nil {
+		return 0, nil, err
+	}
+	esc.listSend[sendID] = c
+	return sendID, c, nil
+}
+
+// forget stop routing the exceptions of the call sendID
+func (esc *EasySimConnect) forget(sendID uint32) {
+	esc.sendMu.Lock()
+	delete(esc.listSend, sendID)
+	esc.sendMu.Unlock()
+}
+
+// exception route an exception to the call which caused it, false when no call expects it
+func (esc *EasySimConnect) exception(recv *SIMCONNECT_RECV_EXCEPTION) bool {
+	esc.sendMu.Lock()
+	c, found := esc.listSend[recv.dwSendID]
+	esc.sendMu.Unlock()
+	if found {
+		select {
+		case c <- recv:
+		default:
+		}
+	}
+	return found
+}
+
+// check make a call without answer and return the exception it causes within exceptionDelay, the error is the
+// one of the transport or of the end of the connection
+func (esc *EasySimConnect) check(call func() (error, uint32)) (*SIMCONNECT_RECV_EXCEPTION, error) {
+	sendID, exceptions, err := esc.send(call)
+	if err != nil {
+		return nil, err
+	}
+	select {
+	case exception := <-exceptions:
+		esc.forget(sendID)
+		return exception, nil
+	case <-esc.done:
+		esc.forget(sendID)
+		return nil, esc.closeError()
+	case <-time.After(exceptionDelay):
+		time.AfterFunc(exceptionWindow, func() { esc.forget(sendID) })
+		return nil, nil
+	}
+}
+
+// expect make a call without answer and without waiting its exception, which is logged instead of closing the
+// connection if it comes within exceptionWindow
+func (esc *EasySimConnect) expect(call func() (error, uint32)) error {
+	sendID, _, err := esc.send(call)
+	if err != nil {
+		return err
+	}
+	time.AfterFunc(exceptionWindow, func() { esc.forget(sendID) })
+	return nil
+}
+
+// request send a call answered by request ID and wait its answer, its exception, the end of the connection or
+// the end of ctx
+func (esc *EasySimConnect) request(ctx context.Context, name string, call func(requestID uint32) (error, uint32)) (interface{}, error) {
+	c := make(chan interface{}, 1)
+	esc.requestMu.Lock()
+	esc.indexRequest++
+	requestID := esc.indexRequest
+	esc.listRequest[requestID] = c
+	esc.requestMu.Unlock()
+	defer func() {
+		esc.requestMu.Lock()
+		delete(esc.listRequest, requestID)
+		esc.requestMu.Unlock()
+	}()
+
+	sendID, exceptions, err := esc.send(func() (error, uint32) { return call(requestID) })
+	if err != nil {
+		return nil, fmt.Errorf("Error in %s : %#v", name, err)
+	}
+	defer esc.forget(sendID)
+	select {
+	case value := <-c:
+		return value, nil
+	case exception := <-exceptions:
+		return nil, fmt.Errorf("Error in %s : %s", name, getTextException(exception.dwException))
+	case <-esc.done:
+		return nil, fmt.Errorf("Error in %s : %w", name, esc.closeError())
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// stop end the session: IsAlive return false and the pending calls fail with reason
+func (esc *EasySimConnect) stop(reason error) {
+	esc.alive.Store(false)
+	esc.doneOnce.Do(func() {
+		esc.sendMu.Lock()
+		esc.doneErr = reason
+		esc.sendMu.Unlock()
+		close(esc.done)
+	})
+	esc.sc.Close()
+}
+
+// closeError return the reason of stop
+func (esc *EasySimConnect) closeError() error {
+	esc.sendMu.Lock()
+	defer esc.sendMu.Unlock()
+	return esc.doneErr
+}
+
+// nextRequestID return a request ID for the calls without answer
+func (esc *EasySimConnect) nextRequestID() uint32 {
+	esc.requestMu.Lock()
+	defer esc.requestMu.Unlock()
+	esc.indexRequest++
+	return esc.indexRequest
+}
@@ -4,54 +4,50 @@ package simconnect
 
 import (
 	"errors"
+	"sync/atomic"
 	"unsafe"
 )
 
-// convert string to const char *
-func cChar(str string) uintptr {
-	b := []byte(str + "\x00")
-	return uintptr(unsafe.Pointer(&b[0]))
-}
-
-func cBool(b bool) uintptr {
-	mask := 0
-	if b {
-		mask = 1
-	}
-	return uintptr(mask)
-}
-
 // SimConnect golang interface
 type SimConnect struct {
-	hSimConnect uintptr
-	syscallSC   *SyscallSC
+	transport Transport
+	// lastSentPacketID is the packet ID of the last call, see GetLastSentPacketID
+	lastSentPacketID atomic.Uint32
 }
 
-// NewSimConnect get instance of SimConnect
+// NewSimConnect get instance of SimConnect using SimConnect.dll
 func NewSimConnect() (*SimConnect, error) {
-	var err error
-	simConnect := &SimConnect{}
-	simConnect.syscallSC, err = NewSyscallSC()
+	transport, err := NewDLLTransport()
 	if err != nil {
 		return nil, err
 	}
-	return simConnect, nil
+	return NewSimConnectWithTransport(transport), nil
+}
+
+// NewSimConnectRemote get instance of SimConnect talking the SimConnect network protocol to address (host:port) without SimConnect.dll
+func NewSimConnectRemote(address string) *SimConnect {
+	return NewSimConnectWithTransport(NewNetSC(address))
+}
+
+// NewSimConnectWithTransport get instance of SimConnect on top of any Transport
+func NewSimConnectWithTransport(transport Transport) *SimConnect {
+	return &SimConnect{transport: transport}
+}
+
+// result return the error and the packet ID of a call on the transport
+func (sc *SimConnect) result(packetID uint32, err error) (error, uint32) {
+	sc.lastSentPacketID.Store(packetID)
+	return err, packetID
 }
 
 // MapClientEventToSimEvent SimConnect_MapClientEventToSimEvent(HANDLE hSimConnect, SIMCONNECT_CLIENT_EVENT_ID EventID, const char * EventName = "")
 func (sc *SimConnect) MapClientEventToSimEvent(EventID uint32, EventName string) (error, uint32) {
-	err := sc.syscallSC.MapClientEventToSimEvent(sc.hSimConnect, uintptr(EventID), cChar(EventName))
-	id := new(uint32)
-	sc.GetLastSentPacketID(id)
-	return err, *id
+	return sc.result(sc.transport.MapClientEventToSimEvent(EventID, EventName))
 }
 
 // TransmitClientEvent SimConnect_TransmitClientEvent(HANDLE hSimConnect, SIMCONNECT_OBJECT_ID ObjectID, SIMCONNECT_CLIENT_EVENT_ID EventID, DWORD dwData, SIMCONNECT_NOTIFICATION_GROUP_ID GroupID, SIMCONNECT_EVENT_FLAG Flags);
 func (sc *SimConnect) TransmitClientEvent(ObjectID uint32, EventID uint32, dwData int, GroupID GroupPriority, Flags EventFlag) (error, uint32) {
-	err := sc.syscallSC.TransmitClientEvent(sc.hSimConnect, uintptr(ObjectID), uintptr(EventID), uintptr(dwData), uintptr(GroupID), uintptr(Flags))
-	id := new(uint32)
-	sc.GetLastSentPacketID(id)
-	return err, *id
+	return sc.result(sc.transport.TransmitClientEvent(ObjectID, EventID, uint32(dwData), uint32(GroupID), uint32(Flags)))
 }
 
 // SetSystemEventState SimConnect_SetSystemEventState(HANDLE hSimConnect, SIMCONNECT_CLIENT_EVENT_ID EventID, SIMCONNECT_STATE dwState);
@@ -61,10 +57,7 @@ func (sc *SimConnect) SetSystemEventState(EventID uint32, dwState uint32) (error
 
 // AddClientEventToNotificationGroup SimConnect_AddClientEventToNotificationGroup(HANDLE hSimConnect, SIMCONNECT_NOTIFICATION_GROUP_ID GroupID, SIMCONNECT_CLIENT_EVENT_ID EventID, BOOL bMaskable = FALSE);
 func (sc *SimConnect) AddClientEventToNotificationGroup(GroupID uint32, EventID uint32, bMaskable bool) (error, uint32) {
-	err := sc.syscallSC.AddClientEventToNotificationGroup(sc.hSimConnect, uintptr(GroupID), uintptr(EventID), cBool(bMaskable))
-	id := new(uint32)
-	sc.GetLastSentPacketID(id)
-	return err, *id
+	return sc.result(sc.transport.AddClientEventToNotificationGroup(GroupID, EventID, bMaskable))
 }
 
 // RemoveClientEvent SimConnect_RemoveClientEvent(HANDLE hSimConnect, SIMCONNECT_NOTIFICATION_GROUP_ID GroupID, SIMCONNECT_CLIENT_EVENT_ID EventID);
@@ -74,7 +67,7 @@ func (sc *SimConnect) RemoveClientEvent(GroupID uint32, EventID uint32) (error,
 
 // SetNotificationGroupPriority SimConnect_SetNotificationGroupPriority(HANDLE hSimConnect, SIMCONNECT_NOTIFICATION_GROUP_ID GroupID, DWORD uPriority);
 func (sc *SimConnect) SetNotificationGroupPriority(GroupID uint32, uPriority GroupPriority) (error, uint32) {
-	return errors.New("not implemented"), 0
+	return sc.result(sc.transport.SetNotificationGroupPriority(GroupID, uint32(uPriority)))
 }
 
 // ClearNotificationGroup SimConnect_ClearNotificationGroup(HANDLE hSimConnect, SIMCONNECT_NOTIFICATION_GROUP_ID GroupID);
@@ -89,52 +82,92 @@ func (sc *SimConnect) RequestNotificationGroup(GroupID uint32, dwReserved uint32
 
 // AddToDataDefinition SimConnect_AddToDataDefinition(HANDLE hSimConnect, SIMCONNECT_DATA_DEFINITION_ID DefineID, const char * DatumName, const char * UnitsName, SIMCONNECT_DATATYPE DatumType = SIMCONNECT_DATATYPE_FLOAT64, float fEpsilon = 0, DWORD DatumID = SIMCONNECT_UNUSED);
 func (sc *SimConnect) AddToDataDefinition(DefineID uint32, DatumName string, UnitsName string, DatumType uint32, fEpsilon float32, DatumID uint32) (error, uint32) {
-	err := sc.syscallSC.AddToDataDefinition(sc.hSimConnect, uintptr(DefineID), cChar(DatumName), cChar(UnitsName), uintptr(DatumType), uintptr(fEpsilon), uintptr(DatumID))
-	id := new(uint32)
-	sc.GetLastSentPacketID(id)
-	return err, *id
+	return sc.result(sc.transport.AddToDataDefinition(DefineID, DatumName, UnitsName, DatumType, fEpsilon, DatumID))
 }
 
 // ClearDataDefinition SimConnect_ClearDataDefinition(HANDLE hSimConnect, SIMCONNECT_DATA_DEFINITION_ID DefineID);
 func (sc *SimConnect) ClearDataDefinition(DefineID uint32) (error, uint32) {
-	err := sc.syscallSC.ClearDataDefinition(sc.hSimConnect, uintptr(DefineID))
-	id := new(uint32)
-	sc.GetLastSentPacketID(id)
-	return err, *id
+	return sc.result(sc.transport.ClearDataDefinition(DefineID))
 }
 
 // RequestDataOnSimObject SimConnect_RequestDataOnSimObject(HANDLE hSimConnect, SIMCONNECT_DATA_REQUEST_ID RequestID, SIMCONNECT_DATA_DEFINITION_ID DefineID, SIMCONNECT_OBJECT_ID ObjectID, SIMCONNECT_PERIOD Period, SIMCONNECT_DATA_REQUEST_FLAG Flags = 0, DWORD origin = 0, DWORD interval = 0, DWORD limit = 0);
 func (sc *SimConnect) RequestDataOnSimObject(RequestID uint32, DefineID uint32, ObjectID uint32, Period uint32, Flags uint32, origin uint32, interval uint32, limit uint32) (error, uint32) {
-	return errors.New("not implemented"), 0
+	return sc.result(sc.transport.RequestDataOnSimObject(RequestID, DefineID, ObjectID, Period, Flags, origin, interval, limit))
 }
 
 // RequestDataOnSimObjectType SimConnect_RequestDataOnSimObjectType(HANDLE hSimConnect, SIMCONNECT_DATA_REQUEST_ID RequestID, SIMCONNECT_DATA_DEFINITION_ID DefineID, DWORD dwRadiusMeters, SIMCONNECT_SIMOBJECT_TYPE type);
 func (sc *SimConnect) RequestDataOnSimObjectType(RequestID uint32, DefineID uint32, dwRadiusMeters uint32, t uint32) (error, uint32) {
-	err := sc.syscallSC.RequestDataOnSimObjectType(sc.hSimConnect, uintptr(RequestID), uintptr(DefineID), uintptr(dwRadiusMeters), uintptr(t))
-	id := new(uint32)
-	sc.GetLastSentPacketID(id)
-	return err, *id
+	return sc.result(sc.transport.RequestDataOnSimObjectType(RequestID, DefineID, dwRadiusMeters, t))
 }
 
 // SetDataOnSimObject SimConnect_SetDataOnSimObject(HANDLE hSimConnect, SIMCONNECT_DATA_DEFINITION_ID DefineID, SIMCONNECT_OBJECT_ID ObjectID, SIMCONNECT_DATA_SET_FLAG Flags, DWORD ArrayCount, DWORD cbUnitSize, void * pDataSet);
 func (sc *SimConnect) SetDataOnSimObject(DefineID uint32, ObjectID uint32, Flags uint32, ArrayCount uint32, cbUnitSize uint32, pDataSet []byte) (error, uint32) {
-	if len(pDataSet) < 0 {
+	if len(pDataSet) == 0 {
 		return errors.New("Your pDataSet is too short on SetDataOnSimObject"), 0
 	}
-	err := sc.syscallSC.SetDataOnSimObject(sc.hSimConnect, uintptr(DefineID), uintptr(ObjectID), uintptr(Flags), uintptr(ArrayCount), uintptr(cbUnitSize), uintptr(unsafe.Pointer(&pDataSet[0])))
-
-	id := new(uint32)
-	sc.GetLastSentPacketID(id)
-	return err, *id
+	return sc.result(sc.transport.SetDataOnSimObject(DefineID, ObjectID, Flags, ArrayCount, cbUnitSize, pDataSet))
 }
 
 // MapInputEventToClientEvent SimConnect_MapInputEventToClientEvent(HANDLE hSimConnect, SIMCONNECT_INPUT_GROUP_ID GroupID, const char * szInputDefinition, SIMCONNECT_CLIENT_EVENT_ID DownEventID, DWORD DownValue = 0, SIMCONNECT_CLIENT_EVENT_ID UpEventID = (SIMCONNECT_CLIENT_EVENT_ID)SIMCONNECT_UNUSED, DWORD UpValue = 0, BOOL bMaskable = FALSE);
 func (sc *SimConnect) MapInputEventToClientEvent(GroupID uint32, szInputDefinition string, DownEventID uint32, DownValue uint32, UpEventID uint32, UpValue uint32, bMaskable bool) (error, uint32) {
+	return sc.result(sc.transport.MapInputEventToClientEvent(GroupID, szInputDefinition, DownEventID, DownValue, UpEventID, UpValue, bMaskable))
+}
+
+// EnumerateInputEvents SimConnect_EnumerateInputEvents(HANDLE hSimConnect, SIMCONNECT_DATA_REQUEST_ID RequestID);
+func (sc *SimConnect) EnumerateInputEvents(RequestID uint32) (error, uint32) {
+	tr, err := feature[InputEventsTransport](sc.transport)
+	if err != nil {
+		return err, 0
+	}
+	return sc.result(tr.EnumerateInputEvents(RequestID))
+}
+
+// GetInputEvent SimConnect_GetInputEvent(HANDLE hSimConnect, SIMCONNECT_DATA_REQUEST_ID RequestID, UINT64 Hash);
+func (sc *SimConnect) GetInputEvent(RequestID uint32, Hash uint64) (error, uint32) {
+	tr, err := feature[InputEventsTransport](sc.transport)
+	if err != nil {
+		return err, 0
+	}
+	return sc.result(tr.GetInputEvent(RequestID, Hash))
+}
+
+// SetInputEvent SimConnect_SetInputEvent(HANDLE hSimConnect, UINT64 Hash, DWORD cbUnitSize, void * Value);
+func (sc *SimConnect) SetInputEvent(Hash uint64, cbUnitSize uint32, Value []byte) (error, uint32) {
+	if len(Value) == 0 {
+		return errors.New("Your Value is too short on SetInputEvent"), 0
+	}
+	tr, err := feature[InputEventsTransport](sc.transport)
+	if err != nil {
+		return err, 0
+	}
+	return sc.result(tr.SetInputEvent(Hash, cbUnitSize, Value))
+}
+
+// SubscribeInputEvent SimConnect_SubscribeInputEvent(HANDLE hSimConnect, UINT64 Hash);
+func (sc *SimConnect) SubscribeInputEvent(Hash uint64) (error, uint32) {
+	tr, err := feature[InputEventsTransport](sc.transport)
+	if err != nil {
+		return err, 0
+	}
+	return sc.result(tr.SubscribeInputEvent(Hash))
+}
+
+// UnsubscribeInputEvent SimConnect_UnsubscribeInputEvent(HANDLE hSimConnect, UINT64 Hash);
+func (sc *SimConnect) UnsubscribeInputEvent(Hash uint64) (error, uint32) {
+	tr, err := feature[InputEventsTransport](sc.transport)
+	if err != nil {
+		return err, 0
+	}
+	return sc.result(tr.UnsubscribeInputEvent(Hash))
+}
 
-	err := sc.syscallSC.MapInputEventToClientEvent(sc.hSimConnect, uintptr(GroupID), cChar(szInputDefinition), uintptr(DownEventID), uintptr(DownValue), uintptr(UpEventID), uintptr(UpValue), cBool(bMaskable))
-	id := new(uint32)
-	sc.GetLastSentPacketID(id)
-	return err, *id
+// EnumerateInputEventParams SimConnect_EnumerateInputEventParams(HANDLE hSimConnect, UINT64 Hash);
+func (sc *SimConnect) EnumerateInputEventParams(Hash uint64) (error, uint32) {
+	tr, err := feature[InputEventsTransport](sc.transport)
+	if err != nil {
+		return err, 0
+	}
+	return sc.result(tr.EnumerateInputEventParams(Hash))
 }
 
 // SetInputGroupPriority SimConnect_SetInputGroupPriority(HANDLE hSimConnect, SIMCONNECT_INPUT_GROUP_ID GroupID, DWORD uPriority);
@@ -154,10 +187,7 @@ func (sc *SimConnect) ClearInputGroup(GroupID uint32) (error, uint32) {
 
 // SetInputGroupState SimConnect_SetInputGroupState(HANDLE hSimConnect, SIMCONNECT_INPUT_GROUP_ID GroupID, DWORD dwState);
 func (sc *SimConnect) SetInputGroupState(GroupID uint32, dwState SimConnectStat) (error, uint32) {
-	err := sc.syscallSC.SetInputGroupState(sc.hSimConnect, uintptr(GroupID), uintptr(dwState))
-	id := new(uint32)
-	sc.GetLastSentPacketID(id)
-	return err, *id
+	return sc.result(sc.transport.SetInputGroupState(GroupID, uint32(dwState)))
 }
 
 // RequestReservedKey SimConnect_RequestReservedKey(HANDLE hSimConnect, SIMCONNECT_CLIENT_EVENT_ID EventID, const char * szKeyChoice1 = "", const char * szKeyChoice2 = "", const char * szKeyChoice3 = "");
@@ -167,10 +197,7 @@ func (sc *SimConnect) RequestReservedKey(EventID uint32, szKeyChoice1 string, sz
 
 // SubscribeToSystemEvent SimConnect_SubscribeToSystemEvent(HANDLE hSimConnect, SIMCONNECT_CLIENT_EVENT_ID EventID, const char * SystemEventName);
 func (sc *SimConnect) SubscribeToSystemEvent(EventID uint32, SystemEventName SystemEvent) (error, uint32) {
-	err := sc.syscallSC.SubscribeToSystemEvent(sc.hSimConnect, uintptr(EventID), cChar(string(SystemEventName)))
-	id := new(uint32)
-	sc.GetLastSentPacketID(id)
-	return err, *id
+	return sc.result(sc.transport.SubscribeToSystemEvent(EventID, string(SystemEventName)))
 }
 
 // UnsubscribeFromSystemEvent SimConnect_UnsubscribeFromSystemEvent(HANDLE hSimConnect, SIMCONNECT_CLIENT_EVENT_ID EventID);
@@ -180,17 +207,29 @@ func (sc *SimConnect) UnsubscribeFromSystemEvent(EventID uint32) (error, uint32)
 
 // WeatherRequestInterpolatedObservation SimConnect_WeatherRequestInterpolatedObservation(HANDLE hSimConnect, SIMCONNECT_DATA_REQUEST_ID RequestID, float lat, float lon, float alt);
 func (sc *SimConnect) WeatherRequestInterpolatedObservation(RequestID uint32, lat float32, lon float32, alt float32) (error, uint32) {
-	return errors.New("not implemented"), 0
+	tr, err := feature[WeatherTransport](sc.transport)
+	if err != nil {
+		return err, 0
+	}
+	return sc.result(tr.WeatherRequestInterpolatedObservation(RequestID, lat, lon, alt))
 }
 
 // WeatherRequestObservationAtStation SimConnect_WeatherRequestObservationAtStation(HANDLE hSimConnect, SIMCONNECT_DATA_REQUEST_ID RequestID, const char * szICAO);
 func (sc *SimConnect) WeatherRequestObservationAtStation(RequestID uint32, szICAO string) (error, uint32) {
-	return errors.New("not implemented"), 0
+	tr, err := feature[WeatherTransport](sc.transport)
+	if err != nil {
+		return err, 0
+	}
+	return sc.result(tr.WeatherRequestObservationAtStation(RequestID, szICAO))
 }
 
 // WeatherRequestObservationAtNearestStation SimConnect_WeatherRequestObservationAtNearestStation(HANDLE hSimConnect, SIMCONNECT_DATA_REQUEST_ID RequestID, float lat, float lon);
 func (sc *SimConnect) WeatherRequestObservationAtNearestStation(RequestID uint32, lat float32, lon float32) (error, uint32) {
-	return errors.New("not implemented"), 0
+	tr, err := feature[WeatherTransport](sc.transport)
+	if err != nil {
+		return err, 0
+	}
+	return sc.result(tr.WeatherRequestObservationAtNearestStation(RequestID, lat, lon))
 }
 
 // WeatherCreateStation SimConnect_WeatherCreateStation(HANDLE hSimConnect, SIMCONNECT_DATA_REQUEST_ID RequestID, const char * szICAO, const char * szName, float lat, float lon, float alt);
@@ -250,37 +289,65 @@ func (sc *SimConnect) WeatherRemoveThermal(ObjectID uint32) (error, uint32) {
 
 // AICreateParkedATCAircraft SimConnect_AICreateParkedATCAircraft(HANDLE hSimConnect, const char * szContainerTitle, const char * szTailNumber, const char * szAirportID, SIMCONNECT_DATA_REQUEST_ID RequestID);
 func (sc *SimConnect) AICreateParkedATCAircraft(szContainerTitle string, szTailNumber string, szAirportID string, RequestID uint32) (error, uint32) {
-	return errors.New("not implemented"), 0
+	tr, err := feature[AITransport](sc.transport)
+	if err != nil {
+		return err, 0
+	}
+	return sc.result(tr.AICreateParkedATCAircraft(szContainerTitle, szTailNumber, szAirportID, RequestID))
 }
 
 // AICreateEnrouteATCAircraft SimConnect_AICreateEnrouteATCAircraft(HANDLE hSimConnect, const char * szContainerTitle, const char * szTailNumber, int iFlightNumber, const char * szFlightPlanPath, double dFlightPlanPosition, BOOL bTouchAndGo, SIMCONNECT_DATA_REQUEST_ID RequestID);
-func (sc *SimConnect) AICreateEnrouteATCAircraft(szContainerTitle string, szTailNumber string, iFlightNumber int, szFlightPlanPath string, dFlightPlanPosition float64, bTouchAndGo uint32, RequestID uint32) (error, uint32) {
-	return errors.New("not implemented"), 0
+func (sc *SimConnect) AICreateEnrouteATCAircraft(szContainerTitle string, szTailNumber string, iFlightNumber int, szFlightPlanPath string, dFlightPlanPosition float64, bTouchAndGo bool, RequestID uint32) (error, uint32) {
+	tr, err := feature[AITransport](sc.transport)
+	if err != nil {
+		return err, 0
+	}
+	return sc.result(tr.AICreateEnrouteATCAircraft(szContainerTitle, szTailNumber, int32(iFlightNumber), szFlightPlanPath, dFlightPlanPosition, bTouchAndGo, RequestID))
 }
 
 // AICreateNonATCAircraft SimConnect_AICreateNonATCAircraft(HANDLE hSimConnect, const char * szContainerTitle, const char * szTailNumber, SIMCONNECT_DATA_INITPOSITION InitPos, SIMCONNECT_DATA_REQUEST_ID RequestID);
-func (sc *SimConnect) AICreateNonATCAircraft(szContainerTitle string, szTailNumber string, InitPos uint32, RequestID uint32) (error, uint32) {
-	return errors.New("not implemented"), 0
+func (sc *SimConnect) AICreateNonATCAircraft(szContainerTitle string, szTailNumber string, InitPos SIMCONNECT_DATA_INITPOSITION, RequestID uint32) (error, uint32) {
+	tr, err := feature[AITransport](sc.transport)
+	if err != nil {
+		return err, 0
+	}
+	return sc.result(tr.AICreateNonATCAircraft(szContainerTitle, szTailNumber, InitPos, RequestID))
 }
 
 // AICreateSimulatedObject SimConnect_AICreateSimulatedObject(HANDLE hSimConnect, const char * szContainerTitle, SIMCONNECT_DATA_INITPOSITION InitPos, SIMCONNECT_DATA_REQUEST_ID RequestID);
-func (sc *SimConnect) AICreateSimulatedObject(szContainerTitle string, InitPos uint32, RequestID uint32) (error, uint32) {
-	return errors.New("not implemented"), 0
+func (sc *SimConnect) AICreateSimulatedObject(szContainerTitle string, InitPos SIMCONNECT_DATA_INITPOSITION, RequestID uint32) (error, uint32) {
+	tr, err := feature[AITransport](sc.transport)
+	if err != nil {
+		return err, 0
+	}
+	return sc.result(tr.AICreateSimulatedObject(szContainerTitle, InitPos, RequestID))
 }
 
 // AIReleaseControl SimConnect_AIReleaseControl(HANDLE hSimConnect, SIMCONNECT_OBJECT_ID ObjectID, SIMCONNECT_DATA_REQUEST_ID RequestID);
 func (sc *SimConnect) AIReleaseControl(ObjectID uint32, RequestID uint32) (error, uint32) {
-	return errors.New("not implemented"), 0
+	tr, err := feature[AITransport](sc.transport)
+	if err != nil {
+		return err, 0
+	}
+	return sc.result(tr.AIReleaseControl(ObjectID, RequestID))
 }
 
 // AIRemoveObject SimConnect_AIRemoveObject(HANDLE hSimConnect, SIMCONNECT_OBJECT_ID ObjectID, SIMCONNECT_DATA_REQUEST_ID RequestID);
 func (sc *SimConnect) AIRemoveObject(ObjectID uint32, RequestID uint32) (error, uint32) {
-	return errors.New("not implemented"), 0
+	tr, err := feature[AITransport](sc.transport)
+	if err != nil {
+		return err, 0
+	}
+	return sc.result(tr.AIRemoveObject(ObjectID, RequestID))
 }
 
 // AISetAircraftFlightPlan SimConnect_AISetAircraftFlightPlan(HANDLE hSimConnect, SIMCONNECT_OBJECT_ID ObjectID, const char * szFlightPlanPath, SIMCONNECT_DATA_REQUEST_ID RequestID);
 func (sc *SimConnect) AISetAircraftFlightPlan(ObjectID uint32, szFlightPlanPath string, RequestID uint32) (error, uint32) {
-	return errors.New("not implemented"), 0
+	tr, err := feature[AITransport](sc.transport)
+	if err != nil {
+		return err, 0
+	}
+	return sc.result(tr.AISetAircraftFlightPlan(ObjectID, szFlightPlanPath, RequestID))
 }
 
 // ExecuteMissionAction SimConnect_ExecuteMissionAction(HANDLE hSimConnect, const GUID guidInstanceId);
@@ -295,10 +362,7 @@ func (sc *SimConnect) CompleteCustomMissionAction(guidInstanceID GUID) (error, u
 
 // Close SimConnect_Close(HANDLE hSimConnect);
 func (sc *SimConnect) Close() (error, uint32) {
-	err := sc.syscallSC.Close(sc.hSimConnect)
-	id := new(uint32)
-	sc.GetLastSentPacketID(id)
-	return err, *id
+	return sc.transport.Close(), 0
 }
 
 // RetrieveString SimConnect_RetrieveString(SIMCONNECT_RECV * pData, DWORD cbData, void * pStringV, char ** pszString, DWORD * pcbString);
@@ -307,21 +371,15 @@ func (sc *SimConnect) RetrieveString(pData *uint32, cbData uint32, pStringV stri
 }
 
 // GetLastSentPacketID SimConnect_GetLastSentPacketID(HANDLE hSimConnect, DWORD * pdwError);
+// Concurrent callers should use the packet ID returned by each call instead
 func (sc *SimConnect) GetLastSentPacketID(pdwError *uint32) error {
-	err := sc.syscallSC.GetLastSentPacketID(sc.hSimConnect, uintptr(unsafe.Pointer(pdwError)))
-
-	return err
+	*pdwError = sc.lastSentPacketID.Load()
+	return nil
 }
 
 // Open SimConnect_Open(HANDLE * phSimConnect, LPCSTR szName, HWND hWnd, DWORD UserEventWin32, HANDLE hEventHandle, DWORD ConfigIndex);
 func (sc *SimConnect) Open(appTitle string) (error, uint32) {
-	err := sc.syscallSC.Open(uintptr(unsafe.Pointer(&sc.hSimConnect)), cChar(appTitle), uintptr(unsafe.Pointer(nil)), 0, 0, 0)
-	if err != nil {
-		return errors.New("Not connected"), 0
-	}
-	id := new(uint32)
-	sc.GetLastSentPacketID(id)
-	return err, *id
+	return sc.transport.Open(appTitle), 0
 }
 
 // CallDispatch SimConnect_CallDispatch(HANDLE hSimConnect, DispatchProc pfcnDispatch, void * pContext);
@@ -330,10 +388,13 @@ func (sc *SimConnect) Open(appTitle string) (error, uint32) {
 
 // GetNextDispatch SimConnect_GetNextDispatch(HANDLE hSimConnect, SIMCONNECT_RECV ** ppData, DWORD * pcbData);
 func (sc *SimConnect) GetNextDispatch(ppData *unsafe.Pointer, pcbData *uint32) (error, uint32) {
-	err := sc.syscallSC.GetNextDispatch(sc.hSimConnect, uintptr(unsafe.Pointer(ppData)), uintptr(unsafe.Pointer(pcbData)))
-	id := new(uint32)
-	sc.GetLastSentPacketID(id)
-	return err, *id
+	buf, err := sc.transport.GetNextDispatch()
+	if err != nil {
+		return err, 0
+	}
+	*ppData = unsafe.Pointer(&buf[0])
+	*pcbData = uint32(len(buf))
+	return nil, 0
 }
 
 // RequestResponseTimes SimConnect_RequestResponseTimes(HANDLE hSimConnect, DWORD nCount, float * fElapsedSeconds);
@@ -353,22 +414,38 @@ func (sc *SimConnect) CameraSetRelative6DOF(fDeltaX float32, fDeltaY float32, fD
 
 // MenuAddItem SimConnect_MenuAddItem(HANDLE hSimConnect, const char * szMenuItem, SIMCONNECT_CLIENT_EVENT_ID MenuEventID, DWORD dwData);
 func (sc *SimConnect) MenuAddItem(szMenuItem string, MenuEventID uint32, dwData uint32) (error, uint32) {
-	return errors.New("not implemented"), 0
+	tr, err := feature[MenuTransport](sc.transport)
+	if err != nil {
+		return err, 0
+	}
+	return sc.result(tr.MenuAddItem(szMenuItem, MenuEventID, dwData))
 }
 
 // MenuDeleteItem SimConnect_MenuDeleteItem(HANDLE hSimConnect, SIMCONNECT_CLIENT_EVENT_ID MenuEventID);
 func (sc *SimConnect) MenuDeleteItem(MenuEventID uint32) (error, uint32) {
-	return errors.New("not implemented"), 0
+	tr, err := feature[MenuTransport](sc.transport)
+	if err != nil {
+		return err, 0
+	}
+	return sc.result(tr.MenuDeleteItem(MenuEventID))
 }
 
 // MenuAddSubItem SimConnect_MenuAddSubItem(HANDLE hSimConnect, SIMCONNECT_CLIENT_EVENT_ID MenuEventID, const char * szMenuItem, SIMCONNECT_CLIENT_EVENT_ID SubMenuEventID, DWORD dwData);
 func (sc *SimConnect) MenuAddSubItem(MenuEventID uint32, szMenuItem string, SubMenuEventID uint32, dwData uint32) (error, uint32) {
-	return errors.New("not implemented"), 0
+	tr, err := feature[MenuTransport](sc.transport)
+	if err != nil {
+		return err, 0
+	}
+	return sc.result(tr.MenuAddSubItem(MenuEventID, szMenuItem, SubMenuEventID, dwData))
 }
 
 // MenuDeleteSubItem SimConnect_MenuDeleteSubItem(HANDLE hSimConnect, SIMCONNECT_CLIENT_EVENT_ID MenuEventID, const SIMCONNECT_CLIENT_EVENT_ID SubMenuEventID);
-func (sc *SimConnect) MenuDeleteSubItem(MenuEventID uint32, constSubMenuEventID uint32) (error, uint32) {
-	return errors.New("not implemented"), 0
+func (sc *SimConnect) MenuDeleteSubItem(MenuEventID uint32, SubMenuEventID uint32) (error, uint32) {
+	tr, err := feature[MenuTransport](sc.transport)
+	if err != nil {
+		return err, 0
+	}
+	return sc.result(tr.MenuDeleteSubItem(MenuEventID, SubMenuEventID))
 }
 
 // RequestSystemState SimConnect_RequestSystemState(HANDLE hSimConnect, SIMCONNECT_DATA_REQUEST_ID RequestID, const char * szState);
@@ -383,32 +460,56 @@ func (sc *SimConnect) SetSystemState(szState string, dwInteger uint32, fFloat fl
 
 // MapClientDataNameToID SimConnect_MapClientDataNameToID(HANDLE hSimConnect, const char * szClientDataName, SIMCONNECT_CLIENT_DATA_ID ClientDataID);
 func (sc *SimConnect) MapClientDataNameToID(szClientDataName string, ClientDataID uint32) (error, uint32) {
-	return errors.New("not implemented"), 0
+	tr, err := feature[ClientDataTransport](sc.transport)
+	if err != nil {
+		return err, 0
+	}
+	return sc.result(tr.MapClientDataNameToID(szClientDataName, ClientDataID))
 }
 
 // CreateClientData SimConnect_CreateClientData(HANDLE hSimConnect, SIMCONNECT_CLIENT_DATA_ID ClientDataID, DWORD dwSize, SIMCONNECT_CREATE_CLIENT_DATA_FLAG Flags);
 func (sc *SimConnect) CreateClientData(ClientDataID uint32, dwSize uint32, Flags uint32) (error, uint32) {
-	return errors.New("not implemented"), 0
+	tr, err := feature[ClientDataTransport](sc.transport)
+	if err != nil {
+		return err, 0
+	}
+	return sc.result(tr.CreateClientData(ClientDataID, dwSize, Flags))
 }
 
 // AddToClientDataDefinition SimConnect_AddToClientDataDefinition(HANDLE hSimConnect, SIMCONNECT_CLIENT_DATA_DEFINITION_ID DefineID, DWORD dwOffset, DWORD dwSizeOrType, float fEpsilon = 0, DWORD DatumID = SIMCONNECT_UNUSED);
 func (sc *SimConnect) AddToClientDataDefinition(DefineID uint32, dwOffset uint32, dwSizeOrType uint32, fEpsilon float32, DatumID uint32) (error, uint32) {
-	return errors.New("not implemented"), 0
+	tr, err := feature[ClientDataTransport](sc.transport)
+	if err != nil {
+		return err, 0
+	}
+	return sc.result(tr.AddToClientDataDefinition(DefineID, dwOffset, dwSizeOrType, fEpsilon, DatumID))
 }
 
 // ClearClientDataDefinition SimConnect_ClearClientDataDefinition(HANDLE hSimConnect, SIMCONNECT_CLIENT_DATA_DEFINITION_ID DefineID);
 func (sc *SimConnect) ClearClientDataDefinition(DefineID uint32) (error, uint32) {
-	return errors.New("not implemented"), 0
+	tr, err := feature[ClientDataTransport](sc.transport)
+	if err != nil {
+		return err, 0
+	}
+	return sc.result(tr.ClearClientDataDefinition(DefineID))
 }
 
 // RequestClientData SimConnect_RequestClientData(HANDLE hSimConnect, SIMCONNECT_CLIENT_DATA_ID ClientDataID, SIMCONNECT_DATA_REQUEST_ID RequestID, SIMCONNECT_CLIENT_DATA_DEFINITION_ID DefineID, SIMCONNECT_CLIENT_DATA_PERIOD Period = SIMCONNECT_CLIENT_DATA_PERIOD_ONCE, SIMCONNECT_CLIENT_DATA_REQUEST_FLAG Flags = 0, DWORD origin = 0, DWORD interval = 0, DWORD limit = 0);
 func (sc *SimConnect) RequestClientData(ClientDataID uint32, RequestID uint32, DefineID uint32, Period uint32, Flags uint32, origin uint32, interval uint32, limit uint32) (error, uint32) {
-	return errors.New("not implemented"), 0
+	tr, err := feature[ClientDataTransport](sc.transport)
+	if err != nil {
+		return err, 0
+	}
+	return sc.result(tr.RequestClientData(ClientDataID, RequestID, DefineID, Period, Flags, origin, interval, limit))
 }
 
 // SetClientData SimConnect_SetClientData(HANDLE hSimConnect, SIMCONNECT_CLIENT_DATA_ID ClientDataID, SIMCONNECT_CLIENT_DATA_DEFINITION_ID DefineID, SIMCONNECT_CLIENT_DATA_SET_FLAG Flags, DWORD dwReserved, DWORD cbUnitSize, void * pDataSet);
-func (sc *SimConnect) SetClientData(ClientDataID uint32, DefineID uint32, Flags uint32, dwReserved uint32, cbUnitSize uint32, pDataSet *uint32) (error, uint32) {
-	return errors.New("not implemented"), 0
+func (sc *SimConnect) SetClientData(ClientDataID uint32, DefineID uint32, Flags uint32, dwReserved uint32, cbUnitSize uint32, pDataSet []byte) (error, uint32) {
+	tr, err := feature[ClientDataTransport](sc.transport)
+	if err != nil {
+		return err, 0
+	}
+	return sc.result(tr.SetClientData(ClientDataID, DefineID, Flags, dwReserved, cbUnitSize, pDataSet))
 }
 
 // FlightLoad SimConnect_FlightLoad(HANDLE hSimConnect, const char * szFileName);
@@ -428,25 +529,50 @@ func (sc *SimConnect) FlightPlanLoad(szFileName string) (error, uint32) {
 
 // Text SimConnect_Text(HANDLE hSimConnect, SIMCONNECT_TEXT_TYPE type, float fTimeSeconds, SIMCONNECT_CLIENT_EVENT_ID EventID, DWORD cbUnitSize, void * pDataSet);
 func (sc *SimConnect) Text(t uint32, fTimeSeconds float32, EventID uint32, pDataSet string) (error, uint32) {
-	str := convGoStringtoBytes(pDataSet)
-	size := len(str)
-	err := sc.syscallSC.Text(sc.hSimConnect, uintptr(t), uintptr(fTimeSeconds), uintptr(EventID), uintptr(size), uintptr(unsafe.Pointer(&str[0])))
-	id := new(uint32)
-	sc.GetLastSentPacketID(id)
-	return err, *id
+	return sc.result(sc.transport.Text(t, fTimeSeconds, EventID, convGoStringtoBytes(pDataSet)))
 }
 
 // SubscribeToFacilities SimConnect_SubscribeToFacilities(HANDLE hSimConnect, SIMCONNECT_FACILITY_LIST_TYPE type, SIMCONNECT_DATA_REQUEST_ID RequestID);
 func (sc *SimConnect) SubscribeToFacilities(t uint32, RequestID uint32) (error, uint32) {
-	return errors.New("not implemented"), 0
+	tr, err := feature[FacilitiesTransport](sc.transport)
+	if err != nil {
+		return err, 0
+	}
+	return sc.result(tr.SubscribeToFacilities(t, RequestID))
 }
 
 // UnsubscribeToFacilities SimConnect_UnsubscribeToFacilities(HANDLE hSimConnect, SIMCONNECT_FACILITY_LIST_TYPE type);
 func (sc *SimConnect) UnsubscribeToFacilities(t uint32) (error, uint32) {
-	return errors.New("not implemented"), 0
+	tr, err := feature[FacilitiesTransport](sc.transport)
+	if err != nil {
+		return err, 0
+	}
+	return sc.result(tr.UnsubscribeToFacilities(t))
 }
 
 // RequestFacilitiesList SimConnect_RequestFacilitiesList(HANDLE hSimConnect, SIMCONNECT_FACILITY_LIST_TYPE type, SIMCONNECT_DATA_REQUEST_ID RequestID);
 func (sc *SimConnect) RequestFacilitiesList(t uint32, RequestID uint32) (error, uint32) {
-	return errors.New("not implemented"), 0
+	tr, err := feature[FacilitiesTransport](sc.transport)
+	if err != nil {
+		return err, 0
+	}
+	return sc.result(tr.RequestFacilitiesList(t, RequestID))
+}
+
+// AddToFacilityDefinition SimConnect_AddToFacilityDefinition(HANDLE hSimConnect, SIMCONNECT_DATA_DEFINITION_ID DefineID, const char * FieldName);
+func (sc *SimConnect) AddToFacilityDefinition(DefineID uint32, FieldName string) (error, uint32) {
+	tr, err := feature[FacilitiesTransport](sc.transport)
+	if err != nil {
+		return err, 0
+	}
+	return sc.result(tr.AddToFacilityDefinition(DefineID, FieldName))
+}
+
+// RequestFacilityData SimConnect_RequestFacilityData(HANDLE hSimConnect, SIMCONNECT_DATA_DEFINITION_ID DefineID, SIMCONNECT_DATA_REQUEST_ID RequestID, const char * ICAO, const char * Region = "");
+func (sc *SimConnect) RequestFacilityData(DefineID uint32, RequestID uint32, ICAO string, Region string) (error, uint32) {
+	tr, err := feature[FacilitiesTransport](sc.transport)
+	if err != nil {
+		return err, 0
+	}
+	return sc.result(tr.RequestFacilityData(DefineID, RequestID, ICAO, Region))
 }
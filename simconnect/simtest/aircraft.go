@@ -0,0 +1,166 @@
+package simtest
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"strings"
+	"sync"
+
+	sim "github.com/flysim-apps/simgo/simconnect"
+)
+
+// EventHandler is called when a client transmits the sim event it is registered for
+type EventHandler func(a *Aircraft, data uint32)
+
+// Aircraft is a scriptable model of the user aircraft.
+// SimVars are stored by name like "PLANE ALTITUDE" or "GENERAL ENG RPM:1", units are ignored.
+// Numeric values are kept as float64, strings as string and structures as
+// sim.SIMCONNECT_DATA_LATLONALT, sim.SIMCONNECT_DATA_XYZ or sim.SIMCONNECT_DATA_WAYPOINT.
+type Aircraft struct {
+	mu     sync.Mutex
+	vars   map[string]interface{}
+	events map[string]EventHandler
+}
+
+// NewAircraft create an aircraft without SimVar
+func NewAircraft() *Aircraft {
+	return &Aircraft{
+		vars:   make(map[string]interface{}),
+		events: make(map[string]EventHandler),
+	}
+}
+
+func key(name string) string {
+	return strings.ToUpper(strings.TrimSpace(name))
+}
+
+// Set the value of a SimVar. Integers and booleans are stored as float64
+func (a *Aircraft) Set(name string, value interface{}) {
+	value = normalize(value)
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.vars[key(name)] = value
+}
+
+// normalize return integers, float32 and booleans as float64, other values are unchanged
+func normalize(value interface{}) interface{} {
+	switch v := value.(type) {
+	case bool:
+		if v {
+			return float64(1)
+		}
+		return float64(0)
+	case int:
+		return float64(v)
+	case int32:
+		return float64(v)
+	case int64:
+		return float64(v)
+	case uint32:
+		return float64(v)
+	case float32:
+		return float64(v)
+	}
+	return value
+}
+
+// Get return the value of a SimVar and false if it was never set
+func (a *Aircraft) Get(name string) (interface{}, bool) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	v, found := a.vars[key(name)]
+	return v, found
+}
+
+// Float64 return the numeric value of a SimVar or 0
+func (a *Aircraft) Float64(name string) float64 {
+	v, _ := a.Get(name)
+	f, _ := v.(float64)
+	return f
+}
+
+// String return the string value of a SimVar or ""
+func (a *Aircraft) String(name string) string {
+	v, _ := a.Get(name)
+	s, _ := v.(string)
+	return s
+}
+
+// Has return true if the SimVar was set
+func (a *Aircraft) Has(name string) bool {
+	_, found := a.Get(name)
+	return found
+}
+
+// OnEvent register the handler run when a client transmits the sim event (e.g. "BRAKES")
+func (a *Aircraft) OnEvent(name string, handler EventHandler) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.events[key(name)] = handler
+}
+
+func (a *Aircraft) fire(name string, data uint32) {
+	a.mu.Lock()
+	handler, found := a.events[key(name)]
+	a.mu.Unlock()
+	if found {
+		handler(a, data)
+	}
+}
+
+// encode a value in the wire format of datumType
+func encode(value interface{}, datumType uint32) []byte {
+	b := make([]byte, datumSize(datumType))
+	f, _ := value.(float64)
+	switch datumType {
+	case sim.SIMCONNECT_DATATYPE_INT32:
+		binary.LittleEndian.PutUint32(b, uint32(int32(f)))
+	case sim.SIMCONNECT_DATATYPE_INT64:
+		binary.LittleEndian.PutUint64(b, uint64(int64(f)))
+	case sim.SIMCONNECT_DATATYPE_FLOAT32:
+		binary.LittleEndian.PutUint32(b, math.Float32bits(float32(f)))
+	case sim.SIMCONNECT_DATATYPE_FLOAT64:
+		binary.LittleEndian.PutUint64(b, math.Float64bits(f))
+	case sim.SIMCONNECT_DATATYPE_LATLONALT, sim.SIMCONNECT_DATATYPE_XYZ, sim.SIMCONNECT_DATATYPE_WAYPOINT:
+		buf := new(bytes.Buffer)
+		if binary.Write(buf, binary.LittleEndian, value) == nil {
+			copy(b, buf.Bytes())
+		}
+	default:
+		s, _ := value.(string)
+		copy(b[:len(b)-1], s)
+	}
+	return b
+}
+
+// decode a value sent by a client in the wire format of datumType
+func decode(b []byte, datumType uint32) interface{} {
+	if len(b) < datumSize(datumType) {
+		return nil
+	}
+	switch datumType {
+	case sim.SIMCONNECT_DATATYPE_INT32:
+		return float64(int32(binary.LittleEndian.Uint32(b)))
+	case sim.SIMCONNECT_DATATYPE_INT64:
+		return float64(int64(binary.LittleEndian.Uint64(b)))
+	case sim.SIMCONNECT_DATATYPE_FLOAT32:
+		return float64(math.Float32frombits(binary.LittleEndian.Uint32(b)))
+	case sim.SIMCONNECT_DATATYPE_FLOAT64:
+		return math.Float64frombits(binary.LittleEndian.Uint64(b))
+	case sim.SIMCONNECT_DATATYPE_LATLONALT:
+		v := sim.SIMCONNECT_DATA_LATLONALT{}
+		binary.Read(bytes.NewReader(b), binary.LittleEndian, &v)
+		return v
+	case sim.SIMCONNECT_DATATYPE_XYZ:
+		v := sim.SIMCONNECT_DATA_XYZ{}
+		binary.Read(bytes.NewReader(b), binary.LittleEndian, &v)
+		return v
+	case sim.SIMCONNECT_DATATYPE_WAYPOINT:
+		v := sim.SIMCONNECT_DATA_WAYPOINT{}
+		binary.Read(bytes.NewReader(b), binary.LittleEndian, &v)
+		return v
+	}
+	r := &reader{buf: b}
+	return r.string(datumSize(datumType))
+}
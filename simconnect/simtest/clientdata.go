@@ -0,0 +1,301 @@
+package simtest
+
+import (
+	"bytes"
+	"sync"
+	"time"
+
+	sim "github.com/flysim-apps/simgo/simconnect"
+)
+
+// clientArea is a client data area created by a client or by the server
+type clientArea struct {
+	data     []byte
+	owner    *client
+	readOnly bool
+}
+
+// clientDatum is a datum of a client data definition
+type clientDatum struct {
+	offset, size, datumID uint32
+}
+
+// clientDataRequest is a periodic RequestClientData, closing stop ends it. mu guards the count of periods,
+// the count of sent data and the last data sent
+type clientDataRequest struct {
+	name                       string
+	requestID, defineID, flags uint32
+	period, origin, interval   uint32
+	limit                      uint32
+	stop                       chan struct{}
+
+	mu      sync.Mutex
+	n, sent uint32
+	last    []byte
+}
+
+// ClientData return a copy of the client data area name, nil when it is not created
+func (s *Server) ClientData(name string) []byte {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	area, found := s.clientData[name]
+	if !found {
+		return nil
+	}
+	return append([]byte(nil), area.data...)
+}
+
+// SetClientData write data at the beginning of the client data area name, like a gauge would. The area is
+// created when missing. Clients subscribed with SIMCONNECT_CLIENT_DATA_PERIOD_ON_SET are notified
+func (s *Server) SetClientData(name string, data []byte) {
+	s.mu.Lock()
+	area, found := s.clientData[name]
+	if !found {
+		area = &clientArea{data: make([]byte, len(data))}
+		s.clientData[name] = area
+	}
+	copy(area.data, data)
+	s.mu.Unlock()
+	s.clientDataSet(name)
+}
+
+// clientDataSet notify the ON_SET subscribers of the client data area name and run the MobiFlight commands
+func (s *Server) clientDataSet(name string) {
+	defer s.mobiFlightCommand(name)
+	for _, c := range s.allClients() {
+		c.mu.Lock()
+		requests := make([]*clientDataRequest, 0)
+		for _, req := range c.clientDataRequests {
+			if req.name == name && req.period == sim.SIMCONNECT_CLIENT_DATA_PERIOD_ON_SET {
+				requests = append(requests, req)
+			}
+		}
+		c.mu.Unlock()
+		for _, req := range requests {
+			c.sendClientDataPeriod(req)
+		}
+	}
+}
+
+// clientDatumSize return the size of a datum declared with a size or a SIMCONNECT_CLIENTDATATYPE
+func clientDatumSize(sizeOrType uint32) uint32 {
+	switch int32(sizeOrType) {
+	case -1: // SIMCONNECT_CLIENTDATATYPE_INT8
+		return 1
+	case -2: // SIMCONNECT_CLIENTDATATYPE_INT16
+		return 2
+	case -3, -5: // SIMCONNECT_CLIENTDATATYPE_INT32, SIMCONNECT_CLIENTDATATYPE_FLOAT32
+		return 4
+	case -4, -6: // SIMCONNECT_CLIENTDATATYPE_INT64, SIMCONNECT_CLIENTDATATYPE_FLOAT64
+		return 8
+	}
+	return sizeOrType
+}
+
+func (c *client) mapClientData(sendID, clientDataID uint32, name string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if prev, found := c.clientDataIDs[clientDataID]; found && prev != name {
+		c.sendException(sim.SIMCONNECT_EXCEPTION_DUPLICATE_ID, sendID, 2)
+		return
+	}
+	c.clientDataIDs[clientDataID] = name
+}
+
+func (c *client) clientDataName(clientDataID uint32) (string, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	name, found := c.clientDataIDs[clientDataID]
+	return name, found
+}
+
+func (c *client) createClientData(sendID, clientDataID, size, flags uint32) {
+	name, found := c.clientDataName(clientDataID)
+	if !found {
+		c.sendException(sim.SIMCONNECT_EXCEPTION_UNRECOGNIZED_ID, sendID, 1)
+		return
+	}
+	if size == 0 || size > sim.SIMCONNECT_CLIENTDATA_MAX_SIZE {
+		c.sendException(sim.SIMCONNECT_EXCEPTION_OUT_OF_BOUNDS, sendID, 2)
+		return
+	}
+	c.srv.mu.Lock()
+	if _, found := c.srv.clientData[name]; found {
+		c.srv.mu.Unlock()
+		c.sendException(sim.SIMCONNECT_EXCEPTION_ALREADY_CREATED, sendID, 1)
+		return
+	}
+	c.srv.clientData[name] = &clientArea{
+		data:     make([]byte, size),
+		owner:    c,
+		readOnly: flags&sim.SIMCONNECT_CREATE_CLIENT_DATA_FLAG_READ_ONLY != 0,
+	}
+	c.srv.mu.Unlock()
+}
+
+func (c *client) addToClientDataDefinition(defineID, offset, sizeOrType, datumID uint32) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.clientDataDefs[defineID] = append(c.clientDataDefs[defineID], clientDatum{offset, clientDatumSize(sizeOrType), datumID})
+}
+
+// readClientData return the values of a definition in the client data area name, false when the area is not
+// created or too small
+func (c *client) readClientData(name string, def []clientDatum) ([][]byte, bool) {
+	c.srv.mu.Lock()
+	defer c.srv.mu.Unlock()
+	area, found := c.srv.clientData[name]
+	if !found {
+		return nil, false
+	}
+	values := make([][]byte, len(def))
+	for i, d := range def {
+		if d.offset+d.size > uint32(len(area.data)) {
+			return nil, false
+		}
+		values[i] = append([]byte(nil), area.data[d.offset:d.offset+d.size]...)
+	}
+	return values, true
+}
+
+// sendClientData send a SIMCONNECT_RECV_CLIENT_DATA, it return false when nothing was sent. With
+// SIMCONNECT_CLIENT_DATA_REQUEST_FLAG_CHANGED nothing is sent until the data differs from the last data sent
+func (c *client) sendClientData(req *clientDataRequest) bool {
+	c.mu.Lock()
+	def, found := c.clientDataDefs[req.defineID]
+	c.mu.Unlock()
+	if !found {
+		return false
+	}
+	values, ok := c.readClientData(req.name, def)
+	if !ok {
+		return false
+	}
+	data := bytes.Join(values, nil)
+	if req.flags&sim.SIMCONNECT_CLIENT_DATA_REQUEST_FLAG_CHANGED != 0 && req.last != nil && bytes.Equal(req.last, data) {
+		return false
+	}
+	req.last = data
+
+	w := newWriter(sim.SIMCONNECT_RECV_ID_CLIENT_DATA)
+	w.uint32(req.requestID, userObjectID, req.defineID, req.flags, 1, 1, uint32(len(def)))
+	for i, b := range values {
+		if req.flags&sim.SIMCONNECT_CLIENT_DATA_REQUEST_FLAG_TAGGED != 0 {
+			w.uint32(def[i].datumID)
+		}
+		w.bytes(b)
+	}
+	c.send(w.packet())
+	return true
+}
+
+// sendClientDataPeriod send the data of a periodic request honouring its origin, interval and limit
+func (c *client) sendClientDataPeriod(req *clientDataRequest) {
+	req.mu.Lock()
+	defer req.mu.Unlock()
+	n := req.n
+	req.n++
+	if n < req.origin || (n-req.origin)%(req.interval+1) != 0 || (req.limit != 0 && req.sent >= req.limit) {
+		return
+	}
+	if c.sendClientData(req) {
+		req.sent++
+	}
+}
+
+func (c *client) requestClientData(sendID uint32, clientDataID uint32, req *clientDataRequest) {
+	name, found := c.clientDataName(clientDataID)
+	if !found {
+		c.sendException(sim.SIMCONNECT_EXCEPTION_UNRECOGNIZED_ID, sendID, 1)
+		return
+	}
+	req.name = name
+	req.stop = make(chan struct{})
+	c.mu.Lock()
+	if prev, found := c.clientDataRequests[req.requestID]; found {
+		close(prev.stop)
+		delete(c.clientDataRequests, req.requestID)
+	}
+	_, defined := c.clientDataDefs[req.defineID]
+	if req.period == sim.SIMCONNECT_CLIENT_DATA_PERIOD_NEVER {
+		c.mu.Unlock()
+		return
+	}
+	if !defined {
+		c.mu.Unlock()
+		c.sendException(sim.SIMCONNECT_EXCEPTION_UNRECOGNIZED_ID, sendID, 3)
+		return
+	}
+	if req.period != sim.SIMCONNECT_CLIENT_DATA_PERIOD_ONCE {
+		c.clientDataRequests[req.requestID] = req
+	}
+	c.mu.Unlock()
+
+	switch req.period {
+	case sim.SIMCONNECT_CLIENT_DATA_PERIOD_ONCE:
+		if !c.sendClientData(req) {
+			c.sendException(sim.SIMCONNECT_EXCEPTION_UNRECOGNIZED_ID, sendID, 1)
+		}
+		return
+	case sim.SIMCONNECT_CLIENT_DATA_PERIOD_ON_SET:
+		c.sendClientDataPeriod(req)
+		return
+	}
+	c.sendClientDataPeriod(req)
+	period := c.srv.Frame
+	if req.period == sim.SIMCONNECT_CLIENT_DATA_PERIOD_SECOND {
+		period = time.Second
+	}
+	go func() {
+		ticker := time.NewTicker(period)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-req.stop:
+				return
+			case <-c.done:
+				return
+			case <-ticker.C:
+			}
+			c.sendClientDataPeriod(req)
+		}
+	}()
+}
+
+func (c *client) setClientData(sendID, clientDataID, defineID uint32, data []byte) {
+	name, found := c.clientDataName(clientDataID)
+	if !found {
+		c.sendException(sim.SIMCONNECT_EXCEPTION_UNRECOGNIZED_ID, sendID, 1)
+		return
+	}
+	c.mu.Lock()
+	def, found := c.clientDataDefs[defineID]
+	c.mu.Unlock()
+	if !found {
+		c.sendException(sim.SIMCONNECT_EXCEPTION_UNRECOGNIZED_ID, sendID, 2)
+		return
+	}
+	c.srv.mu.Lock()
+	area, found := c.srv.clientData[name]
+	switch {
+	case !found:
+		c.srv.mu.Unlock()
+		c.sendException(sim.SIMCONNECT_EXCEPTION_UNRECOGNIZED_ID, sendID, 1)
+		return
+	case area.readOnly && area.owner != c:
+		c.srv.mu.Unlock()
+		c.sendException(sim.SIMCONNECT_EXCEPTION_ILLEGAL_OPERATION, sendID, 1)
+		return
+	}
+	for _, d := range def {
+		if uint32(len(data)) < d.size || d.offset+d.size > uint32(len(area.data)) {
+			c.srv.mu.Unlock()
+			c.sendException(sim.SIMCONNECT_EXCEPTION_DATA_ERROR, sendID, 6)
+			return
+		}
+		copy(area.data[d.offset:], data[:d.size])
+		data = data[d.size:]
+	}
+	c.srv.mu.Unlock()
+	c.srv.clientDataSet(name)
+}
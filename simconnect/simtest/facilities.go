@@ -0,0 +1,180 @@
+package simtest
+
+import (
+	"encoding/binary"
+	"math"
+	"time"
+
+	sim "github.com/flysim-apps/simgo/simconnect"
+)
+
+const (
+	// bubbleRadius is the radius of the reality bubble around the user aircraft, in meters
+	bubbleRadius = 100000
+	// facilitiesPerPacket is the number of facilities of a SIMCONNECT_RECV_*_LIST, longer lists are chopped
+	facilitiesPerPacket = 16
+)
+
+// AddFacility add a sim.FacilityAirport, sim.FacilityWaypoint, sim.FacilityNDB or sim.FacilityVOR. It is in the
+// facility lists while within 100 km of the user aircraft
+func (s *Server) AddFacility(f sim.Facility) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.facilities = append(s.facilities, f)
+}
+
+// facilityType return the list type of a facility
+func facilityType(f sim.Facility) uint32 {
+	switch f.(type) {
+	case sim.FacilityAirport:
+		return sim.SIMCONNECT_FACILITY_LIST_TYPE_AIRPORT
+	case sim.FacilityWaypoint:
+		return sim.SIMCONNECT_FACILITY_LIST_TYPE_WAYPOINT
+	case sim.FacilityNDB:
+		return sim.SIMCONNECT_FACILITY_LIST_TYPE_NDB
+	}
+	return sim.SIMCONNECT_FACILITY_LIST_TYPE_VOR
+}
+
+// facilitiesAround return the facilities of listType in the reality bubble
+func (s *Server) facilitiesAround(listType uint32) []sim.Facility {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	list := make([]sim.Facility, 0)
+	for _, f := range s.facilities {
+		if facilityType(f) != listType {
+			continue
+		}
+		airport := facilityAirport(f)
+		position := NewAircraft()
+		position.Set("PLANE LATITUDE", airport.Latitude)
+		position.Set("PLANE LONGITUDE", airport.Longitude)
+		if distance(s.Aircraft, position) <= bubbleRadius {
+			list = append(list, f)
+		}
+	}
+	return list
+}
+
+func facilityAirport(f sim.Facility) sim.FacilityAirport {
+	switch f := f.(type) {
+	case sim.FacilityAirport:
+		return f
+	case sim.FacilityWaypoint:
+		return f.FacilityAirport
+	case sim.FacilityNDB:
+		return f.FacilityAirport
+	case sim.FacilityVOR:
+		return f.FacilityAirport
+	}
+	return sim.FacilityAirport{}
+}
+
+// encodeFacility write a facility as its packed SIMCONNECT_DATA_FACILITY_* structure
+func encodeFacility(f sim.Facility) []byte {
+	b := make([]byte, 0, 80)
+	f64 := func(v float64) { b = binary.LittleEndian.AppendUint64(b, math.Float64bits(v)) }
+	f32 := func(v float32) { b = binary.LittleEndian.AppendUint32(b, math.Float32bits(v)) }
+	u32 := func(v uint32) { b = binary.LittleEndian.AppendUint32(b, v) }
+
+	airport := facilityAirport(f)
+	icao := make([]byte, 9)
+	copy(icao[:5], airport.Ident)
+	copy(icao[6:8], airport.Region)
+	b = append(b, icao...)
+	f64(airport.Latitude)
+	f64(airport.Longitude)
+	f64(airport.Altitude)
+	switch f := f.(type) {
+	case sim.FacilityWaypoint:
+		f32(f.MagVar)
+	case sim.FacilityNDB:
+		f32(f.MagVar)
+		u32(f.Frequency)
+	case sim.FacilityVOR:
+		f32(f.MagVar)
+		u32(f.Frequency)
+		u32(f.Flags)
+		f32(f.Localizer)
+		f64(f.GlideLat)
+		f64(f.GlideLon)
+		f64(f.GlideAlt)
+		f32(f.GlideSlopeAngle)
+	}
+	return b
+}
+
+// sendFacilities send a facility list chopped in packets of facilitiesPerPacket facilities
+func (c *client) sendFacilities(listType, requestID uint32, list []sim.Facility) {
+	recvID := map[uint32]uint32{
+		sim.SIMCONNECT_FACILITY_LIST_TYPE_AIRPORT:  sim.SIMCONNECT_RECV_ID_AIRPORT_LIST,
+		sim.SIMCONNECT_FACILITY_LIST_TYPE_WAYPOINT: sim.SIMCONNECT_RECV_ID_WAYPOINT_LIST,
+		sim.SIMCONNECT_FACILITY_LIST_TYPE_NDB:      sim.SIMCONNECT_RECV_ID_NDB_LIST,
+		sim.SIMCONNECT_FACILITY_LIST_TYPE_VOR:      sim.SIMCONNECT_RECV_ID_VOR_LIST,
+	}[listType]
+	outof := (len(list) + facilitiesPerPacket - 1) / facilitiesPerPacket
+	if outof == 0 {
+		outof = 1
+	}
+	for entry := 0; entry < outof; entry++ {
+		chunk := list[entry*facilitiesPerPacket:]
+		if len(chunk) > facilitiesPerPacket {
+			chunk = chunk[:facilitiesPerPacket]
+		}
+		w := newWriter(recvID).uint32(requestID, uint32(len(chunk)), uint32(entry), uint32(outof))
+		for _, f := range chunk {
+			w.bytes(encodeFacility(f))
+		}
+		c.send(w.packet())
+	}
+}
+
+// subscribeFacilities send the facilities of listType entering the reality bubble every frame until
+// UnsubscribeToFacilities
+func (c *client) subscribeFacilities(listType, requestID uint32) {
+	stop := make(chan struct{})
+	c.mu.Lock()
+	if prev, found := c.facilities[listType]; found {
+		close(prev)
+	}
+	c.facilities[listType] = stop
+	c.mu.Unlock()
+
+	go func() {
+		ticker := time.NewTicker(c.srv.Frame)
+		defer ticker.Stop()
+		inside := make(map[string]struct{})
+		for {
+			list := c.srv.facilitiesAround(listType)
+			entering := make([]sim.Facility, 0)
+			now := make(map[string]struct{}, len(list))
+			for _, f := range list {
+				now[f.Key()] = struct{}{}
+				if _, found := inside[f.Key()]; !found {
+					entering = append(entering, f)
+				}
+			}
+			inside = now
+			if len(entering) > 0 {
+				c.sendFacilities(listType, requestID, entering)
+			}
+			select {
+			case <-stop:
+				return
+			case <-c.done:
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// unsubscribeFacilities stop the subscription of listType
+func (c *client) unsubscribeFacilities(listType uint32) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if stop, found := c.facilities[listType]; found {
+		close(stop)
+		delete(c.facilities, listType)
+	}
+}
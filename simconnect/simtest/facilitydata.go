@@ -0,0 +1,273 @@
+package simtest
+
+import (
+	"encoding/binary"
+	"math"
+	"strings"
+
+	sim "github.com/flysim-apps/simgo/simconnect"
+)
+
+// AddAirportData add the airport answered to the facility data requests of its ICAO
+func (s *Server) AddAirportData(a sim.FacilityAirportData) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.airports[a.ICAO] = &a
+}
+
+// facilityObject is an object of a facility definition, between its OPEN and CLOSE fields
+type facilityObject struct {
+	name     string
+	fields   []string
+	children []*facilityObject
+}
+
+// facilityObjectFields are the fields known by the emulator for each object
+var facilityObjectFields = map[string][]string{
+	"AIRPORT":             {"ICAO", "REGION", "NAME", "LATITUDE", "LONGITUDE", "ALTITUDE", "MAGVAR"},
+	"RUNWAY":              {"LATITUDE", "LONGITUDE", "ALTITUDE", "HEADING", "LENGTH", "WIDTH", "SURFACE", "PRIMARY_NUMBER", "PRIMARY_DESIGNATOR", "SECONDARY_NUMBER", "SECONDARY_DESIGNATOR"},
+	"PRIMARY_THRESHOLD":   {"LENGTH"},
+	"SECONDARY_THRESHOLD": {"LENGTH"},
+	"TAXI_PARKING":        {"TYPE", "NAME", "SUFFIX", "NUMBER", "HEADING", "RADIUS", "BIAS_X", "BIAS_Z"},
+	"FREQUENCY":           {"TYPE", "FREQUENCY", "NAME"},
+}
+
+// parseFacilityDefinition return the objects of a facility definition and the object open at its end, the
+// definition is invalid when ok is false
+func parseFacilityDefinition(def []string) (root *facilityObject, open *facilityObject, ok bool) {
+	stack := make([]*facilityObject, 0)
+	for _, field := range def {
+		switch {
+		case strings.HasPrefix(field, "OPEN "):
+			name := strings.TrimPrefix(field, "OPEN ")
+			if _, known := facilityObjectFields[name]; !known {
+				return nil, nil, false
+			}
+			o := &facilityObject{name: name}
+			if len(stack) == 0 {
+				if root != nil {
+					return nil, nil, false
+				}
+				root = o
+			} else {
+				top := stack[len(stack)-1]
+				top.children = append(top.children, o)
+			}
+			stack = append(stack, o)
+		case strings.HasPrefix(field, "CLOSE "):
+			if len(stack) == 0 || stack[len(stack)-1].name != strings.TrimPrefix(field, "CLOSE ") {
+				return nil, nil, false
+			}
+			stack = stack[:len(stack)-1]
+		default:
+			if len(stack) == 0 {
+				return nil, nil, false
+			}
+			top := stack[len(stack)-1]
+			known := false
+			for _, f := range facilityObjectFields[top.name] {
+				known = known || f == field
+			}
+			if !known {
+				return nil, nil, false
+			}
+			top.fields = append(top.fields, field)
+		}
+	}
+	if len(stack) > 0 {
+		open = stack[len(stack)-1]
+	}
+	return root, open, true
+}
+
+// addToFacilityDefinition add a field to a facility definition, SIMCONNECT_EXCEPTION_NAME_UNRECOGNIZED is sent
+// for unknown objects and fields
+func (c *client) addToFacilityDefinition(sendID, defineID uint32, field string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	def := append(c.facilityDefs[defineID], field)
+	if _, _, ok := parseFacilityDefinition(def); !ok {
+		c.sendException(sim.SIMCONNECT_EXCEPTION_NAME_UNRECOGNIZED, sendID, 2)
+		return
+	}
+	c.facilityDefs[defineID] = def
+}
+
+type facilityEncoder struct {
+	buf []byte
+}
+
+func (e *facilityEncoder) float64(v float64) {
+	e.buf = binary.LittleEndian.AppendUint64(e.buf, math.Float64bits(v))
+}
+
+func (e *facilityEncoder) float32(v float32) {
+	e.buf = binary.LittleEndian.AppendUint32(e.buf, math.Float32bits(v))
+}
+
+func (e *facilityEncoder) int32(v int32) {
+	e.buf = binary.LittleEndian.AppendUint32(e.buf, uint32(v))
+}
+
+func (e *facilityEncoder) string(v string, size int) {
+	b := make([]byte, size)
+	copy(b[:size-1], v)
+	e.buf = append(e.buf, b...)
+}
+
+// encodeAirport write the fields of an airport object
+func encodeAirport(fields []string, a *sim.FacilityAirportData) []byte {
+	e := &facilityEncoder{}
+	for _, field := range fields {
+		switch field {
+		case "ICAO":
+			e.string(a.ICAO, 8)
+		case "REGION":
+			e.string(a.Region, 8)
+		case "NAME":
+			e.string(a.Name, 32)
+		case "LATITUDE":
+			e.float64(a.Latitude)
+		case "LONGITUDE":
+			e.float64(a.Longitude)
+		case "ALTITUDE":
+			e.float64(a.Altitude)
+		case "MAGVAR":
+			e.float32(a.MagVar)
+		}
+	}
+	return e.buf
+}
+
+// encodeRunway write the fields of a runway object
+func encodeRunway(fields []string, r sim.FacilityRunway) []byte {
+	e := &facilityEncoder{}
+	for _, field := range fields {
+		switch field {
+		case "LATITUDE":
+			e.float64(r.Latitude)
+		case "LONGITUDE":
+			e.float64(r.Longitude)
+		case "ALTITUDE":
+			e.float64(r.Altitude)
+		case "HEADING":
+			e.float32(r.Heading)
+		case "LENGTH":
+			e.float32(r.Length)
+		case "WIDTH":
+			e.float32(r.Width)
+		case "SURFACE":
+			e.int32(r.Surface)
+		case "PRIMARY_NUMBER":
+			e.int32(r.PrimaryNumber)
+		case "PRIMARY_DESIGNATOR":
+			e.int32(r.PrimaryDesignator)
+		case "SECONDARY_NUMBER":
+			e.int32(r.SecondaryNumber)
+		case "SECONDARY_DESIGNATOR":
+			e.int32(r.SecondaryDesignator)
+		}
+	}
+	return e.buf
+}
+
+// encodeParking write the fields of a parking object
+func encodeParking(fields []string, p sim.FacilityParking) []byte {
+	e := &facilityEncoder{}
+	for _, field := range fields {
+		switch field {
+		case "TYPE":
+			e.int32(p.Type)
+		case "NAME":
+			e.int32(p.Name)
+		case "SUFFIX":
+			e.int32(p.Suffix)
+		case "NUMBER":
+			e.int32(int32(p.Number))
+		case "HEADING":
+			e.float32(p.Heading)
+		case "RADIUS":
+			e.float32(p.Radius)
+		case "BIAS_X":
+			e.float32(p.BiasX)
+		case "BIAS_Z":
+			e.float32(p.BiasZ)
+		}
+	}
+	return e.buf
+}
+
+// encodeFrequency write the fields of a frequency object
+func encodeFrequency(fields []string, f sim.FacilityFrequency) []byte {
+	e := &facilityEncoder{}
+	for _, field := range fields {
+		switch field {
+		case "TYPE":
+			e.int32(f.Type)
+		case "FREQUENCY":
+			e.int32(f.Frequency)
+		case "NAME":
+			e.string(f.Name, 64)
+		}
+	}
+	return e.buf
+}
+
+// requestFacilityData send the objects of the airport icao selected by a facility definition then
+// SIMCONNECT_RECV_ID_FACILITY_DATA_END, only the end is sent for unknown airports
+func (c *client) requestFacilityData(sendID, defineID, requestID uint32, icao string) {
+	c.mu.Lock()
+	def, found := c.facilityDefs[defineID]
+	c.mu.Unlock()
+	root, open, ok := parseFacilityDefinition(def)
+	if !found || !ok || open != nil || root == nil || root.name != "AIRPORT" {
+		c.sendException(sim.SIMCONNECT_EXCEPTION_UNRECOGNIZED_ID, sendID, 1)
+		return
+	}
+	c.srv.mu.Lock()
+	a, found := c.srv.airports[icao]
+	c.srv.mu.Unlock()
+
+	if found {
+		uniqueID := uint32(0)
+		send := func(parentID, objectType, index, size uint32, data []byte) uint32 {
+			uniqueID++
+			isList := uint32(0)
+			if size > 0 {
+				isList = 1
+			}
+			c.send(newWriter(sim.SIMCONNECT_RECV_ID_FACILITY_DATA).
+				uint32(requestID, uniqueID, parentID, objectType, isList, index, size).bytes(data).packet())
+			return uniqueID
+		}
+		airportID := send(0, sim.SIMCONNECT_FACILITY_DATA_AIRPORT, 0, 0, encodeAirport(root.fields, a))
+		for _, child := range root.children {
+			switch child.name {
+			case "RUNWAY":
+				for i, r := range a.Runways {
+					runwayID := send(airportID, sim.SIMCONNECT_FACILITY_DATA_RUNWAY, uint32(i), uint32(len(a.Runways)), encodeRunway(child.fields, r))
+					for _, threshold := range child.children {
+						length := r.PrimaryThreshold
+						if threshold.name == "SECONDARY_THRESHOLD" {
+							length = r.SecondaryThreshold
+						}
+						e := &facilityEncoder{}
+						for range threshold.fields {
+							e.float32(length)
+						}
+						send(runwayID, sim.SIMCONNECT_FACILITY_DATA_PAVEMENT, 0, 0, e.buf)
+					}
+				}
+			case "TAXI_PARKING":
+				for i, p := range a.Parkings {
+					send(airportID, sim.SIMCONNECT_FACILITY_DATA_TAXI_PARKING, uint32(i), uint32(len(a.Parkings)), encodeParking(child.fields, p))
+				}
+			case "FREQUENCY":
+				for i, f := range a.Frequencies {
+					send(airportID, sim.SIMCONNECT_FACILITY_DATA_FREQUENCY, uint32(i), uint32(len(a.Frequencies)), encodeFrequency(child.fields, f))
+				}
+			}
+		}
+	}
+	c.send(newWriter(sim.SIMCONNECT_RECV_ID_FACILITY_DATA_END).uint32(requestID).packet())
+}
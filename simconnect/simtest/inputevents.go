@@ -0,0 +1,195 @@
+package simtest
+
+import (
+	"encoding/binary"
+	"hash/fnv"
+	"math"
+	"strings"
+
+	sim "github.com/flysim-apps/simgo/simconnect"
+)
+
+// inputEventsPerPacket is the number of descriptors of a SIMCONNECT_RECV_ENUMERATE_INPUT_EVENTS, longer
+// catalogs are chopped
+const inputEventsPerPacket = 16
+
+// inputEvent is an input event of the aircraft, value is a float64 or a string
+type inputEvent struct {
+	name   string
+	hash   uint64
+	value  interface{}
+	params string
+}
+
+func (e *inputEvent) eventType() uint32 {
+	if _, ok := e.value.(string); ok {
+		return sim.SIMCONNECT_INPUT_EVENT_TYPE_STRING
+	}
+	return sim.SIMCONNECT_INPUT_EVENT_TYPE_DOUBLE
+}
+
+// encode return the value like in SIMCONNECT_RECV_GET_INPUT_EVENT
+func (e *inputEvent) encode() []byte {
+	if s, ok := e.value.(string); ok {
+		b := make([]byte, sim.MAX_PATH)
+		copy(b[:sim.MAX_PATH-1], s)
+		return b
+	}
+	return binary.LittleEndian.AppendUint64(nil, math.Float64bits(e.value.(float64)))
+}
+
+// inputEventValue return value as a string or a float64, values of other types are 0
+func inputEventValue(value interface{}) interface{} {
+	switch v := normalize(value).(type) {
+	case string, float64:
+		return v
+	}
+	return float64(0)
+}
+
+// AddInputEvent add an input event to the aircraft and return its hash. value is a string or a number, params
+// are the types of its parameters separated by ;
+func (s *Server) AddInputEvent(name string, value interface{}, params string) uint64 {
+	h := fnv.New64a()
+	h.Write([]byte(key(name)))
+	e := &inputEvent{name: name, hash: h.Sum64(), value: inputEventValue(value), params: params}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.inputEvents = append(s.inputEvents, e)
+	return e.hash
+}
+
+// InputEvent return the value of an input event, a float64 or a string
+func (s *Server) InputEvent(name string) (interface{}, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, e := range s.inputEvents {
+		if key(e.name) == key(name) {
+			return e.value, true
+		}
+	}
+	return nil, false
+}
+
+// SetInputEvent change the value of an input event, like a control moved in the virtual cockpit. The clients
+// subscribed to it are notified
+func (s *Server) SetInputEvent(name string, value interface{}) {
+	s.mu.Lock()
+	var event *inputEvent
+	for _, e := range s.inputEvents {
+		if key(e.name) == key(name) {
+			e.value = inputEventValue(value)
+			event = e
+		}
+	}
+	s.mu.Unlock()
+	if event != nil {
+		s.inputEventChanged(event)
+	}
+}
+
+func (s *Server) inputEvent(hash uint64) (*inputEvent, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, e := range s.inputEvents {
+		if e.hash == hash {
+			return e, true
+		}
+	}
+	return nil, false
+}
+
+// inputEventChanged send a SIMCONNECT_RECV_SUBSCRIBE_INPUT_EVENT to the clients subscribed to e
+func (s *Server) inputEventChanged(e *inputEvent) {
+	s.mu.Lock()
+	w := newWriter(sim.SIMCONNECT_RECV_ID_SUBSCRIBE_INPUT_EVENT).uint64(e.hash).uint32(e.eventType()).bytes(e.encode())
+	s.mu.Unlock()
+	for _, c := range s.allClients() {
+		c.mu.Lock()
+		subscribed := c.inputEvents[e.hash]
+		c.mu.Unlock()
+		if subscribed {
+			c.send(w.packet())
+		}
+	}
+}
+
+// sendInputEvents send the catalog of the input events chopped in packets of inputEventsPerPacket descriptors
+func (c *client) sendInputEvents(requestID uint32) {
+	c.srv.mu.Lock()
+	list := append([]*inputEvent(nil), c.srv.inputEvents...)
+	c.srv.mu.Unlock()
+	outof := (len(list) + inputEventsPerPacket - 1) / inputEventsPerPacket
+	if outof == 0 {
+		outof = 1
+	}
+	for entry := 0; entry < outof; entry++ {
+		chunk := list[entry*inputEventsPerPacket:]
+		if len(chunk) > inputEventsPerPacket {
+			chunk = chunk[:inputEventsPerPacket]
+		}
+		w := newWriter(sim.SIMCONNECT_RECV_ID_ENUMERATE_INPUT_EVENTS).uint32(requestID, uint32(len(chunk)), uint32(entry), uint32(outof))
+		for _, e := range chunk {
+			w.string(e.name, 64).uint64(e.hash).uint32(e.eventType())
+		}
+		c.send(w.packet())
+	}
+}
+
+func (c *client) getInputEvent(sendID, requestID uint32, hash uint64) {
+	e, found := c.srv.inputEvent(hash)
+	if !found {
+		c.sendException(sim.SIMCONNECT_EXCEPTION_UNRECOGNIZED_ID, sendID, 2)
+		return
+	}
+	c.srv.mu.Lock()
+	w := newWriter(sim.SIMCONNECT_RECV_ID_GET_INPUT_EVENT).uint32(requestID, e.eventType()).bytes(e.encode())
+	c.srv.mu.Unlock()
+	c.send(w.packet())
+}
+
+func (c *client) setInputEvent(sendID uint32, hash uint64, data []byte) {
+	e, found := c.srv.inputEvent(hash)
+	if !found {
+		c.sendException(sim.SIMCONNECT_EXCEPTION_UNRECOGNIZED_ID, sendID, 1)
+		return
+	}
+	var value interface{}
+	switch {
+	case e.eventType() == sim.SIMCONNECT_INPUT_EVENT_TYPE_STRING:
+		value, _, _ = strings.Cut(string(data), "\x00")
+	case len(data) >= 8:
+		value = math.Float64frombits(binary.LittleEndian.Uint64(data))
+	default:
+		c.sendException(sim.SIMCONNECT_EXCEPTION_DATA_ERROR, sendID, 3)
+		return
+	}
+	c.srv.mu.Lock()
+	e.value = value
+	c.srv.mu.Unlock()
+	c.srv.inputEventChanged(e)
+}
+
+func (c *client) subscribeInputEvent(sendID uint32, hash uint64, subscribe bool) {
+	if _, found := c.srv.inputEvent(hash); !found {
+		c.sendException(sim.SIMCONNECT_EXCEPTION_UNRECOGNIZED_ID, sendID, 1)
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if subscribe {
+		c.inputEvents[hash] = true
+	} else {
+		delete(c.inputEvents, hash)
+	}
+}
+
+func (c *client) inputEventParams(sendID uint32, hash uint64) {
+	e, found := c.srv.inputEvent(hash)
+	if !found {
+		c.sendException(sim.SIMCONNECT_EXCEPTION_UNRECOGNIZED_ID, sendID, 1)
+		return
+	}
+	w := newWriter(sim.SIMCONNECT_RECV_ID_ENUMERATE_INPUT_EVENT_PARAMS).uint64(hash).string(e.params, sim.MAX_PATH)
+	c.send(w.packet())
+}
@@ -0,0 +1,109 @@
+package simtest
+
+import (
+	sim "github.com/flysim-apps/simgo/simconnect"
+)
+
+// menuItem is an item of the Add-ons menu added by a client, parentID is SIMCONNECT_UNUSED for a top level item
+type menuItem struct {
+	text     string
+	eventID  uint32
+	data     uint32
+	parentID uint32
+}
+
+// Menu return the items of the Add-ons menu added by the clients with the text of their sub items
+func (s *Server) Menu() map[string][]string {
+	menu := make(map[string][]string)
+	for _, c := range s.allClients() {
+		c.mu.Lock()
+		for _, item := range c.menu {
+			if _, found := menu[item.text]; !found && item.parentID == sim.SIMCONNECT_UNUSED {
+				menu[item.text] = []string{}
+			}
+		}
+		for _, item := range c.menu {
+			if parent, found := c.menuItem(item.parentID); found {
+				menu[parent.text] = append(menu[parent.text], item.text)
+			}
+		}
+		c.mu.Unlock()
+	}
+	return menu
+}
+
+// ClickMenuItem select the menu item at path, its text then the text of the sub item, and notify the client
+// having added it. False when the item does not exist
+func (s *Server) ClickMenuItem(path ...string) bool {
+	if len(path) == 0 || len(path) > 2 {
+		return false
+	}
+	for _, c := range s.allClients() {
+		c.mu.Lock()
+		var selected *menuItem
+		for _, item := range c.menu {
+			if item.text != path[0] || item.parentID != sim.SIMCONNECT_UNUSED {
+				continue
+			}
+			selected = item
+			if len(path) == 2 {
+				selected = nil
+				for _, sub := range c.menu {
+					if sub.parentID == item.eventID && sub.text == path[1] {
+						selected = sub
+					}
+				}
+			}
+		}
+		c.mu.Unlock()
+		if selected != nil {
+			c.sendEvent(sim.SIMCONNECT_UNUSED, selected.eventID, selected.data)
+			return true
+		}
+	}
+	return false
+}
+
+// menuItem return the item of eventID, c.mu must be held
+func (c *client) menuItem(eventID uint32) (*menuItem, bool) {
+	for _, item := range c.menu {
+		if item.eventID == eventID {
+			return item, true
+		}
+	}
+	return nil, false
+}
+
+func (c *client) addMenuItem(sendID uint32, item *menuItem) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, found := c.menuItem(item.eventID); found {
+		c.sendException(sim.SIMCONNECT_EXCEPTION_DUPLICATE_ID, sendID, 1)
+		return
+	}
+	if item.parentID != sim.SIMCONNECT_UNUSED {
+		if parent, found := c.menuItem(item.parentID); !found || parent.parentID != sim.SIMCONNECT_UNUSED {
+			c.sendException(sim.SIMCONNECT_EXCEPTION_UNRECOGNIZED_ID, sendID, 1)
+			return
+		}
+	}
+	c.menu = append(c.menu, item)
+}
+
+// deleteMenuItem remove the item of eventID, the sub items of a top level item are removed with it
+func (c *client) deleteMenuItem(sendID, parentID, eventID uint32) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	item, found := c.menuItem(eventID)
+	if !found || item.parentID != parentID {
+		c.sendException(sim.SIMCONNECT_EXCEPTION_UNRECOGNIZED_ID, sendID, 1)
+		return
+	}
+	menu := c.menu[:0]
+	for _, m := range c.menu {
+		if m != item && m.parentID != eventID {
+			menu = append(menu, m)
+		}
+	}
+	c.menu = menu
+}
@@ -0,0 +1,238 @@
+package simtest
+
+import (
+	"encoding/binary"
+	"math"
+	"strconv"
+	"strings"
+
+	sim "github.com/flysim-apps/simgo/simconnect"
+)
+
+// mobiFlightMessageSize is the size of the command and response areas of the MobiFlight WASM module
+const mobiFlightMessageSize = 1024
+
+// mobiFlight emulates the MobiFlight WASM module, clients are the L:vars registered by each client in order
+type mobiFlight struct {
+	lvars   map[string]float64
+	clients map[string][]string
+	hEvents []string
+}
+
+// StartMobiFlight install the MobiFlight WASM module: clients register with it to read and write the L:vars
+func (s *Server) StartMobiFlight() {
+	s.mu.Lock()
+	s.mobiFlight = &mobiFlight{
+		lvars:   make(map[string]float64),
+		clients: make(map[string][]string),
+	}
+	s.createClientData("MobiFlight.Command", mobiFlightMessageSize)
+	s.createClientData("MobiFlight.Response", mobiFlightMessageSize)
+	s.mu.Unlock()
+}
+
+// LVar return the value of an L:var, false when it was never set
+func (s *Server) LVar(name string) (float64, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.mobiFlight == nil {
+		return 0, false
+	}
+	value, found := s.mobiFlight.lvars[name]
+	return value, found
+}
+
+// SetLVar change the value of an L:var, like the aircraft would. The clients following it are notified
+func (s *Server) SetLVar(name string, value float64) {
+	s.mu.Lock()
+	if s.mobiFlight == nil {
+		s.mu.Unlock()
+		return
+	}
+	s.mobiFlight.lvars[name] = value
+	areas := s.refreshLVars()
+	s.mu.Unlock()
+	for _, area := range areas {
+		s.clientDataSet(area)
+	}
+}
+
+// HEvents return the H:events fired by the clients since the module started
+func (s *Server) HEvents() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.mobiFlight == nil {
+		return nil
+	}
+	return append([]string(nil), s.mobiFlight.hEvents...)
+}
+
+// response write a message into the response area of a client, s.mu is held
+func (s *Server) response(client, message string) {
+	data := s.clientData[client+".Response"].data
+	copy(data, make([]byte, len(data)))
+	copy(data[:len(data)-1], message)
+}
+
+// execute run RPN calculator code: numbers, + - * / !, (L:var) reads, (>L:var) writes, (>K:event) transmits
+// and (>H:event) fires. It return the sim events to transmit and the areas to notify, s.mu is held
+func (s *Server) execute(code string) ([]Event, []string) {
+	events := make([]Event, 0)
+	areas := make([]string, 0)
+	stack := make([]float64, 0)
+	pop := func() float64 {
+		if len(stack) == 0 {
+			return 0
+		}
+		v := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		return v
+	}
+	lvarsChanged := false
+	for _, token := range rpnTokens(code) {
+		// the unit of (L:var, unit) is ignored
+		name, _, _ := strings.Cut(strings.Trim(token, "()"), ",")
+		switch {
+		case strings.HasPrefix(name, "L:"):
+			stack = append(stack, s.mobiFlight.lvars[name[2:]])
+		case strings.HasPrefix(name, ">L:"):
+			s.mobiFlight.lvars[name[3:]] = pop()
+			lvarsChanged = true
+		case strings.HasPrefix(name, ">K:"):
+			events = append(events, Event{key(name[3:]), uint32(int32(pop()))})
+		case strings.HasPrefix(name, ">H:"):
+			s.mobiFlight.hEvents = append(s.mobiFlight.hEvents, name[3:])
+		case token == "!":
+			stack = append(stack, boolFloat(pop() == 0))
+		case token == "+", token == "-", token == "*", token == "/":
+			b, a := pop(), pop()
+			stack = append(stack, arithmetic(token, a, b))
+		default:
+			if v, err := strconv.ParseFloat(token, 64); err == nil {
+				stack = append(stack, v)
+			}
+		}
+	}
+	if lvarsChanged {
+		areas = append(areas, s.refreshLVars()...)
+	}
+	return events, areas
+}
+
+// rpnTokens split calculator code on spaces, a parenthesized variable like (L:var, bool) is one token
+func rpnTokens(code string) []string {
+	tokens := make([]string, 0)
+	for code = strings.TrimSpace(code); code != ""; code = strings.TrimSpace(code) {
+		end := strings.IndexAny(code, " \t\n")
+		if code[0] == '(' {
+			end = strings.IndexByte(code, ')') + 1
+		}
+		if end <= 0 {
+			end = len(code)
+		}
+		tokens = append(tokens, code[:end])
+		code = code[end:]
+	}
+	return tokens
+}
+
+func boolFloat(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+func arithmetic(operator string, a, b float64) float64 {
+	switch operator {
+	case "+":
+		return a + b
+	case "-":
+		return a - b
+	case "*":
+		return a * b
+	}
+	if b == 0 {
+		return 0
+	}
+	return a / b
+}
+
+// createClientData create the client data area name owned by the server when it is missing, s.mu is held
+func (s *Server) createClientData(name string, size int) {
+	if _, found := s.clientData[name]; !found {
+		s.clientData[name] = &clientArea{data: make([]byte, size)}
+	}
+}
+
+// refreshLVars write the L:vars into the LVars area of every MobiFlight client and return the areas, s.mu is held
+func (s *Server) refreshLVars() []string {
+	areas := make([]string, 0, len(s.mobiFlight.clients))
+	for client, lvars := range s.mobiFlight.clients {
+		area := s.clientData[client+".LVars"]
+		for i, name := range lvars {
+			if (i+1)*4 <= len(area.data) {
+				binary.LittleEndian.PutUint32(area.data[i*4:], math.Float32bits(float32(s.mobiFlight.lvars[name])))
+			}
+		}
+		areas = append(areas, client+".LVars")
+	}
+	return areas
+}
+
+// mobiFlightCommand run a command written into the command area of a MobiFlight client
+func (s *Server) mobiFlightCommand(area string) {
+	client, ok := strings.CutSuffix(area, ".Command")
+	if !ok {
+		return
+	}
+	s.mu.Lock()
+	if s.mobiFlight == nil {
+		s.mu.Unlock()
+		return
+	}
+	if _, found := s.mobiFlight.clients[client]; !found && client != "MobiFlight" {
+		s.mu.Unlock()
+		return
+	}
+	command := string(s.clientData[area].data)
+	if i := strings.IndexByte(command, 0); i >= 0 {
+		command = command[:i]
+	}
+
+	response := ""
+	changed := make([]string, 0)
+	events := make([]Event, 0)
+	switch {
+	case command == "MF.Ping":
+		response = "MF.Pong"
+	case strings.HasPrefix(command, "MF.Clients.Add.") && client == "MobiFlight":
+		name := strings.TrimPrefix(command, "MF.Clients.Add.")
+		s.createClientData(name+".LVars", sim.SIMCONNECT_CLIENTDATA_MAX_SIZE)
+		s.createClientData(name+".Command", mobiFlightMessageSize)
+		s.createClientData(name+".Response", mobiFlightMessageSize)
+		s.mobiFlight.clients[name] = nil
+		response = command + ".Finished"
+	case command == "MF.SimVars.Clear":
+		s.mobiFlight.clients[client] = nil
+		lvars := s.clientData[client+".LVars"].data
+		copy(lvars, make([]byte, len(lvars)))
+	case strings.HasPrefix(command, "MF.SimVars.Add.(L:"):
+		name := strings.TrimSuffix(strings.TrimPrefix(command, "MF.SimVars.Add.(L:"), ")")
+		s.mobiFlight.clients[client] = append(s.mobiFlight.clients[client], name)
+		changed = s.refreshLVars()
+	case strings.HasPrefix(command, "MF.SimVars.Set."):
+		events, changed = s.execute(strings.TrimPrefix(command, "MF.SimVars.Set."))
+	}
+	if response != "" {
+		s.response(client, response)
+		changed = append(changed, client+".Response")
+	}
+	s.mu.Unlock()
+	for _, e := range events {
+		s.transmit(e.Name, e.Data)
+	}
+	for _, area := range changed {
+		s.clientDataSet(area)
+	}
+}
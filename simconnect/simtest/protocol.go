@@ -0,0 +1,201 @@
+package simtest
+
+import (
+	"encoding/binary"
+	"math"
+
+	sim "github.com/flysim-apps/simgo/simconnect"
+)
+
+// SimConnect wire protocol seen from the simulator side
+const (
+	protocolVersion = 5
+	headerSize      = 16
+	packetMask      = 0xF0000000
+	maxPacketSize   = 1 << 24
+	userObjectID    = 1
+)
+
+// Client packet types
+const (
+	packetOpen                                      = 0x01
+	packetMapClientEventToSimEvent                  = 0x04
+	packetTransmitClientEvent                       = 0x05
+	packetAddClientEventToNotificationGroup         = 0x07
+	packetAddToDataDefinition                       = 0x0C
+	packetClearDataDefinition                       = 0x0D
+	packetRequestDataOnSimObject                    = 0x0E
+	packetRequestDataOnSimObjectType                = 0x0F
+	packetSetDataOnSimObject                        = 0x10
+	packetSubscribeToSystemEvent                    = 0x17
+	packetUnsubscribeFromSystemEvent                = 0x18
+	packetWeatherRequestInterpolatedObservation     = 0x19
+	packetWeatherRequestObservationAtStation        = 0x1A
+	packetWeatherRequestObservationAtNearestStation = 0x1B
+	packetAICreateParkedATCAircraft                 = 0x27
+	packetAICreateEnrouteATCAircraft                = 0x28
+	packetAICreateNonATCAircraft                    = 0x29
+	packetAICreateSimulatedObject                   = 0x2A
+	packetAIReleaseControl                          = 0x2B
+	packetAIRemoveObject                            = 0x2C
+	packetAISetAircraftFlightPlan                   = 0x2D
+	packetMenuAddItem                               = 0x31
+	packetMenuDeleteItem                            = 0x32
+	packetMenuAddSubItem                            = 0x33
+	packetMenuDeleteSubItem                         = 0x34
+	packetMapClientDataNameToID                     = 0x37
+	packetCreateClientData                          = 0x38
+	packetAddToClientDataDefinition                 = 0x39
+	packetClearClientDataDefinition                 = 0x3A
+	packetRequestClientData                         = 0x3B
+	packetSetClientData                             = 0x3C
+	packetText                                      = 0x40
+	packetSubscribeToFacilities                     = 0x41
+	packetUnsubscribeToFacilities                   = 0x42
+	packetRequestFacilitiesList                     = 0x43
+	packetAddToFacilityDefinition                   = 0x45
+	packetRequestFacilityData                       = 0x46
+	packetEnumerateInputEvents                      = 0x4F
+	packetGetInputEvent                             = 0x50
+	packetSetInputEvent                             = 0x51
+	packetSubscribeInputEvent                       = 0x52
+	packetUnsubscribeInputEvent                     = 0x53
+	packetEnumerateInputEventParams                 = 0x54
+)
+
+// reader decode the body of a client packet
+type reader struct {
+	buf []byte
+	pos int
+}
+
+func (r *reader) uint32() uint32 {
+	if r.pos+4 > len(r.buf) {
+		r.pos = len(r.buf)
+		return 0
+	}
+	v := binary.LittleEndian.Uint32(r.buf[r.pos:])
+	r.pos += 4
+	return v
+}
+
+func (r *reader) float32() float32 {
+	return math.Float32frombits(r.uint32())
+}
+
+func (r *reader) uint64() uint64 {
+	if r.pos+8 > len(r.buf) {
+		r.pos = len(r.buf)
+		return 0
+	}
+	v := binary.LittleEndian.Uint64(r.buf[r.pos:])
+	r.pos += 8
+	return v
+}
+
+func (r *reader) float64() float64 {
+	return math.Float64frombits(r.uint64())
+}
+
+func (r *reader) initPosition() sim.SIMCONNECT_DATA_INITPOSITION {
+	return sim.SIMCONNECT_DATA_INITPOSITION{
+		Latitude:  r.float64(),
+		Longitude: r.float64(),
+		Altitude:  r.float64(),
+		Pitch:     r.float64(),
+		Bank:      r.float64(),
+		Heading:   r.float64(),
+		OnGround:  r.uint32(),
+		Airspeed:  r.uint32(),
+	}
+}
+
+func (r *reader) string(size int) string {
+	if r.pos+size > len(r.buf) {
+		size = len(r.buf) - r.pos
+	}
+	b := r.buf[r.pos : r.pos+size]
+	r.pos += size
+	for i, c := range b {
+		if c == 0 {
+			return string(b[:i])
+		}
+	}
+	return string(b)
+}
+
+func (r *reader) bytes() []byte {
+	b := r.buf[r.pos:]
+	r.pos = len(r.buf)
+	return b
+}
+
+// writer build a SIMCONNECT_RECV message
+type writer struct {
+	buf []byte
+}
+
+func newWriter(id uint32) *writer {
+	w := &writer{buf: make([]byte, 12, 64)}
+	binary.LittleEndian.PutUint32(w.buf[4:], protocolVersion)
+	binary.LittleEndian.PutUint32(w.buf[8:], id)
+	return w
+}
+
+func (w *writer) uint32(v ...uint32) *writer {
+	for _, i := range v {
+		w.buf = binary.LittleEndian.AppendUint32(w.buf, i)
+	}
+	return w
+}
+
+func (w *writer) uint64(v ...uint64) *writer {
+	for _, i := range v {
+		w.buf = binary.LittleEndian.AppendUint64(w.buf, i)
+	}
+	return w
+}
+
+func (w *writer) string(s string, size int) *writer {
+	b := make([]byte, size)
+	copy(b[:size-1], s)
+	w.buf = append(w.buf, b...)
+	return w
+}
+
+func (w *writer) bytes(b []byte) *writer {
+	w.buf = append(w.buf, b...)
+	return w
+}
+
+func (w *writer) packet() []byte {
+	binary.LittleEndian.PutUint32(w.buf[0:], uint32(len(w.buf)))
+	return w.buf
+}
+
+// datumSize return the size in bytes of a SIMCONNECT_DATATYPE
+func datumSize(datumType uint32) int {
+	switch datumType {
+	case sim.SIMCONNECT_DATATYPE_INT32, sim.SIMCONNECT_DATATYPE_FLOAT32:
+		return 4
+	case sim.SIMCONNECT_DATATYPE_INT64, sim.SIMCONNECT_DATATYPE_FLOAT64, sim.SIMCONNECT_DATATYPE_STRING8:
+		return 8
+	case sim.SIMCONNECT_DATATYPE_STRING32:
+		return 32
+	case sim.SIMCONNECT_DATATYPE_STRING64:
+		return 64
+	case sim.SIMCONNECT_DATATYPE_STRING128:
+		return 128
+	case sim.SIMCONNECT_DATATYPE_STRING256:
+		return 256
+	case sim.SIMCONNECT_DATATYPE_STRING260:
+		return 260
+	case sim.SIMCONNECT_DATATYPE_LATLONALT, sim.SIMCONNECT_DATATYPE_XYZ:
+		return 24
+	case sim.SIMCONNECT_DATATYPE_WAYPOINT:
+		return 48
+	case sim.SIMCONNECT_DATATYPE_INITPOSITION:
+		return 56
+	}
+	return 8
+}
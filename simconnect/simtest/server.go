@@ -0,0 +1,757 @@
+// Package simtest provides an in-process flight simulator speaking the SimConnect network protocol.
+//
+// Point simconnect.NewEasySimConnectRemote or SimGo.SimConnectAddr to Server.Addr() to run
+// EasySimConnect and SimGo end-to-end without Microsoft Flight Simulator.
+//
+//	srv, _ := simtest.NewServer()
+//	defer srv.Close()
+//	srv.Aircraft.Set("PLANE ALTITUDE", 1500)
+//	esc := simconnect.NewEasySimConnectRemote(ctx, srv.Addr())
+package simtest
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"math"
+	"net"
+	"sync"
+	"time"
+
+	sim "github.com/flysim-apps/simgo/simconnect"
+)
+
+// Event is a client event transmitted to the simulator
+type Event struct {
+	Name string
+	Data uint32
+}
+
+// Server emulates a flight simulator. It serves the SimVars of Aircraft to every connected client
+type Server struct {
+	// Aircraft is the user aircraft, values can be changed at any time
+	Aircraft *Aircraft
+	// Strict answer SIMCONNECT_EXCEPTION_NAME_UNRECOGNIZED to data definitions of SimVars not set on Aircraft.
+	// Otherwise unknown SimVars read as zero
+	Strict bool
+	// Name is the application name returned on open
+	Name string
+	// Titles are the containers AI objects can be created from, any title is accepted when empty
+	Titles []string
+	// Frame is the duration of a frame for the SIM_FRAME and VISUAL_FRAME periods, 30 fps by default.
+	// Change it before clients connect
+	Frame time.Duration
+
+	listener net.Listener
+	mu       sync.Mutex
+	clients  map[*client]struct{}
+	running  bool
+	paused   bool
+	events   []Event
+	// objects are the AI and multiplayer objects by object ID
+	objects      map[uint32]*simObject
+	lastObjectID uint32
+	stations     []station
+	facilities   []sim.Facility
+	airports     map[string]*sim.FacilityAirportData
+	clientData   map[string]*clientArea
+	mobiFlight   *mobiFlight
+	inputEvents  []*inputEvent
+	wg           sync.WaitGroup
+}
+
+type datum struct {
+	name      string
+	datumType uint32
+	epsilon   float32
+	datumID   uint32
+}
+
+type client struct {
+	srv     *Server
+	conn    net.Conn
+	wmu     sync.Mutex
+	mu      sync.Mutex
+	defs    map[uint32][]datum
+	mapped  map[uint32]string
+	grouped map[uint32]uint32
+	system  map[uint32]string
+	// requests are the periodic data requests, closing the chan stops the request
+	requests map[uint32]chan struct{}
+	done     chan struct{}
+	// facilities are the facility subscriptions by list type, closing the chan stops the subscription
+	facilities map[uint32]chan struct{}
+	// facilityDefs are the fields of the facility definitions by define ID
+	facilityDefs map[uint32][]string
+	// clientDataIDs are the names of the client data areas by client data ID, clientDataDefs the client data
+	// definitions by define ID and clientDataRequests the periodic client data requests by request ID
+	clientDataIDs      map[uint32]string
+	clientDataDefs     map[uint32][]clientDatum
+	clientDataRequests map[uint32]*clientDataRequest
+	// inputEvents are the input event subscriptions by hash
+	inputEvents map[uint64]bool
+	// menu are the Add-ons menu items and sub items, in the order they were added
+	menu []*menuItem
+}
+
+// NewServer start a simulator listening on a random local port. The flight is running and not paused
+func NewServer() (*Server, error) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, err
+	}
+	s := &Server{
+		Aircraft: NewAircraft(),
+		Name:     "KittyHawk",
+		Frame:    time.Second / 30,
+		listener: l,
+		clients:  make(map[*client]struct{}),
+		running:  true,
+
+		objects:      make(map[uint32]*simObject),
+		airports:     make(map[string]*sim.FacilityAirportData),
+		clientData:   make(map[string]*clientArea),
+		lastObjectID: userObjectID,
+	}
+	s.wg.Add(1)
+	go s.serve()
+	return s, nil
+}
+
+// Addr return the address (host:port) clients connect to
+func (s *Server) Addr() string {
+	return s.listener.Addr().String()
+}
+
+// Close stop the simulator and disconnect every client without RECV_QUIT
+func (s *Server) Close() error {
+	err := s.listener.Close()
+	for _, c := range s.allClients() {
+		c.conn.Close()
+	}
+	s.wg.Wait()
+	return err
+}
+
+// Quit send RECV_QUIT to every client and disconnect them, like when the simulator exits
+func (s *Server) Quit() {
+	for _, c := range s.allClients() {
+		c.send(newWriter(sim.SIMCONNECT_RECV_ID_QUIT).packet())
+		c.conn.Close()
+	}
+}
+
+// Events return the client events transmitted since the server started
+func (s *Server) Events() []Event {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Event(nil), s.events...)
+}
+
+// Subscribers return the number of subscriptions to a system event, used to wait for a client to be ready
+func (s *Server) Subscribers(name sim.SystemEvent) int {
+	n := 0
+	for _, c := range s.allClients() {
+		n += len(c.subscribed(name))
+	}
+	return n
+}
+
+// DataRequests return the number of periodic data requests and of data definitions of the clients, used to
+// check they are released
+func (s *Server) DataRequests() (int, int) {
+	requests, defs := 0, 0
+	for _, c := range s.allClients() {
+		c.mu.Lock()
+		requests += len(c.requests)
+		defs += len(c.defs)
+		c.mu.Unlock()
+	}
+	return requests, defs
+}
+
+// SetRunning start or stop the flight and notify the Sim, SimStart and SimStop subscribers
+func (s *Server) SetRunning(running bool) {
+	s.mu.Lock()
+	s.running = running
+	s.mu.Unlock()
+	s.FireSystemEvent(sim.SystemEventSim, boolData(running))
+	if running {
+		s.FireSystemEvent(sim.SystemEventSimStart, 0)
+	} else {
+		s.FireSystemEvent(sim.SystemEventSimStop, 0)
+	}
+}
+
+// SetPaused pause or resume the flight and notify the Pause, Paused and Unpaused subscribers
+func (s *Server) SetPaused(paused bool) {
+	s.mu.Lock()
+	s.paused = paused
+	s.mu.Unlock()
+	s.FireSystemEvent(sim.SystemEventPause, boolData(paused))
+	if paused {
+		s.FireSystemEvent(sim.SystemEventPaused, 0)
+	} else {
+		s.FireSystemEvent(sim.SystemEventUnpaused, 0)
+	}
+}
+
+// Crash the user aircraft
+func (s *Server) Crash() {
+	s.FireSystemEvent(sim.SystemEventCrashed, 0)
+}
+
+// FireSystemEvent send a SIMCONNECT_RECV_EVENT to the subscribers of a system event
+func (s *Server) FireSystemEvent(name sim.SystemEvent, data uint32) {
+	for _, c := range s.allClients() {
+		for _, eventID := range c.subscribed(name) {
+			c.sendEvent(sim.SIMCONNECT_UNUSED, eventID, data)
+		}
+	}
+}
+
+// FireSystemEventFilename send a SIMCONNECT_RECV_EVENT_FILENAME to the subscribers of a system event
+// like AircraftLoaded or FlightLoaded
+func (s *Server) FireSystemEventFilename(name sim.SystemEvent, filename string) {
+	for _, c := range s.allClients() {
+		for _, eventID := range c.subscribed(name) {
+			w := newWriter(sim.SIMCONNECT_RECV_ID_EVENT_FILENAME).uint32(sim.SIMCONNECT_UNUSED, eventID, 0)
+			c.send(w.string(filename, sim.MAX_PATH).uint32(0).packet())
+		}
+	}
+}
+
+func boolData(b bool) uint32 {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+func (s *Server) allClients() []*client {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	clients := make([]*client, 0, len(s.clients))
+	for c := range s.clients {
+		clients = append(clients, c)
+	}
+	return clients
+}
+
+func (s *Server) serve() {
+	defer s.wg.Done()
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+		c := &client{
+			srv:     s,
+			conn:    conn,
+			defs:    make(map[uint32][]datum),
+			mapped:  make(map[uint32]string),
+			grouped: make(map[uint32]uint32),
+			system:  make(map[uint32]string),
+
+			requests:     make(map[uint32]chan struct{}),
+			done:         make(chan struct{}),
+			facilities:   make(map[uint32]chan struct{}),
+			facilityDefs: make(map[uint32][]string),
+
+			clientDataIDs:      make(map[uint32]string),
+			clientDataDefs:     make(map[uint32][]clientDatum),
+			clientDataRequests: make(map[uint32]*clientDataRequest),
+			inputEvents:        make(map[uint64]bool),
+		}
+		s.mu.Lock()
+		s.clients[c] = struct{}{}
+		s.mu.Unlock()
+		s.wg.Add(1)
+		go c.run()
+	}
+}
+
+func (c *client) run() {
+	defer c.srv.wg.Done()
+	defer func() {
+		c.srv.mu.Lock()
+		delete(c.srv.clients, c)
+		c.srv.mu.Unlock()
+		close(c.done)
+		c.conn.Close()
+	}()
+	header := make([]byte, headerSize)
+	for {
+		if _, err := io.ReadFull(c.conn, header); err != nil {
+			return
+		}
+		size := binary.LittleEndian.Uint32(header[0:])
+		if size < headerSize || size > maxPacketSize {
+			return
+		}
+		body := make([]byte, size-headerSize)
+		if _, err := io.ReadFull(c.conn, body); err != nil {
+			return
+		}
+		packetType := binary.LittleEndian.Uint32(header[8:]) &^ packetMask
+		sendID := binary.LittleEndian.Uint32(header[12:])
+		c.handle(packetType, sendID, &reader{buf: body})
+	}
+}
+
+func (c *client) send(buf []byte) {
+	c.wmu.Lock()
+	defer c.wmu.Unlock()
+	c.conn.Write(buf)
+}
+
+func (c *client) sendEvent(groupID, eventID, data uint32) {
+	c.send(newWriter(sim.SIMCONNECT_RECV_ID_EVENT).uint32(groupID, eventID, data).packet())
+}
+
+func (c *client) sendException(exception, sendID, index uint32) {
+	c.send(newWriter(sim.SIMCONNECT_RECV_ID_EXCEPTION).uint32(exception, sendID, index).packet())
+}
+
+func (c *client) subscribed(name sim.SystemEvent) []uint32 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	ids := make([]uint32, 0)
+	for id, n := range c.system {
+		if n == key(string(name)) {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+func (c *client) handle(packetType, sendID uint32, r *reader) {
+	switch packetType {
+	case packetOpen:
+		w := newWriter(sim.SIMCONNECT_RECV_ID_OPEN).string(c.srv.Name, 256)
+		c.send(w.uint32(11, 0, 62651, 3, 11, 0, 62651, 3, 0, 0).packet())
+	case packetMapClientEventToSimEvent:
+		eventID := r.uint32()
+		name := r.string(256)
+		c.mu.Lock()
+		c.mapped[eventID] = key(name)
+		c.mu.Unlock()
+	case packetAddClientEventToNotificationGroup:
+		groupID := r.uint32()
+		eventID := r.uint32()
+		c.mu.Lock()
+		c.grouped[eventID] = groupID
+		c.mu.Unlock()
+	case packetTransmitClientEvent:
+		r.uint32() // object ID
+		eventID := r.uint32()
+		data := r.uint32()
+		c.mu.Lock()
+		name, found := c.mapped[eventID]
+		c.mu.Unlock()
+		if !found {
+			c.sendException(sim.SIMCONNECT_EXCEPTION_UNRECOGNIZED_ID, sendID, 2)
+			return
+		}
+		c.srv.transmit(name, data)
+	case packetAddToDataDefinition:
+		defineID := r.uint32()
+		name := r.string(256)
+		r.string(256) // units are ignored
+		datumType := r.uint32()
+		epsilon := r.float32()
+		datumID := r.uint32()
+		if c.srv.Strict && !c.srv.Aircraft.Has(name) {
+			c.sendException(sim.SIMCONNECT_EXCEPTION_NAME_UNRECOGNIZED, sendID, 2)
+			return
+		}
+		c.mu.Lock()
+		c.defs[defineID] = append(c.defs[defineID], datum{name, datumType, epsilon, datumID})
+		c.mu.Unlock()
+	case packetClearDataDefinition:
+		defineID := r.uint32()
+		c.mu.Lock()
+		delete(c.defs, defineID)
+		c.mu.Unlock()
+	case packetRequestDataOnSimObject:
+		req := request{
+			requestID: r.uint32(),
+			defineID:  r.uint32(),
+			objectID:  r.uint32(),
+			period:    r.uint32(),
+			flags:     r.uint32(),
+			origin:    r.uint32(),
+			interval:  r.uint32(),
+			limit:     r.uint32(),
+		}
+		if req.objectID != sim.SIMCONNECT_OBJECT_ID_USER && req.objectID != userObjectID {
+			c.sendException(sim.SIMCONNECT_EXCEPTION_UNRECOGNIZED_ID, sendID, 3)
+			return
+		}
+		c.mu.Lock()
+		_, found := c.defs[req.defineID]
+		c.mu.Unlock()
+		if !found && req.period != sim.SIMCONNECT_PERIOD_NEVER {
+			c.sendException(sim.SIMCONNECT_EXCEPTION_UNRECOGNIZED_ID, sendID, 2)
+			return
+		}
+		c.request(req)
+	case packetRequestDataOnSimObjectType:
+		requestID := r.uint32()
+		defineID := r.uint32()
+		radius := r.uint32()
+		objectType := r.uint32()
+		c.mu.Lock()
+		_, found := c.defs[defineID]
+		c.mu.Unlock()
+		if !found {
+			c.sendException(sim.SIMCONNECT_EXCEPTION_UNRECOGNIZED_ID, sendID, 2)
+			return
+		}
+		objects := c.srv.objectsAround(objectType, radius)
+		if len(objects) == 0 {
+			w := newWriter(sim.SIMCONNECT_RECV_ID_SIMOBJECT_DATA_BYTYPE)
+			c.send(w.uint32(requestID, 0, defineID, 0, 0, 0, 0).packet())
+			return
+		}
+		for i, o := range objects {
+			e := entry{sim.SIMCONNECT_RECV_ID_SIMOBJECT_DATA_BYTYPE, requestID, o.objectID, defineID, 0, uint32(i + 1), uint32(len(objects))}
+			c.sendData(e, o.aircraft, nil)
+		}
+	case packetSetDataOnSimObject:
+		defineID := r.uint32()
+		objectID := r.uint32()
+		r.uint32() // flags
+		r.uint32() // array count
+		r.uint32() // unit size
+		data := r.bytes()
+		c.mu.Lock()
+		def, found := c.defs[defineID]
+		c.mu.Unlock()
+		if !found {
+			c.sendException(sim.SIMCONNECT_EXCEPTION_UNRECOGNIZED_ID, sendID, 1)
+			return
+		}
+		aircraft := c.srv.Aircraft
+		if objectID != sim.SIMCONNECT_OBJECT_ID_USER && objectID != userObjectID {
+			if aircraft, found = c.srv.Object(objectID); !found {
+				c.sendException(sim.SIMCONNECT_EXCEPTION_UNRECOGNIZED_ID, sendID, 2)
+				return
+			}
+		}
+		for _, d := range def {
+			size := datumSize(d.datumType)
+			if len(data) < size {
+				c.sendException(sim.SIMCONNECT_EXCEPTION_DATA_ERROR, sendID, 6)
+				return
+			}
+			aircraft.Set(d.name, decode(data[:size], d.datumType))
+			data = data[size:]
+		}
+	case packetSubscribeToSystemEvent:
+		eventID := r.uint32()
+		name := key(r.string(256))
+		c.mu.Lock()
+		c.system[eventID] = name
+		c.mu.Unlock()
+		c.srv.mu.Lock()
+		running, paused := c.srv.running, c.srv.paused
+		c.srv.mu.Unlock()
+		switch name {
+		case key(string(sim.SystemEventSim)):
+			c.sendEvent(sim.SIMCONNECT_UNUSED, eventID, boolData(running))
+		case key(string(sim.SystemEventPause)):
+			c.sendEvent(sim.SIMCONNECT_UNUSED, eventID, boolData(paused))
+		}
+	case packetUnsubscribeFromSystemEvent:
+		eventID := r.uint32()
+		c.mu.Lock()
+		delete(c.system, eventID)
+		c.mu.Unlock()
+	case packetWeatherRequestInterpolatedObservation:
+		requestID := r.uint32()
+		lat, lon := r.float32(), r.float32()
+		metar, found := c.srv.nearestStation(float64(lat), float64(lon))
+		c.observation(sendID, requestID, interpolated(metar), found)
+	case packetWeatherRequestObservationAtStation:
+		requestID := r.uint32()
+		metar, found := c.srv.stationAt(r.string(5))
+		c.observation(sendID, requestID, metar, found)
+	case packetWeatherRequestObservationAtNearestStation:
+		requestID := r.uint32()
+		lat, lon := r.float32(), r.float32()
+		metar, found := c.srv.nearestStation(float64(lat), float64(lon))
+		c.observation(sendID, requestID, metar, found)
+	case packetAICreateParkedATCAircraft:
+		title := r.string(256)
+		tail := r.string(12)
+		r.string(5) // aircraft are parked at the user position
+		c.create(sendID, r.uint32(), sim.SIMCONNECT_SIMOBJECT_TYPE_AIRCRAFT, title, tail, c.srv.userPosition())
+	case packetAICreateEnrouteATCAircraft:
+		title := r.string(256)
+		tail := r.string(12)
+		r.uint32()    // flight number
+		r.string(260) // flight plan
+		r.float64()   // flight plan position
+		r.uint32()    // touch and go
+		c.create(sendID, r.uint32(), sim.SIMCONNECT_SIMOBJECT_TYPE_AIRCRAFT, title, tail, c.srv.userPosition())
+	case packetAICreateNonATCAircraft:
+		title := r.string(256)
+		tail := r.string(12)
+		pos := r.initPosition()
+		c.create(sendID, r.uint32(), sim.SIMCONNECT_SIMOBJECT_TYPE_AIRCRAFT, title, tail, pos)
+	case packetAICreateSimulatedObject:
+		title := r.string(256)
+		pos := r.initPosition()
+		c.create(sendID, r.uint32(), sim.SIMCONNECT_SIMOBJECT_TYPE_GROUND, title, "", pos)
+	case packetAIReleaseControl, packetAISetAircraftFlightPlan:
+		if _, found := c.srv.Object(r.uint32()); !found {
+			c.sendException(sim.SIMCONNECT_EXCEPTION_UNRECOGNIZED_ID, sendID, 1)
+		}
+	case packetAIRemoveObject:
+		objectID := r.uint32()
+		if _, found := c.srv.Object(objectID); !found {
+			c.sendException(sim.SIMCONNECT_EXCEPTION_UNRECOGNIZED_ID, sendID, 1)
+			return
+		}
+		c.srv.RemoveObject(objectID)
+	case packetSubscribeToFacilities:
+		listType := r.uint32()
+		c.subscribeFacilities(listType, r.uint32())
+	case packetUnsubscribeToFacilities:
+		c.unsubscribeFacilities(r.uint32())
+	case packetRequestFacilitiesList:
+		listType := r.uint32()
+		if listType >= sim.SIMCONNECT_FACILITY_LIST_TYPE_COUNT {
+			c.sendException(sim.SIMCONNECT_EXCEPTION_OUT_OF_BOUNDS, sendID, 1)
+			return
+		}
+		c.sendFacilities(listType, r.uint32(), c.srv.facilitiesAround(listType))
+	case packetAddToFacilityDefinition:
+		defineID := r.uint32()
+		c.addToFacilityDefinition(sendID, defineID, r.string(256))
+	case packetRequestFacilityData:
+		defineID, requestID := r.uint32(), r.uint32()
+		icao := r.string(16)
+		r.string(16) // region
+		c.requestFacilityData(sendID, defineID, requestID, icao)
+	case packetMapClientDataNameToID:
+		name := r.string(256)
+		c.mapClientData(sendID, r.uint32(), name)
+	case packetCreateClientData:
+		clientDataID, size := r.uint32(), r.uint32()
+		c.createClientData(sendID, clientDataID, size, r.uint32())
+	case packetAddToClientDataDefinition:
+		defineID, offset, sizeOrType := r.uint32(), r.uint32(), r.uint32()
+		r.float32() // epsilon
+		c.addToClientDataDefinition(defineID, offset, sizeOrType, r.uint32())
+	case packetClearClientDataDefinition:
+		defineID := r.uint32()
+		c.mu.Lock()
+		delete(c.clientDataDefs, defineID)
+		c.mu.Unlock()
+	case packetRequestClientData:
+		clientDataID := r.uint32()
+		req := &clientDataRequest{
+			requestID: r.uint32(),
+			defineID:  r.uint32(),
+			period:    r.uint32(),
+			flags:     r.uint32(),
+			origin:    r.uint32(),
+			interval:  r.uint32(),
+			limit:     r.uint32(),
+		}
+		c.requestClientData(sendID, clientDataID, req)
+	case packetSetClientData:
+		clientDataID, defineID := r.uint32(), r.uint32()
+		r.uint32() // flags
+		r.uint32() // reserved
+		r.uint32() // unit size
+		c.setClientData(sendID, clientDataID, defineID, r.bytes())
+	case packetEnumerateInputEvents:
+		c.sendInputEvents(r.uint32())
+	case packetGetInputEvent:
+		requestID := r.uint32()
+		c.getInputEvent(sendID, requestID, r.uint64())
+	case packetSetInputEvent:
+		hash := r.uint64()
+		r.uint32() // value size
+		c.setInputEvent(sendID, hash, r.bytes())
+	case packetSubscribeInputEvent:
+		c.subscribeInputEvent(sendID, r.uint64(), true)
+	case packetUnsubscribeInputEvent:
+		c.subscribeInputEvent(sendID, r.uint64(), false)
+	case packetEnumerateInputEventParams:
+		c.inputEventParams(sendID, r.uint64())
+	case packetMenuAddItem:
+		text := r.string(256)
+		c.addMenuItem(sendID, &menuItem{text: text, eventID: r.uint32(), data: r.uint32(), parentID: sim.SIMCONNECT_UNUSED})
+	case packetMenuDeleteItem:
+		c.deleteMenuItem(sendID, sim.SIMCONNECT_UNUSED, r.uint32())
+	case packetMenuAddSubItem:
+		parentID := r.uint32()
+		text := r.string(256)
+		c.addMenuItem(sendID, &menuItem{text: text, eventID: r.uint32(), data: r.uint32(), parentID: parentID})
+	case packetMenuDeleteSubItem:
+		parentID := r.uint32()
+		c.deleteMenuItem(sendID, parentID, r.uint32())
+	case packetText:
+		r.uint32() // type
+		r.float32()
+		eventID := r.uint32()
+		c.sendEvent(sim.SIMCONNECT_UNUSED, eventID, sim.SIMCONNECT_TEXT_RESULT_DISPLAYED)
+	}
+}
+
+// transmit run the aircraft handler of a sim event and notify the clients having it in a notification group
+func (s *Server) transmit(name string, data uint32) {
+	s.mu.Lock()
+	s.events = append(s.events, Event{name, data})
+	s.mu.Unlock()
+	s.Aircraft.fire(name, data)
+	for _, c := range s.allClients() {
+		c.mu.Lock()
+		notify := make(map[uint32]uint32)
+		for eventID, n := range c.mapped {
+			if groupID, found := c.grouped[eventID]; found && n == name {
+				notify[eventID] = groupID
+			}
+		}
+		c.mu.Unlock()
+		for eventID, groupID := range notify {
+			c.sendEvent(groupID, eventID, data)
+		}
+	}
+}
+
+// request is a RequestDataOnSimObject
+type request struct {
+	requestID, defineID, objectID uint32
+	period, flags                 uint32
+	origin, interval, limit       uint32
+}
+
+// entry is the header of a SIMCONNECT_RECV_SIMOBJECT_DATA
+type entry struct {
+	recvID, requestID, objectID, defineID, flags, entry, outof uint32
+}
+
+// sendData send the current values of a definition, it return false when the definition is unknown or
+// nothing changed.
+// last holds the values sent by the request: with SIMCONNECT_DATA_REQUEST_FLAG_CHANGED nothing is sent until
+// a value moved by more than its epsilon, with SIMCONNECT_DATA_REQUEST_FLAG_TAGGED only those are sent
+func (c *client) sendData(e entry, a *Aircraft, last map[int][]byte) bool {
+	c.mu.Lock()
+	def, found := c.defs[e.defineID]
+	c.mu.Unlock()
+	if !found {
+		return false
+	}
+	values := make([][]byte, len(def))
+	changed := make([]int, 0, len(def))
+	for i, d := range def {
+		value, _ := a.Get(d.name)
+		values[i] = encode(value, d.datumType)
+		if prev, found := last[i]; !found || moved(prev, values[i], d) {
+			changed = append(changed, i)
+		}
+	}
+	if last != nil {
+		for _, i := range changed {
+			last[i] = values[i]
+		}
+	}
+	if e.flags&sim.SIMCONNECT_DATA_REQUEST_FLAG_CHANGED != 0 && len(changed) == 0 {
+		return false
+	}
+
+	w := newWriter(e.recvID)
+	if e.flags&sim.SIMCONNECT_DATA_REQUEST_FLAG_TAGGED == 0 {
+		w.uint32(e.requestID, e.objectID, e.defineID, e.flags, e.entry, e.outof, uint32(len(def)))
+		for _, b := range values {
+			w.bytes(b)
+		}
+		c.send(w.packet())
+		return true
+	}
+	if e.flags&sim.SIMCONNECT_DATA_REQUEST_FLAG_CHANGED == 0 {
+		changed = changed[:0]
+		for i := range def {
+			changed = append(changed, i)
+		}
+	}
+	w.uint32(e.requestID, e.objectID, e.defineID, e.flags, e.entry, e.outof, uint32(len(changed)))
+	for _, i := range changed {
+		w.uint32(def[i].datumID)
+		w.bytes(values[i])
+	}
+	c.send(w.packet())
+	return true
+}
+
+// moved return true when the value of d changed by more than its epsilon
+func moved(prev, value []byte, d datum) bool {
+	a, aok := decode(prev, d.datumType).(float64)
+	b, bok := decode(value, d.datumType).(float64)
+	if aok && bok {
+		return math.Abs(a-b) > float64(d.epsilon)
+	}
+	return !bytes.Equal(prev, value)
+}
+
+// request replace the request of the same ID and send the data at the first period and every interval+1
+// periods after origin periods
+func (c *client) request(req request) {
+	stop := make(chan struct{})
+	c.mu.Lock()
+	if prev, found := c.requests[req.requestID]; found {
+		close(prev)
+		delete(c.requests, req.requestID)
+	}
+	if req.period == sim.SIMCONNECT_PERIOD_NEVER {
+		c.mu.Unlock()
+		return
+	}
+	if req.period != sim.SIMCONNECT_PERIOD_ONCE {
+		c.requests[req.requestID] = stop
+	}
+	c.mu.Unlock()
+
+	last := make(map[int][]byte)
+	e := entry{sim.SIMCONNECT_RECV_ID_SIMOBJECT_DATA, req.requestID, userObjectID, req.defineID, req.flags, 1, 1}
+	send := func(n uint32) bool {
+		if n < req.origin || (n-req.origin)%(req.interval+1) != 0 {
+			return false
+		}
+		return c.sendData(e, c.srv.Aircraft, last)
+	}
+	if req.period == sim.SIMCONNECT_PERIOD_ONCE {
+		c.sendData(e, c.srv.Aircraft, last)
+		return
+	}
+	sent := uint32(0)
+	if send(0) {
+		sent++
+	}
+
+	period := c.srv.Frame
+	if req.period == sim.SIMCONNECT_PERIOD_SECOND {
+		period = time.Second
+	}
+	go func() {
+		ticker := time.NewTicker(period)
+		defer ticker.Stop()
+		for n := uint32(1); req.limit == 0 || sent < req.limit; n++ {
+			select {
+			case <-stop:
+				return
+			case <-c.done:
+				return
+			case <-ticker.C:
+			}
+			if send(n) {
+				sent++
+			}
+		}
+	}()
+}
@@ -0,0 +1,121 @@
+package simtest
+
+import (
+	"math"
+	"sort"
+
+	sim "github.com/flysim-apps/simgo/simconnect"
+)
+
+// maxRadius is the largest radius of RequestDataOnSimObjectType, in meters
+const maxRadius = 200000
+
+type simObject struct {
+	objectID   uint32
+	objectType uint32
+	aircraft   *Aircraft
+}
+
+// AddObject add an AI or multiplayer object of objectType (sim.SIMCONNECT_SIMOBJECT_TYPE_AIRCRAFT, _HELICOPTER,
+// _BOAT or _GROUND) and return its object ID. Its position is read from the "PLANE LATITUDE" and
+// "PLANE LONGITUDE" SimVars of a, in degrees, like the user aircraft
+func (s *Server) AddObject(objectType uint32, a *Aircraft) uint32 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.lastObjectID++
+	s.objects[s.lastObjectID] = &simObject{s.lastObjectID, objectType, a}
+	return s.lastObjectID
+}
+
+// Object return the aircraft of an object added with AddObject or created by a client
+func (s *Server) Object(objectID uint32) (*Aircraft, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	o, found := s.objects[objectID]
+	if !found {
+		return nil, false
+	}
+	return o.aircraft, true
+}
+
+// RemoveObject remove an object added with AddObject
+func (s *Server) RemoveObject(objectID uint32) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.objects, objectID)
+}
+
+// objectsAround return the objects of objectType within radius meters of the user aircraft ordered by object ID.
+// Only the user aircraft is returned when radius is zero or objectType is SIMCONNECT_SIMOBJECT_TYPE_USER
+func (s *Server) objectsAround(objectType, radius uint32) []*simObject {
+	user := &simObject{userObjectID, sim.SIMCONNECT_SIMOBJECT_TYPE_AIRCRAFT, s.Aircraft}
+	if objectType == sim.SIMCONNECT_SIMOBJECT_TYPE_USER || radius == 0 {
+		return []*simObject{user}
+	}
+	if radius > maxRadius {
+		radius = maxRadius
+	}
+	objects := make([]*simObject, 0)
+	if objectType == sim.SIMCONNECT_SIMOBJECT_TYPE_ALL || objectType == sim.SIMCONNECT_SIMOBJECT_TYPE_AIRCRAFT {
+		objects = append(objects, user)
+	}
+	s.mu.Lock()
+	for _, o := range s.objects {
+		if objectType != sim.SIMCONNECT_SIMOBJECT_TYPE_ALL && objectType != o.objectType {
+			continue
+		}
+		if distance(s.Aircraft, o.aircraft) <= float64(radius) {
+			objects = append(objects, o)
+		}
+	}
+	s.mu.Unlock()
+	sort.Slice(objects, func(i, j int) bool { return objects[i].objectID < objects[j].objectID })
+	return objects
+}
+
+// distance return the great circle distance between two aircraft in meters
+func distance(a, b *Aircraft) float64 {
+	const earthRadius = 6371000
+	rad := func(a *Aircraft, name string) float64 { return a.Float64(name) * math.Pi / 180 }
+	lat1, lon1 := rad(a, "PLANE LATITUDE"), rad(a, "PLANE LONGITUDE")
+	lat2, lon2 := rad(b, "PLANE LATITUDE"), rad(b, "PLANE LONGITUDE")
+	h := math.Pow(math.Sin((lat2-lat1)/2), 2) + math.Cos(lat1)*math.Cos(lat2)*math.Pow(math.Sin((lon2-lon1)/2), 2)
+	return 2 * earthRadius * math.Asin(math.Sqrt(h))
+}
+
+// userPosition return the position of the user aircraft
+func (s *Server) userPosition() sim.SIMCONNECT_DATA_INITPOSITION {
+	return sim.SIMCONNECT_DATA_INITPOSITION{
+		Latitude:  s.Aircraft.Float64("PLANE LATITUDE"),
+		Longitude: s.Aircraft.Float64("PLANE LONGITUDE"),
+		Altitude:  s.Aircraft.Float64("PLANE ALTITUDE"),
+		Heading:   s.Aircraft.Float64("PLANE HEADING DEGREES TRUE"),
+		OnGround:  1,
+	}
+}
+
+// create an AI object and send its object ID, SIMCONNECT_EXCEPTION_CREATE_OBJECT_FAILED is sent when title is
+// not one of Server.Titles
+func (c *client) create(sendID, requestID, objectType uint32, title, tailNumber string, pos sim.SIMCONNECT_DATA_INITPOSITION) {
+	known := len(c.srv.Titles) == 0
+	for _, t := range c.srv.Titles {
+		known = known || t == title
+	}
+	if !known {
+		c.sendException(sim.SIMCONNECT_EXCEPTION_CREATE_OBJECT_FAILED, sendID, 1)
+		return
+	}
+	a := NewAircraft()
+	a.Set("TITLE", title)
+	a.Set("ATC ID", tailNumber)
+	a.Set("PLANE LATITUDE", pos.Latitude)
+	a.Set("PLANE LONGITUDE", pos.Longitude)
+	a.Set("PLANE ALTITUDE", pos.Altitude)
+	a.Set("PLANE PITCH DEGREES", pos.Pitch)
+	a.Set("PLANE BANK DEGREES", pos.Bank)
+	a.Set("PLANE HEADING DEGREES TRUE", pos.Heading)
+	a.Set("SIM ON GROUND", pos.OnGround)
+	a.Set("AIRSPEED TRUE", pos.Airspeed)
+	objectID := c.srv.AddObject(objectType, a)
+	c.send(newWriter(sim.SIMCONNECT_RECV_ID_ASSIGNED_OBJECT_ID).uint32(requestID, objectID).packet())
+}
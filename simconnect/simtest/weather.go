@@ -0,0 +1,70 @@
+package simtest
+
+import (
+	"strings"
+
+	sim "github.com/flysim-apps/simgo/simconnect"
+)
+
+type station struct {
+	icao     string
+	position *Aircraft
+	metar    string
+}
+
+// AddStation add a weather station at lat, lon in degrees observing metar. It answers the observation requests
+// at its ICAO code and the nearest station requests, the interpolated requests get the METAR of the nearest
+// station with the GLOB station code
+func (s *Server) AddStation(icao string, lat, lon float64, metar string) {
+	position := NewAircraft()
+	position.Set("PLANE LATITUDE", lat)
+	position.Set("PLANE LONGITUDE", lon)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.stations = append(s.stations, station{icao, position, metar})
+}
+
+// stationAt return the METAR of the station icao
+func (s *Server) stationAt(icao string) (string, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, st := range s.stations {
+		if st.icao == icao {
+			return st.metar, true
+		}
+	}
+	return "", false
+}
+
+// nearestStation return the METAR of the station nearest to lat, lon in degrees
+func (s *Server) nearestStation(lat, lon float64) (string, bool) {
+	position := NewAircraft()
+	position.Set("PLANE LATITUDE", lat)
+	position.Set("PLANE LONGITUDE", lon)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	metar, found, nearest := "", false, 0.0
+	for _, st := range s.stations {
+		if d := distance(position, st.position); !found || d < nearest {
+			metar, found, nearest = st.metar, true, d
+		}
+	}
+	return metar, found
+}
+
+// observation send a METAR, SIMCONNECT_EXCEPTION_WEATHER_UNABLE_TO_GET_OBSERVATION is sent without station
+func (c *client) observation(sendID, requestID uint32, metar string, found bool) {
+	if !found {
+		c.sendException(sim.SIMCONNECT_EXCEPTION_WEATHER_UNABLE_TO_GET_OBSERVATION, sendID, 1)
+		return
+	}
+	c.send(newWriter(sim.SIMCONNECT_RECV_ID_WEATHER_OBSERVATION).uint32(requestID).string(metar, len(metar)+1).packet())
+}
+
+// interpolated replace the station of metar by GLOB
+func interpolated(metar string) string {
+	if i := strings.IndexByte(metar, ' '); i >= 0 {
+		return "GLOB" + metar[i:]
+	}
+	return metar
+}
@@ -101,7 +101,9 @@ type SimVar struct {
 	Unit     SimVarUnit
 	Settable bool
 	Index    int
-	data     []byte
+	// Epsilon is the change needed before the SimVar is sent again with SIMCONNECT_DATA_REQUEST_FLAG_CHANGED
+	Epsilon float32
+	data    []byte
 }
 
 func (s *SimVar) getUnitForDataDefinition() string {
@@ -229,6 +229,23 @@ type SIMCONNECT_RECV_VOR_LIST struct {
 	rgData [1]SIMCONNECT_DATA_FACILITY_VOR
 }
 
+type SIMCONNECT_RECV_FACILITY_DATA struct {
+	SIMCONNECT_RECV
+	UserRequestId         uint32
+	UniqueRequestId       uint32 // ID of this object, the ParentUniqueRequestId of its children
+	ParentUniqueRequestId uint32
+	Type                  uint32 // SIMCONNECT_FACILITY_DATA_TYPE
+	IsListItem            uint32
+	ItemIndex             uint32
+	ListSize              uint32
+	Data                  [1]byte // fields of the facility definition of this object type
+}
+
+type SIMCONNECT_RECV_FACILITY_DATA_END struct {
+	SIMCONNECT_RECV
+	RequestId uint32
+}
+
 type SIMCONNECT_DATA_INITPOSITION struct {
 	Latitude  float64 // degrees
 	Longitude float64 // degrees
@@ -0,0 +1,47 @@
+package simconnect
+
+import (
+	"context"
+	"fmt"
+)
+
+// Subscribe return a chan receiving a T each time its SimVars are updated. The data definition is derived from the
+// sim and simUnit tags of T, every call has its own definition and chan. When ctx is done the data request is
+// stopped, the definition cleared and the chan closed
+func Subscribe[T any](ctx context.Context, esc *EasySimConnect) (<-chan T, error) {
+	var report T
+	simVars, err := SimVarGenerator(report)
+	if err != nil {
+		return nil, err
+	}
+	if len(simVars) == 0 {
+		return nil, fmt.Errorf("%T has no sim tag", report)
+	}
+	req, throttle := esc.requestEvery(esc.updateDelay())
+	defineID, cSimVar, err := esc.connectToSimVar(req, throttle, simVars)
+	if err != nil {
+		return nil, err
+	}
+	c := make(chan T)
+	go func() {
+		defer close(c)
+		defer esc.removeDefinition(defineID)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case sv := <-cSimVar:
+				v, ok := SimVarAssignInterface(report, sv).(T)
+				if !ok {
+					continue
+				}
+				select {
+				case c <- v:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+	return c, nil
+}
@@ -1,7 +1,10 @@
+//go:build windows
+
 package simconnect
 
 import (
 	"errors"
+	"fmt"
 	"syscall"
 )
 
@@ -79,6 +82,26 @@ type SyscallSC struct {
 	pSubscribeToFacilities                     *syscall.Proc
 	pUnsubscribeToFacilities                   *syscall.Proc
 	pRequestFacilitiesList                     *syscall.Proc
+	pAddToFacilityDefinition                   *syscall.Proc
+	pRequestFacilityData                       *syscall.Proc
+	pEnumerateInputEvents                      *syscall.Proc
+	pGetInputEvent                             *syscall.Proc
+	pSetInputEvent                             *syscall.Proc
+	pSubscribeInputEvent                       *syscall.Proc
+	pUnsubscribeInputEvent                     *syscall.Proc
+	pEnumerateInputEventParams                 *syscall.Proc
+}
+
+// errUnsupportedProc is returned by the calls missing in the loaded SimConnect.dll
+var errUnsupportedProc = fmt.Errorf("%w: SimConnect.dll is too old", ErrTransportUnsupported)
+
+// optionalProc return the proc of a call introduced by a recent SDK, nil when the SimConnect.dll does not export it
+func optionalProc(simDLL *syscall.DLL, name string) *syscall.Proc {
+	proc, err := simDLL.FindProc(name)
+	if err != nil {
+		return nil
+	}
+	return proc
 }
 
 // NewsyscallSC.pinit all syscall
@@ -376,6 +399,21 @@ func NewSyscallSC() (*SyscallSC, error) {
 	if err != nil {
 		return nil, err
 	}
+	syscallSC.pAddToFacilityDefinition, err = simDLL.FindProc("SimConnect_AddToFacilityDefinition")
+	if err != nil {
+		return nil, err
+	}
+	syscallSC.pRequestFacilityData, err = simDLL.FindProc("SimConnect_RequestFacilityData")
+	if err != nil {
+		return nil, err
+	}
+	// the input events came with MSFS SU13, older SimConnect.dll do not export them
+	syscallSC.pEnumerateInputEvents = optionalProc(simDLL, "SimConnect_EnumerateInputEvents")
+	syscallSC.pGetInputEvent = optionalProc(simDLL, "SimConnect_GetInputEvent")
+	syscallSC.pSetInputEvent = optionalProc(simDLL, "SimConnect_SetInputEvent")
+	syscallSC.pSubscribeInputEvent = optionalProc(simDLL, "SimConnect_SubscribeInputEvent")
+	syscallSC.pUnsubscribeInputEvent = optionalProc(simDLL, "SimConnect_UnsubscribeInputEvent")
+	syscallSC.pEnumerateInputEventParams = optionalProc(simDLL, "SimConnect_EnumerateInputEventParams")
 	return syscallSC, nil
 }
 func (syscallSC *SyscallSC) MapClientEventToSimEvent(hSimConnect uintptr, EventID uintptr, EventName uintptr) error {
@@ -953,3 +991,85 @@ func (syscallSC *SyscallSC) RequestFacilitiesList(hSimConnect uintptr, t uintptr
 
 	return nil
 }
+func (syscallSC *SyscallSC) AddToFacilityDefinition(hSimConnect uintptr, DefineID uintptr, FieldName uintptr) error {
+	r1, _, _ := syscallSC.pAddToFacilityDefinition.Call(hSimConnect, DefineID, FieldName)
+	if r1 != 0 {
+		return errors.New("r1 error")
+	}
+
+	return nil
+}
+func (syscallSC *SyscallSC) RequestFacilityData(hSimConnect uintptr, DefineID uintptr, RequestID uintptr, ICAO uintptr, Region uintptr) error {
+	r1, _, _ := syscallSC.pRequestFacilityData.Call(hSimConnect, DefineID, RequestID, ICAO, Region)
+	if r1 != 0 {
+		return errors.New("r1 error")
+	}
+
+	return nil
+}
+func (syscallSC *SyscallSC) EnumerateInputEvents(hSimConnect uintptr, RequestID uintptr) error {
+	if syscallSC.pEnumerateInputEvents == nil {
+		return errUnsupportedProc
+	}
+	r1, _, _ := syscallSC.pEnumerateInputEvents.Call(hSimConnect, RequestID)
+	if r1 != 0 {
+		return errors.New("r1 error")
+	}
+
+	return nil
+}
+func (syscallSC *SyscallSC) GetInputEvent(hSimConnect uintptr, RequestID uintptr, Hash uintptr) error {
+	if syscallSC.pGetInputEvent == nil {
+		return errUnsupportedProc
+	}
+	r1, _, _ := syscallSC.pGetInputEvent.Call(hSimConnect, RequestID, Hash)
+	if r1 != 0 {
+		return errors.New("r1 error")
+	}
+
+	return nil
+}
+func (syscallSC *SyscallSC) SetInputEvent(hSimConnect uintptr, Hash uintptr, cbUnitSize uintptr, Value uintptr) error {
+	if syscallSC.pSetInputEvent == nil {
+		return errUnsupportedProc
+	}
+	r1, _, _ := syscallSC.pSetInputEvent.Call(hSimConnect, Hash, cbUnitSize, Value)
+	if r1 != 0 {
+		return errors.New("r1 error")
+	}
+
+	return nil
+}
+func (syscallSC *SyscallSC) SubscribeInputEvent(hSimConnect uintptr, Hash uintptr) error {
+	if syscallSC.pSubscribeInputEvent == nil {
+		return errUnsupportedProc
+	}
+	r1, _, _ := syscallSC.pSubscribeInputEvent.Call(hSimConnect, Hash)
+	if r1 != 0 {
+		return errors.New("r1 error")
+	}
+
+	return nil
+}
+func (syscallSC *SyscallSC) UnsubscribeInputEvent(hSimConnect uintptr, Hash uintptr) error {
+	if syscallSC.pUnsubscribeInputEvent == nil {
+		return errUnsupportedProc
+	}
+	r1, _, _ := syscallSC.pUnsubscribeInputEvent.Call(hSimConnect, Hash)
+	if r1 != 0 {
+		return errors.New("r1 error")
+	}
+
+	return nil
+}
+func (syscallSC *SyscallSC) EnumerateInputEventParams(hSimConnect uintptr, Hash uintptr) error {
+	if syscallSC.pEnumerateInputEventParams == nil {
+		return errUnsupportedProc
+	}
+	r1, _, _ := syscallSC.pEnumerateInputEventParams.Call(hSimConnect, Hash)
+	if r1 != 0 {
+		return errors.New("r1 error")
+	}
+
+	return nil
+}
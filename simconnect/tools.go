@@ -1,6 +1,7 @@
 package simconnect
 
 import (
+	"encoding/binary"
 	"errors"
 	"reflect"
 	"strconv"
@@ -36,6 +37,26 @@ func convCBytesToGoBytes(ptr unsafe.Pointer, size int) ([]byte, error) {
 	return buf, nil
 }
 
+// convBytesToSimObjectData read the header of SIMCONNECT_RECV_SIMOBJECT_DATA, the datums start at dwData
+func convBytesToSimObjectData(buf []byte) SIMCONNECT_RECV_SIMOBJECT_DATA {
+	var recv SIMCONNECT_RECV_SIMOBJECT_DATA
+	if len(buf) < int(unsafe.Offsetof(recv.dwData)) {
+		return recv
+	}
+	le := binary.LittleEndian
+	recv.dwSize = le.Uint32(buf[0:])
+	recv.dwVersuib = le.Uint32(buf[4:])
+	recv.dwID = le.Uint32(buf[8:])
+	recv.dwRequestID = le.Uint32(buf[12:])
+	recv.dwObjectID = le.Uint32(buf[16:])
+	recv.dwDefineID = le.Uint32(buf[20:])
+	recv.dwFlags = le.Uint32(buf[24:])
+	recv.dwentrynumber = le.Uint32(buf[28:])
+	recv.dwoutof = le.Uint32(buf[32:])
+	recv.dwDefineCount = le.Uint32(buf[36:])
+	return recv
+}
+
 func getByVarName(name string, listSimVar []SimVar) *SimVar {
 	if strings.Contains(name, ":") {
 		name = strings.Split(name, ":")[0]
@@ -94,6 +115,13 @@ func SimVarGenerator(iFace interface{}) ([]SimVar, error) {
 			Unit:  unit,
 			Index: index,
 		}
+		if epsilon := f.Tag.Get("epsilon"); epsilon != "" {
+			e, err := strconv.ParseFloat(epsilon, 32)
+			if err != nil {
+				return nil, err
+			}
+			simVar.Epsilon = float32(e)
+		}
 		simVars = append(simVars, simVar)
 	}
 	return simVars, nil
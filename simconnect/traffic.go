@@ -0,0 +1,186 @@
+package simconnect
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// TrafficObject is a sim object of the traffic and its SimVars
+type TrafficObject struct {
+	ObjectID uint32
+	SimVars  []SimVar
+}
+
+// TrafficDiff is the change of the traffic since the previous snapshot
+type TrafficDiff struct {
+	Added   []TrafficObject
+	Updated []TrafficObject
+	Removed []uint32
+	// Snapshot is every object within the radius by object ID
+	Snapshot map[uint32]TrafficObject
+}
+
+// traffic assemble the entries of the RequestDataOnSimObjectType replies into snapshots
+type traffic struct {
+	c        chan TrafficDiff
+	snapshot map[uint32]TrafficObject
+	pending  map[uint32]TrafficObject
+	started  bool
+	// known is the snapshot of the last diff read from c, sent the one of the last diff sent on c
+	known  map[uint32]TrafficObject
+	sent   map[uint32]TrafficObject
+	mu     sync.Mutex
+	closed bool
+}
+
+// add an entry of a reply. The diff with the previous snapshot is returned after the last entry,
+// nil when nothing changed
+func (t *traffic) add(objectID, entry, outof uint32, simVars []SimVar) *TrafficDiff {
+	if entry <= 1 || t.pending == nil {
+		t.pending = make(map[uint32]TrafficObject)
+	}
+	if outof > 0 {
+		t.pending[objectID] = TrafficObject{objectID, simVars}
+	}
+	if entry < outof {
+		return nil
+	}
+
+	diff := diffTraffic(t.snapshot, t.pending)
+	t.snapshot, t.pending = t.pending, nil
+
+	if t.started && diff.empty() {
+		return nil
+	}
+	t.started = true
+	return diff
+}
+
+// send diff on c without blocking the dispatch, nil is ignored. A diff not read yet is replaced by the changes
+// since the last diff read
+func (t *traffic) send(diff *TrafficDiff) {
+	if diff == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.closed {
+		return
+	}
+	select {
+	case <-t.c:
+		diff = diffTraffic(t.known, diff.Snapshot)
+		if t.known != nil && diff.empty() {
+			// back to the objects already read
+			t.sent = t.known
+			return
+		}
+	default:
+		t.known = t.sent
+	}
+	t.c <- *diff
+	t.sent = diff.Snapshot
+}
+
+func (t *traffic) close() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.closed = true
+	close(t.c)
+}
+
+// diffTraffic return the objects of to added, updated or removed since from
+func diffTraffic(from, to map[uint32]TrafficObject) *TrafficDiff {
+	diff := &TrafficDiff{Snapshot: to}
+	for _, id := range sortedObjectIDs(to) {
+		prev, found := from[id]
+		switch {
+		case !found:
+			diff.Added = append(diff.Added, to[id])
+		case !sameSimVars(prev.SimVars, to[id].SimVars):
+			diff.Updated = append(diff.Updated, to[id])
+		}
+	}
+	for _, id := range sortedObjectIDs(from) {
+		if _, found := to[id]; !found {
+			diff.Removed = append(diff.Removed, id)
+		}
+	}
+	return diff
+}
+
+func (d *TrafficDiff) empty() bool {
+	return len(d.Added)+len(d.Updated)+len(d.Removed) == 0
+}
+
+func sortedObjectIDs(objects map[uint32]TrafficObject) []uint32 {
+	ids := make([]uint32, 0, len(objects))
+	for id := range objects {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	return ids
+}
+
+func sameSimVars(a, b []SimVar) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if !bytes.Equal(a[i].data, b[i].data) {
+			return false
+		}
+	}
+	return true
+}
+
+// ConnectToTraffic return a chan receiving the changes of the objects of objectType (SIMCONNECT_SIMOBJECT_TYPE_AIRCRAFT,
+// _HELICOPTER, _BOAT, _GROUND or _ALL) within radius meters of the user aircraft, the user aircraft is part of the
+// aircraft. The objects are requested every interval, the delay of SetDelay when zero. The first TrafficDiff
+// adds every object, then a TrafficDiff is sent when an object is added, updated or removed. A diff not read
+// before the next one is merged into it, Snapshot always holds every object. When ctx is done the objects are no
+// longer requested, the definition is cleared and the chan closed
+func (esc *EasySimConnect) ConnectToTraffic(ctx context.Context, objectType uint32, radius uint32, interval time.Duration, listSimVar ...SimVar) (<-chan TrafficDiff, error) {
+	if interval <= 0 {
+		interval = esc.updateDelay()
+	}
+	t := &traffic{c: make(chan TrafficDiff, 1)}
+	defineID, err := esc.addDefinition(listSimVar, 0, t)
+	if err != nil {
+		return nil, err
+	}
+	request := func() (error, uint32) {
+		return esc.sc.RequestDataOnSimObjectType(defineID, defineID, radius, objectType)
+	}
+	if err := esc.expect(request); err != nil {
+		esc.removeDefinition(defineID)
+		return nil, fmt.Errorf("Error in RequestDataOnSimObjectType : %#v", err)
+	}
+	go func() {
+		defer t.close()
+		defer esc.removeDefinition(defineID)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-esc.ctx.Done():
+				return
+			case <-ticker.C:
+			}
+			if !esc.alive.Load() {
+				return
+			}
+			if err := esc.expect(request); err != nil {
+				esc.logf(LogInfo, "Traffic request stopped : %#v", err)
+				return
+			}
+		}
+	}()
+	return t.c, nil
+}
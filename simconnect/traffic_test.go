@@ -0,0 +1,56 @@
+package simconnect
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTrafficSendMerge(t *testing.T) {
+	tr := &traffic{c: make(chan TrafficDiff, 1)}
+	value := func(b byte) []SimVar { return []SimVar{{Name: "PLANE LATITUDE", data: []byte{b}}} }
+	receive := func() TrafficDiff {
+		t.Helper()
+		select {
+		case diff := <-tr.c:
+			return diff
+		default:
+			t.Fatal("no diff")
+		}
+		return TrafficDiff{}
+	}
+
+	tr.send(tr.add(1, 1, 2, value(1)))
+	tr.send(tr.add(2, 2, 2, value(1)))
+	diff := receive()
+	require.Len(t, diff.Added, 2)
+
+	// two diffs sent without reader are merged
+	tr.send(tr.add(1, 1, 1, value(2)))
+	tr.send(tr.add(1, 1, 2, value(2)))
+	tr.send(tr.add(3, 2, 2, value(1)))
+	diff = receive()
+	require.Len(t, diff.Added, 1)
+	assert.Equal(t, uint32(3), diff.Added[0].ObjectID)
+	require.Len(t, diff.Updated, 1)
+	assert.Equal(t, uint32(1), diff.Updated[0].ObjectID)
+	assert.Equal(t, []uint32{2}, diff.Removed)
+	assert.Len(t, diff.Snapshot, 2)
+
+	// a change undone before being read is not sent
+	tr.send(tr.add(1, 1, 1, value(3)))
+	tr.send(tr.add(1, 1, 2, value(2)))
+	tr.send(tr.add(3, 2, 2, value(1)))
+	assert.Empty(t, tr.c)
+	tr.send(tr.add(1, 1, 2, value(2)))
+	tr.send(tr.add(3, 2, 2, value(4)))
+	diff = receive()
+	require.Len(t, diff.Updated, 1)
+	assert.Equal(t, uint32(3), diff.Updated[0].ObjectID)
+
+	tr.close()
+	tr.send(&TrafficDiff{})
+	_, open := <-tr.c
+	assert.False(t, open)
+}
@@ -0,0 +1,107 @@
+package simconnect
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+)
+
+// ErrProviderUnavailable is returned when SimConnect.dll can not be used on this platform.
+// Use NewEasySimConnectRemote to reach the simulator with the network protocol instead.
+var ErrProviderUnavailable = errors.New("SimConnect.dll provider unavailable on this platform, use a remote SimConnect server")
+
+// ErrTransportUnsupported is returned by the calls of SimConnect whose optional interface is not implemented
+// by the transport
+var ErrTransportUnsupported = errors.New("unsupported by this transport")
+
+// Transport carries the SimConnect calls to the simulator with typed arguments.
+// DLLTransport (SimConnect.dll) and NetSC (SimConnect network protocol) are the implementations
+// of this package; tests can provide their own with NewSimConnectWithTransport.
+// The calls return the ID of the packet they sent, it is referenced by SIMCONNECT_RECV_EXCEPTION.dwSendID.
+// Transport is the core of SimConnect, the other features are optional interfaces like AITransport: a call
+// of SimConnect returns ErrTransportUnsupported when its transport does not implement them.
+type Transport interface {
+	// Open start the session, the answer SIMCONNECT_RECV_OPEN is returned by GetNextDispatch
+	Open(appTitle string) error
+	Close() error
+	// GetNextDispatch return the next received SIMCONNECT_RECV message or an error when there is none
+	GetNextDispatch() ([]byte, error)
+
+	MapClientEventToSimEvent(EventID uint32, EventName string) (uint32, error)
+	TransmitClientEvent(ObjectID uint32, EventID uint32, dwData uint32, GroupID uint32, Flags uint32) (uint32, error)
+	AddClientEventToNotificationGroup(GroupID uint32, EventID uint32, bMaskable bool) (uint32, error)
+	SetNotificationGroupPriority(GroupID uint32, uPriority uint32) (uint32, error)
+	SubscribeToSystemEvent(EventID uint32, SystemEventName string) (uint32, error)
+	MapInputEventToClientEvent(GroupID uint32, szInputDefinition string, DownEventID uint32, DownValue uint32, UpEventID uint32, UpValue uint32, bMaskable bool) (uint32, error)
+	SetInputGroupState(GroupID uint32, dwState uint32) (uint32, error)
+	AddToDataDefinition(DefineID uint32, DatumName string, UnitsName string, DatumType uint32, fEpsilon float32, DatumID uint32) (uint32, error)
+	ClearDataDefinition(DefineID uint32) (uint32, error)
+	RequestDataOnSimObject(RequestID uint32, DefineID uint32, ObjectID uint32, Period uint32, Flags uint32, origin uint32, interval uint32, limit uint32) (uint32, error)
+	RequestDataOnSimObjectType(RequestID uint32, DefineID uint32, dwRadiusMeters uint32, t uint32) (uint32, error)
+	SetDataOnSimObject(DefineID uint32, ObjectID uint32, Flags uint32, ArrayCount uint32, cbUnitSize uint32, pDataSet []byte) (uint32, error)
+	Text(t uint32, fTimeSeconds float32, EventID uint32, pDataSet []byte) (uint32, error)
+}
+
+// WeatherTransport is implemented by the transports requesting weather observations
+type WeatherTransport interface {
+	WeatherRequestInterpolatedObservation(RequestID uint32, lat float32, lon float32, alt float32) (uint32, error)
+	WeatherRequestObservationAtStation(RequestID uint32, szICAO string) (uint32, error)
+	WeatherRequestObservationAtNearestStation(RequestID uint32, lat float32, lon float32) (uint32, error)
+}
+
+// AITransport is implemented by the transports creating and controlling AI objects
+type AITransport interface {
+	AICreateParkedATCAircraft(szContainerTitle string, szTailNumber string, szAirportID string, RequestID uint32) (uint32, error)
+	AICreateEnrouteATCAircraft(szContainerTitle string, szTailNumber string, iFlightNumber int32, szFlightPlanPath string, dFlightPlanPosition float64, bTouchAndGo bool, RequestID uint32) (uint32, error)
+	AICreateNonATCAircraft(szContainerTitle string, szTailNumber string, InitPos SIMCONNECT_DATA_INITPOSITION, RequestID uint32) (uint32, error)
+	AICreateSimulatedObject(szContainerTitle string, InitPos SIMCONNECT_DATA_INITPOSITION, RequestID uint32) (uint32, error)
+	AIReleaseControl(ObjectID uint32, RequestID uint32) (uint32, error)
+	AIRemoveObject(ObjectID uint32, RequestID uint32) (uint32, error)
+	AISetAircraftFlightPlan(ObjectID uint32, szFlightPlanPath string, RequestID uint32) (uint32, error)
+}
+
+// FacilitiesTransport is implemented by the transports requesting facility lists and facility data
+type FacilitiesTransport interface {
+	SubscribeToFacilities(t uint32, RequestID uint32) (uint32, error)
+	UnsubscribeToFacilities(t uint32) (uint32, error)
+	RequestFacilitiesList(t uint32, RequestID uint32) (uint32, error)
+	AddToFacilityDefinition(DefineID uint32, FieldName string) (uint32, error)
+	RequestFacilityData(DefineID uint32, RequestID uint32, ICAO string, Region string) (uint32, error)
+}
+
+// ClientDataTransport is implemented by the transports sharing client data areas
+type ClientDataTransport interface {
+	MapClientDataNameToID(szClientDataName string, ClientDataID uint32) (uint32, error)
+	CreateClientData(ClientDataID uint32, dwSize uint32, Flags uint32) (uint32, error)
+	AddToClientDataDefinition(DefineID uint32, dwOffset uint32, dwSizeOrType uint32, fEpsilon float32, DatumID uint32) (uint32, error)
+	ClearClientDataDefinition(DefineID uint32) (uint32, error)
+	RequestClientData(ClientDataID uint32, RequestID uint32, DefineID uint32, Period uint32, Flags uint32, origin uint32, interval uint32, limit uint32) (uint32, error)
+	SetClientData(ClientDataID uint32, DefineID uint32, Flags uint32, dwReserved uint32, cbUnitSize uint32, pDataSet []byte) (uint32, error)
+}
+
+// InputEventsTransport is implemented by the transports reading and writing input events (MSFS SU13)
+type InputEventsTransport interface {
+	EnumerateInputEvents(RequestID uint32) (uint32, error)
+	GetInputEvent(RequestID uint32, Hash uint64) (uint32, error)
+	SetInputEvent(Hash uint64, cbUnitSize uint32, Value []byte) (uint32, error)
+	SubscribeInputEvent(Hash uint64) (uint32, error)
+	UnsubscribeInputEvent(Hash uint64) (uint32, error)
+	EnumerateInputEventParams(Hash uint64) (uint32, error)
+}
+
+// MenuTransport is implemented by the transports adding items to the Add-ons menu
+type MenuTransport interface {
+	MenuAddItem(szMenuItem string, MenuEventID uint32, dwData uint32) (uint32, error)
+	MenuDeleteItem(MenuEventID uint32) (uint32, error)
+	MenuAddSubItem(MenuEventID uint32, szMenuItem string, SubMenuEventID uint32, dwData uint32) (uint32, error)
+	MenuDeleteSubItem(MenuEventID uint32, SubMenuEventID uint32) (uint32, error)
+}
+
+// feature return the transport as the optional interface T, or ErrTransportUnsupported
+func feature[T any](transport Transport) (T, error) {
+	t, ok := transport.(T)
+	if !ok {
+		return t, fmt.Errorf("%T is not a %s: %w", transport, reflect.TypeOf((*T)(nil)).Elem().Name(), ErrTransportUnsupported)
+	}
+	return t, nil
+}
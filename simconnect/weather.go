@@ -0,0 +1,203 @@
+package simconnect
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Metar is a decoded METAR weather observation. The extensions of the simulator after '&' are ignored, only the
+// surface wind, visibility, temperature and pressure are kept
+type Metar struct {
+	// Raw is the METAR as sent by the simulator
+	Raw string
+	// Station is the ICAO code of the station, GLOB for interpolated observations
+	Station string
+	// Day, Hour and Minute are the UTC time of the observation
+	Day, Hour, Minute int
+	// WindDirection is where the wind blows from in degrees true, WindVariable is true for a variable wind (VRB)
+	WindDirection int
+	WindVariable  bool
+	// WindSpeed and WindGust are in knots, WindGust is zero without gusts
+	WindSpeed float64
+	WindGust  float64
+	// Visibility is in meters, 10 km and more is 10000
+	Visibility float64
+	CAVOK      bool
+	// Clouds are the cloud layers from the lowest
+	Clouds []MetarCloud
+	// Temperature and DewPoint are in degrees Celsius
+	Temperature float64
+	DewPoint    float64
+	// QNH is in hectopascals, altimeter settings in inches of mercury are converted
+	QNH float64
+}
+
+// MetarCloud is a cloud layer of a METAR
+type MetarCloud struct {
+	// Cover is FEW, SCT, BKN, OVC or VV for a vertical visibility
+	Cover string
+	// Base is the height above the station in feet
+	Base int
+	// Type is CB, TCU or empty
+	Type string
+}
+
+var (
+	metarStation    = regexp.MustCompile(`^[A-Z0-9]{4}$`)
+	metarTime       = regexp.MustCompile(`^(\d{2})(\d{2})(\d{2})Z$`)
+	metarWind       = regexp.MustCompile(`^(\d{3}|VRB)(\d{2,3})(?:G(\d{2,3}))?(KT|MPS|KMH)$`)
+	metarVisibility = regexp.MustCompile(`^(\d{4})$|^(\d+)KM$|^M?(\d+)(?:/(\d+))?SM$`)
+	metarCloud      = regexp.MustCompile(`^(FEW|SCT|BKN|OVC|VV)(\d{3})(CB|TCU)?$`)
+	metarOktas      = regexp.MustCompile(`^([1-8])([A-Z]{2})(\d{3})$`)
+	metarTemp       = regexp.MustCompile(`^(M?\d{1,2})/(M?\d{1,2})?$`)
+	metarPressure   = regexp.MustCompile(`^([QA])(\d{4})$`)
+)
+
+// ParseMetar decode a METAR, unknown groups like the weather phenomena are skipped
+func ParseMetar(raw string) (*Metar, error) {
+	fields := strings.Fields(raw)
+	if len(fields) > 0 && (fields[0] == "METAR" || fields[0] == "SPECI") {
+		fields = fields[1:]
+	}
+	if len(fields) == 0 {
+		return nil, errors.New("empty METAR")
+	}
+	if !metarStation.MatchString(fields[0]) {
+		return nil, fmt.Errorf("invalid METAR station %q", fields[0])
+	}
+	m := &Metar{Raw: raw, Station: fields[0], Clouds: make([]MetarCloud, 0)}
+	var wind, visibility, temp, pressure bool
+	for i := 1; i < len(fields); i++ {
+		field := strings.SplitN(fields[i], "&", 2)[0]
+		if field == "RMK" {
+			break
+		}
+		switch {
+		case metarTime.MatchString(field):
+			match := metarTime.FindStringSubmatch(field)
+			m.Day, m.Hour, m.Minute = atoi(match[1]), atoi(match[2]), atoi(match[3])
+		case !wind && metarWind.MatchString(field):
+			match := metarWind.FindStringSubmatch(field)
+			wind = true
+			if match[1] == "VRB" {
+				m.WindVariable = true
+			} else {
+				m.WindDirection = atoi(match[1])
+			}
+			m.WindSpeed = knots(atoi(match[2]), match[4])
+			if match[3] != "" {
+				m.WindGust = knots(atoi(match[3]), match[4])
+			}
+		case field == "CAVOK":
+			m.CAVOK = true
+			if !visibility {
+				visibility = true
+				m.Visibility = 10000
+			}
+		case !visibility && metarVisibility.MatchString(field):
+			match := metarVisibility.FindStringSubmatch(field)
+			visibility = true
+			switch {
+			case match[1] != "":
+				m.Visibility = float64(atoi(match[1]))
+				if m.Visibility == 9999 {
+					m.Visibility = 10000
+				}
+			case match[2] != "":
+				m.Visibility = float64(atoi(match[2])) * 1000
+			default:
+				miles := float64(atoi(match[3]))
+				if match[4] != "" {
+					miles /= float64(atoi(match[4]))
+				}
+				// 1 1/2SM is written in two groups
+				if i > 1 && len(fields[i-1]) == 1 && fields[i-1][0] >= '1' && fields[i-1][0] <= '9' {
+					miles += float64(fields[i-1][0] - '0')
+				}
+				m.Visibility = miles * 1609.344
+			}
+		case metarCloud.MatchString(field):
+			match := metarCloud.FindStringSubmatch(field)
+			m.Clouds = append(m.Clouds, MetarCloud{match[1], atoi(match[2]) * 100, match[3]})
+		case metarOktas.MatchString(field):
+			// the simulator writes the cover in oktas followed by the cloud type
+			match := metarOktas.FindStringSubmatch(field)
+			cloud := MetarCloud{oktasCover[atoi(match[1])], atoi(match[3]) * 100, ""}
+			if match[2] == "CB" {
+				cloud.Type = "CB"
+			}
+			m.Clouds = append(m.Clouds, cloud)
+		case !temp && metarTemp.MatchString(field):
+			match := metarTemp.FindStringSubmatch(field)
+			temp = true
+			m.Temperature = celsius(match[1])
+			m.DewPoint = celsius(match[2])
+		case !pressure && metarPressure.MatchString(field):
+			match := metarPressure.FindStringSubmatch(field)
+			pressure = true
+			m.QNH = float64(atoi(match[2]))
+			if match[1] == "A" {
+				m.QNH = m.QNH / 100 * 33.8639
+			}
+		}
+	}
+	return m, nil
+}
+
+var oktasCover = [...]string{"", "FEW", "FEW", "SCT", "SCT", "BKN", "BKN", "BKN", "OVC"}
+
+func atoi(s string) int {
+	v, _ := strconv.Atoi(s)
+	return v
+}
+
+func knots(speed int, unit string) float64 {
+	switch unit {
+	case "MPS":
+		return float64(speed) * 1.943844
+	case "KMH":
+		return float64(speed) / 1.852
+	}
+	return float64(speed)
+}
+
+func celsius(s string) float64 {
+	if strings.HasPrefix(s, "M") {
+		return -float64(atoi(s[1:]))
+	}
+	return float64(atoi(s))
+}
+
+// observation send a weather observation request and decode its METAR
+func (esc *EasySimConnect) observation(ctx context.Context, name string, send func(requestID uint32) (error, uint32)) (*Metar, error) {
+	raw, err := esc.request(ctx, name, send)
+	if err != nil {
+		return nil, err
+	}
+	return ParseMetar(raw.(string))
+}
+
+// WeatherRequestObservationAtStation return the weather observed at the station icao
+func (esc *EasySimConnect) WeatherRequestObservationAtStation(ctx context.Context, icao string) (*Metar, error) {
+	return esc.observation(ctx, "WeatherRequestObservationAtStation", func(requestID uint32) (error, uint32) {
+		return esc.sc.WeatherRequestObservationAtStation(requestID, icao)
+	})
+}
+
+// WeatherRequestObservationAtNearestStation return the weather observed at the station nearest to lat, lon in degrees
+func (esc *EasySimConnect) WeatherRequestObservationAtNearestStation(ctx context.Context, lat, lon float32) (*Metar, error) {
+	return esc.observation(ctx, "WeatherRequestObservationAtNearestStation", func(requestID uint32) (error, uint32) {
+		return esc.sc.WeatherRequestObservationAtNearestStation(requestID, lat, lon)
+	})
+}
+
+// WeatherRequestInterpolatedObservation return the weather interpolated at lat, lon in degrees and alt in feet
+func (esc *EasySimConnect) WeatherRequestInterpolatedObservation(ctx context.Context, lat, lon, alt float32) (*Metar, error) {
+	return esc.observation(ctx, "WeatherRequestInterpolatedObservation", func(requestID uint32) (error, uint32) {
+		return esc.sc.WeatherRequestInterpolatedObservation(requestID, lat, lon, alt)
+	})
+}
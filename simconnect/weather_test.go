@@ -0,0 +1,52 @@
+package simconnect
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseMetar(t *testing.T) {
+	m, err := ParseMetar("LFPG 181030Z 27015G25KT 240V300 9999 -RA FEW015 BKN040CB M02/M05 Q1008 NOSIG")
+	require.NoError(t, err)
+	assert.Equal(t, "LFPG", m.Station)
+	assert.Equal(t, []int{18, 10, 30}, []int{m.Day, m.Hour, m.Minute})
+	assert.Equal(t, 270, m.WindDirection)
+	assert.Equal(t, 15.0, m.WindSpeed)
+	assert.Equal(t, 25.0, m.WindGust)
+	assert.Equal(t, 10000.0, m.Visibility)
+	assert.Equal(t, []MetarCloud{{"FEW", 1500, ""}, {"BKN", 4000, "CB"}}, m.Clouds)
+	assert.Equal(t, -2.0, m.Temperature)
+	assert.Equal(t, -5.0, m.DewPoint)
+	assert.Equal(t, 1008.0, m.QNH)
+}
+
+func TestParseMetarUS(t *testing.T) {
+	m, err := ParseMetar("METAR KSEA 181053Z VRB03KT 1 1/2SM BR OVC008 12/11 A2992 RMK AO2 SLP133")
+	require.NoError(t, err)
+	assert.Equal(t, "KSEA", m.Station)
+	assert.True(t, m.WindVariable)
+	assert.Equal(t, 3.0, m.WindSpeed)
+	assert.InDelta(t, 2414, m.Visibility, 1)
+	assert.Equal(t, []MetarCloud{{"OVC", 800, ""}}, m.Clouds)
+	assert.InDelta(t, 1013.2, m.QNH, 0.1)
+}
+
+func TestParseMetarSimulator(t *testing.T) {
+	m, err := ParseMetar("EGLL 181050Z 23010KT&D980NG 25020KT&A1000NG 20KM&B-1500&D3000 3CU030&CU001FNMN000N 8ST080&ST001FNMN000N 15/10&A0 05/M02&A3000 Q1013")
+	require.NoError(t, err)
+	assert.Equal(t, 230, m.WindDirection)
+	assert.Equal(t, 10.0, m.WindSpeed)
+	assert.Equal(t, 20000.0, m.Visibility)
+	assert.Equal(t, []MetarCloud{{"SCT", 3000, ""}, {"OVC", 8000, ""}}, m.Clouds)
+	assert.Equal(t, 15.0, m.Temperature)
+	assert.Equal(t, 1013.0, m.QNH)
+}
+
+func TestParseMetarInvalid(t *testing.T) {
+	_, err := ParseMetar("")
+	assert.Error(t, err)
+	_, err = ParseMetar("not a metar")
+	assert.Error(t, err)
+}
@@ -0,0 +1,317 @@
+package simgo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+
+	sim "github.com/flysim-apps/simgo/simconnect"
+	"github.com/op/go-logging"
+)
+
+var _ DataProvider = (*SimConnectProvider)(nil)
+
+// SimConnectProvider is the DataProvider of Microsoft Flight Simulator, reports use the name, index and unit tags.
+// Fields tagged lvar are local variables read and written through the MobiFlight WASM module
+type SimConnectProvider struct {
+	name    string
+	address string
+	logger  *logging.Logger
+	// ctx is the lifetime of the connection, cancel is called by Close
+	ctx    context.Context
+	cancel context.CancelFunc
+	sc     *sim.EasySimConnect
+	lvars  *sim.LVarClient
+	events chan Event
+	mu     sync.Mutex
+	closed bool
+}
+
+// NewSimConnectProvider create a SimConnect provider. address is host:port of a remote SimConnect server,
+// when empty SimConnect.dll is used
+func NewSimConnectProvider(name string, address string, logger *logging.Logger) *SimConnectProvider {
+	return &SimConnectProvider{
+		name:    name,
+		address: address,
+		logger:  logger,
+		events:  make(chan Event, 16),
+	}
+}
+
+// Connect open the connection and wait for the flight to be running. ctx only bounds the wait, the connection
+// lasts until Close
+func (p *SimConnectProvider) Connect(ctx context.Context) error {
+	connCtx, cancel := context.WithCancel(context.Background())
+	var sc *sim.EasySimConnect
+	if p.address != "" {
+		sc = sim.NewEasySimConnectRemote(connCtx, p.address)
+	} else {
+		var err error
+		if sc, err = sim.NewEasySimConnect(connCtx); err != nil {
+			cancel()
+			return err
+		}
+	}
+	sc.SetLoggerLevel(sim.LogInfo)
+
+	c, err := sc.Connect(p.name)
+	if err != nil {
+		cancel()
+		return err
+	}
+	select {
+	case open := <-c:
+		if !open {
+			cancel()
+			return errors.New("connection to MSFS has been refused")
+		}
+	case <-ctx.Done():
+		sc.Close()
+		cancel()
+		return ctx.Err()
+	}
+
+	running := sc.ConnectSysEventSim()
+	crashed := sc.ConnectSysEventCrashed()
+	paused := sc.ConnectSysEventPause()
+	airloaded := sc.ConnectSysEventAircraftLoaded()
+
+	p.mu.Lock()
+	p.ctx, p.cancel, p.sc, p.lvars, p.closed = connCtx, cancel, sc, nil, false
+	p.mu.Unlock()
+
+	// events are read from now on, the dispatcher blocks until they are consumed
+	started := make(chan struct{})
+	quit := make(chan struct{})
+	go func() {
+		var once sync.Once
+		for {
+			var e Event
+			select {
+			case <-connCtx.Done():
+				return
+			case open := <-c:
+				if !open {
+					close(quit)
+					if !p.isClosed() {
+						p.emit(Event{Type: EventQuit})
+					}
+					// the subscriptions end with the connection
+					cancel()
+					return
+				}
+				continue
+			case r := <-running:
+				e = Event{Type: EventSimStop}
+				if r {
+					once.Do(func() { close(started) })
+					e = Event{Type: EventSimStart}
+				}
+			case r := <-paused:
+				e = Event{Type: EventPause, Value: r}
+			case <-crashed:
+				e = Event{Type: EventCrash}
+			case r := <-airloaded:
+				e = Event{Type: EventAircraftLoaded, Value: r}
+			}
+			p.emit(e)
+		}
+	}()
+
+	select {
+	case <-started:
+		return nil
+	case <-quit:
+		return errors.New("MSFS quit before the flight started")
+	case <-ctx.Done():
+		p.Close()
+		return ctx.Err()
+	}
+}
+
+func (p *SimConnectProvider) emit(e Event) {
+	if !sendEvent(p.events, e) {
+		p.logger.Warningf("Event %s dropped, events are not read", e.Type)
+	}
+}
+
+func (p *SimConnectProvider) isClosed() bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.closed
+}
+
+func (p *SimConnectProvider) conn() (context.Context, *sim.EasySimConnect, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.sc == nil || p.closed {
+		return nil, nil, errProviderNotConnected
+	}
+	return p.ctx, p.sc, nil
+}
+
+// lvarClient return the L:var client of the connection, it is registered with the MobiFlight WASM module on
+// first use
+func (p *SimConnectProvider) lvarClient() (*sim.LVarClient, error) {
+	ctx, sc, err := p.conn()
+	if err != nil {
+		return nil, err
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.lvars == nil {
+		name := strings.NewReplacer(".", "_", " ", "_").Replace(p.name)
+		if p.lvars, err = sim.NewLVarClient(ctx, sc, name); err != nil {
+			return nil, err
+		}
+	}
+	return p.lvars, nil
+}
+
+// lvarFields return the L:vars of the fields tagged lvar by field index
+func lvarFields(t reflect.Type) map[int]string {
+	fields := make(map[int]string)
+	for i := 0; i < t.NumField(); i++ {
+		if name := t.Field(i).Tag.Get("lvar"); name != "" {
+			fields[i] = name
+		}
+	}
+	return fields
+}
+
+// Subscribe to the SimVars and L:vars of report. interval is the delay between updates of every subscription,
+// L:vars are sent as soon as they change
+func (p *SimConnectProvider) Subscribe(report interface{}, interval time.Duration) (<-chan interface{}, error) {
+	ctx, sc, err := p.conn()
+	if err != nil {
+		return nil, err
+	}
+	val := reflect.Indirect(reflect.ValueOf(report))
+	if val.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("report must be a struct, got %s", val.Kind())
+	}
+	var cSimVar <-chan []sim.SimVar
+	simVars, err := convertToSimSimVar(val)
+	if err != nil {
+		return nil, err
+	}
+	if len(simVars) > 0 {
+		if cSimVar, err = sc.ConnectToSimVarEvery(interval, simVars...); err != nil {
+			return nil, err
+		}
+	}
+	var cLVar <-chan sim.LVar
+	var lvars *sim.LVarClient
+	fields := lvarFields(val.Type())
+	if len(fields) > 0 {
+		if lvars, err = p.lvarClient(); err != nil {
+			return nil, err
+		}
+		cLVar = lvars.Updates(ctx)
+		names := make([]string, 0, len(fields))
+		for _, name := range fields {
+			names = append(names, name)
+		}
+		if err := lvars.Register(names...); err != nil {
+			return nil, err
+		}
+	}
+
+	out := make(chan interface{})
+	go func() {
+		defer close(out)
+		var last []sim.SimVar
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case sv := <-cSimVar:
+				last = sv
+			case <-cLVar:
+				// L:vars are sent with the SimVars once they are received
+				if len(simVars) > 0 && last == nil {
+					continue
+				}
+			}
+			r := reflect.New(val.Type()).Elem()
+			assignSimVars(r, last)
+			for i, name := range fields {
+				if f, found := lvars.Get(name); found {
+					setFloat(r.Field(i), f)
+				}
+			}
+			select {
+			case out <- r.Interface():
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return out, nil
+}
+
+// Write set the numeric and bool tagged fields of report on the user aircraft, SimVars and L:vars.
+// Pass a struct holding only the variables to change
+func (p *SimConnectProvider) Write(report interface{}) error {
+	_, sc, err := p.conn()
+	if err != nil {
+		return err
+	}
+	val := reflect.Indirect(reflect.ValueOf(report))
+	if val.Kind() != reflect.Struct {
+		return fmt.Errorf("report must be a struct, got %s", val.Kind())
+	}
+	for i, name := range lvarFields(val.Type()) {
+		f, ok := fieldFloat(val.Field(i))
+		if !ok {
+			continue
+		}
+		lvars, err := p.lvarClient()
+		if err != nil {
+			return err
+		}
+		if err := lvars.Set(name, f); err != nil {
+			return err
+		}
+	}
+	for i := 0; i < val.NumField(); i++ {
+		simVar, ok, err := simVarField(val.Type().Field(i))
+		if err != nil {
+			return err
+		}
+		if !ok {
+			continue
+		}
+		f, ok := fieldFloat(val.Field(i))
+		if !ok {
+			continue
+		}
+		simVar.SetFloat64(f)
+		if err := sc.SetSimObjectOn(sim.SIMCONNECT_OBJECT_ID_USER, simVar); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Events return pause, crash, aircraft loaded, sim start/stop and quit notifications
+func (p *SimConnectProvider) Events() <-chan Event {
+	return p.events
+}
+
+// Close the connection to the simulator
+func (p *SimConnectProvider) Close() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.sc == nil || p.closed {
+		return nil
+	}
+	p.closed = true
+	p.sc.Close()
+	p.cancel()
+	return nil
+}
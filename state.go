@@ -0,0 +1,137 @@
+package simgo
+
+import "sync"
+
+// ConnectionState is the state of the connection to the simulator, published on SimGo.State
+type ConnectionState int
+
+const (
+	// StateWaitingForSimulator tracking started, the simulator is not reachable yet
+	StateWaitingForSimulator ConnectionState = iota
+	// StateConnectedInMenu the simulator is connected, the user is in the menu or loading a flight
+	StateConnectedInMenu
+	// StateInFlight the flight is running
+	StateInFlight
+	// StatePaused the flight is paused
+	StatePaused
+	// StateReconnecting the session failed and is restarted
+	StateReconnecting
+	// StateGaveUp the session exceeded its restart budget
+	StateGaveUp
+)
+
+func (c ConnectionState) String() string {
+	switch c {
+	case StateWaitingForSimulator:
+		return "waiting for simulator"
+	case StateConnectedInMenu:
+		return "connected, in menu"
+	case StateInFlight:
+		return "in flight"
+	case StatePaused:
+		return "paused"
+	case StateReconnecting:
+		return "reconnecting"
+	case StateGaveUp:
+		return "gave up"
+	}
+	return "unknown"
+}
+
+// connected return true when a simulator is connected
+func (c ConnectionState) connected() bool {
+	return c == StateConnectedInMenu || c == StateInFlight || c == StatePaused
+}
+
+// stateMachine hold the current state and its subscribers, the zero value is ready to use
+type stateMachine struct {
+	mu      sync.Mutex
+	current ConnectionState
+	subs    map[chan ConnectionState]struct{}
+	// supervised is true while a TrackReports supervisor drives the state, there is one per SimGo
+	supervised bool
+}
+
+// acquire reserve the state for a supervisor, false when another one runs
+func (m *stateMachine) acquire() bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.supervised {
+		return false
+	}
+	m.supervised = true
+	return true
+}
+
+// release let another supervisor start
+func (m *stateMachine) release() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.supervised = false
+}
+
+// ConnectionState return the current state
+func (s *SimGo) ConnectionState() ConnectionState {
+	s.state.mu.Lock()
+	defer s.state.mu.Unlock()
+	return s.state.current
+}
+
+// SubscribeState return a chan receiving the current state then every change. A slow reader only misses
+// intermediate states, the last one is always delivered. Call cancel to unsubscribe
+func (s *SimGo) SubscribeState() (<-chan ConnectionState, func()) {
+	c := make(chan ConnectionState, 1)
+	s.state.mu.Lock()
+	defer s.state.mu.Unlock()
+	if s.state.subs == nil {
+		s.state.subs = make(map[chan ConnectionState]struct{})
+	}
+	s.state.subs[c] = struct{}{}
+	c <- s.state.current
+	return c, func() {
+		s.state.mu.Lock()
+		defer s.state.mu.Unlock()
+		delete(s.state.subs, c)
+	}
+}
+
+// setState move to next and publish it on State, Connection and the subscribers when it changed
+func (s *SimGo) setState(next ConnectionState) {
+	s.state.mu.Lock()
+	defer s.state.mu.Unlock()
+	prev := s.state.current
+	if prev == next {
+		return
+	}
+	s.state.current = next
+	s.Logger.Infof("State: %s", next)
+
+	select {
+	case s.State <- next:
+	default:
+	}
+	if prev.connected() != next.connected() {
+		select {
+		case s.Connection <- next.connected():
+		default:
+		}
+	}
+	for c := range s.state.subs {
+		select {
+		case <-c:
+		default:
+		}
+		c <- next
+	}
+}
+
+// setPaused switch between StateInFlight and StatePaused
+func (s *SimGo) setPaused(paused bool) {
+	state := s.ConnectionState()
+	if paused && state == StateInFlight {
+		s.setState(StatePaused)
+	}
+	if !paused && state == StatePaused {
+		s.setState(StateInFlight)
+	}
+}
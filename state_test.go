@@ -0,0 +1,155 @@
+package simgo
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/flysim-apps/simgo/simconnect/simtest"
+	"github.com/op/go-logging"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// waitState read states until want is received
+func waitState(t *testing.T, states <-chan ConnectionState, want ConnectionState) {
+	t.Helper()
+	timeout := time.After(5 * time.Second)
+	for {
+		select {
+		case state := <-states:
+			if state == want {
+				return
+			}
+		case <-timeout:
+			t.Fatalf("state %q not reached", want)
+		}
+	}
+}
+
+func TestSubscribeState(t *testing.T) {
+	s := NewSimGo(logging.MustGetLogger("simgo-test"), SimConnect)
+	assert.Equal(t, StateWaitingForSimulator, s.ConnectionState())
+
+	states, cancel := s.SubscribeState()
+	assert.Equal(t, StateWaitingForSimulator, <-states)
+
+	s.setState(StateConnectedInMenu)
+	s.setState(StateInFlight)
+	s.setPaused(true)
+	assert.Equal(t, StatePaused, <-states, "only the last state is kept")
+	assert.Equal(t, StatePaused, s.ConnectionState())
+	assert.Equal(t, true, <-s.Connection)
+	assert.Equal(t, StateConnectedInMenu, <-s.State)
+
+	cancel()
+	s.setState(StateReconnecting)
+	select {
+	case state := <-states:
+		t.Fatalf("unexpected state %q after cancel", state)
+	default:
+	}
+	assert.Equal(t, false, <-s.Connection)
+	assert.Equal(t, "reconnecting", StateReconnecting.String())
+}
+
+func TestTrackState(t *testing.T) {
+	srv, err := simtest.NewServer()
+	require.NoError(t, err)
+	defer srv.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	s := NewSimGo(logging.MustGetLogger("simgo-test"), SimConnect)
+	s.SimConnectAddr = srv.Addr()
+	s.Context = ctx
+	go func() {
+		for {
+			select {
+			case <-s.TrackPause:
+			case <-s.TrackEvent:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	states, unsubscribe := s.SubscribeState()
+	defer unsubscribe()
+
+	s.TrackWithPolicy("simgo-test", ReportTest{}, RestartPolicy{MaxTries: 0, Backoff: time.Millisecond}, 1)
+	waitState(t, states, StateInFlight)
+
+	srv.SetPaused(true)
+	waitState(t, states, StatePaused)
+	srv.SetPaused(false)
+	waitState(t, states, StateInFlight)
+	srv.SetRunning(false)
+	waitState(t, states, StateConnectedInMenu)
+
+	srv.Crash()
+	waitState(t, states, StateGaveUp)
+	select {
+	case crashed := <-s.TrackCrash:
+		assert.True(t, crashed)
+	default:
+		t.Fatal("crash not published on TrackCrash")
+	}
+}
+
+func TestTrackStateReconnecting(t *testing.T) {
+	srv, err := simtest.NewServer()
+	require.NoError(t, err)
+	addr := srv.Addr()
+	srv.Close()
+
+	s := NewSimGo(logging.MustGetLogger("simgo-test"), SimConnect)
+	s.SimConnectAddr = addr
+	states, unsubscribe := s.SubscribeState()
+	defer unsubscribe()
+
+	s.TrackWithPolicy("simgo-test", ReportTest{}, RestartPolicy{MaxTries: 1, Backoff: 50 * time.Millisecond}, 1)
+	waitState(t, states, StateReconnecting)
+	waitState(t, states, StateGaveUp)
+	assert.Equal(t, StateGaveUp, s.ConnectionState())
+}
+
+// nextTrackError read errors until one of trackID is received
+func nextTrackError(t *testing.T, s *SimGo, trackID int) *TrackError {
+	t.Helper()
+	timeout := time.After(5 * time.Second)
+	for {
+		select {
+		case err := <-s.Error:
+			var trackErr *TrackError
+			require.ErrorAs(t, err, &trackErr)
+			if trackErr.TrackID == trackID {
+				return trackErr
+			}
+		case <-timeout:
+			t.Fatalf("no error for track %d", trackID)
+		}
+	}
+}
+
+func TestTrackStateSingleSupervisor(t *testing.T) {
+	srv, err := simtest.NewServer()
+	require.NoError(t, err)
+	addr := srv.Addr()
+	srv.Close()
+
+	s := NewSimGo(logging.MustGetLogger("simgo-test"), SimConnect)
+	s.SimConnectAddr = addr
+	s.TrackWithPolicy("simgo-test", ReportTest{}, RestartPolicy{MaxTries: 1, Backoff: 50 * time.Millisecond}, 1)
+	s.TrackWithPolicy("simgo-test", ReportTest{}, RestartPolicy{MaxTries: 1, Backoff: 50 * time.Millisecond}, 2)
+	assert.ErrorIs(t, nextTrackError(t, s, 2), ErrAlreadyTracking)
+
+	for {
+		if errors.Is(nextTrackError(t, s, 1), ErrExceededRetries) {
+			break
+		}
+	}
+	// the state is free once the first supervisor gave up
+	s.TrackWithPolicy("simgo-test", ReportTest{}, RestartPolicy{MaxTries: 0, Backoff: 50 * time.Millisecond}, 3)
+	assert.ErrorIs(t, nextTrackError(t, s, 3), ErrConnectFailed)
+}
@@ -0,0 +1,77 @@
+package simgo
+
+import (
+	"context"
+	"time"
+)
+
+// SubscribeOptions configure Subscribe
+type SubscribeOptions struct {
+	// Provider to subscribe with. When nil a provider of SimGo.Provider is connected, it is closed with ctx
+	Provider DataProvider
+	// Name of the client, "simgo" when empty. Used when Provider is nil
+	Name string
+	// Address of the sim bridge, the provider default when empty. Used when Provider is nil
+	Address string
+	// Interval between two reports, the provider default when zero
+	Interval time.Duration
+}
+
+// Subscribe return a chan receiving a T on every update. The definition is derived from the tags of T
+// (name/unit, dataref or address depending on the provider). Each call has its own chan, several report types
+// can be tracked at once by sharing opts.Provider. The chan is closed when ctx is done or the provider stops
+func Subscribe[T any](ctx context.Context, sim *SimGo, opts SubscribeOptions) (<-chan T, error) {
+	provider := opts.Provider
+	if provider == nil {
+		name := opts.Name
+		if name == "" {
+			name = "simgo"
+		}
+		p, err := sim.NewProvider(name, opts.Address)
+		if err != nil {
+			return nil, err
+		}
+		if err := p.Connect(ctx); err != nil {
+			return nil, err
+		}
+		provider = p
+	}
+	closeProvider := func() {
+		if opts.Provider == nil {
+			provider.Close()
+		}
+	}
+
+	var report T
+	reports, err := provider.Subscribe(report, opts.Interval)
+	if err != nil {
+		closeProvider()
+		return nil, err
+	}
+	c := make(chan T)
+	go func() {
+		defer close(c)
+		defer closeProvider()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case r, ok := <-reports:
+				if !ok {
+					return
+				}
+				v, ok := r.(T)
+				if !ok {
+					sim.Logger.Warningf("Subscribe: unexpected %T, want %T", r, report)
+					continue
+				}
+				select {
+				case c <- v:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+	return c, nil
+}
@@ -0,0 +1,104 @@
+package simgo
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/flysim-apps/simgo/simconnect/simtest"
+	"github.com/op/go-logging"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type headingTest struct {
+	Heading float64 `name:"PLANE HEADING DEGREES TRUE" unit:"degrees"`
+}
+
+func TestSubscribe(t *testing.T) {
+	srv, err := simtest.NewServer()
+	require.NoError(t, err)
+	defer srv.Close()
+	srv.Aircraft.Set("PLANE ALTITUDE", 1200)
+	srv.Aircraft.Set("PLANE HEADING DEGREES TRUE", 270)
+
+	s := NewSimGo(logging.MustGetLogger("simgo-test"), SimConnect)
+	s.SimConnectAddr = srv.Addr()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	reports, err := Subscribe[ReportTest](ctx, s, SubscribeOptions{Name: "simgo-test", Interval: 50 * time.Millisecond})
+	require.NoError(t, err)
+	headings, err := Subscribe[headingTest](ctx, s, SubscribeOptions{Name: "simgo-test", Interval: 50 * time.Millisecond})
+	require.NoError(t, err)
+
+	for i := 0; i < 2; i++ {
+		select {
+		case r := <-reports:
+			assert.Equal(t, 1200.0, r.PlaneAltitude)
+		case h := <-headings:
+			assert.Equal(t, 270.0, h.Heading)
+		case <-time.After(2 * time.Second):
+			t.Fatal("no report received")
+		}
+	}
+	select {
+	case h := <-headings:
+		assert.Equal(t, 270.0, h.Heading)
+	case <-time.After(2 * time.Second):
+		t.Fatal("no heading received")
+	}
+
+	cancel()
+	assert.Eventually(t, func() bool {
+		_, ok := <-reports
+		return !ok
+	}, 2*time.Second, 10*time.Millisecond)
+}
+
+func TestSubscribeSharedProvider(t *testing.T) {
+	srv, err := simtest.NewServer()
+	require.NoError(t, err)
+	defer srv.Close()
+	srv.Aircraft.Set("PLANE HEADING DEGREES TRUE", 90)
+
+	s := NewSimGo(logging.MustGetLogger("simgo-test"), SimConnect)
+	s.SimConnectAddr = srv.Addr()
+	p, err := s.NewProvider("simgo-test", "")
+	require.NoError(t, err)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	require.NoError(t, p.Connect(ctx))
+	defer p.Close()
+
+	subCtx, subCancel := context.WithCancel(ctx)
+	headings, err := Subscribe[headingTest](subCtx, s, SubscribeOptions{Provider: p})
+	require.NoError(t, err)
+	select {
+	case h := <-headings:
+		assert.Equal(t, 90.0, h.Heading)
+	case <-time.After(2 * time.Second):
+		t.Fatal("no heading received")
+	}
+	subCancel()
+
+	// the shared provider stays open
+	_, err = p.Subscribe(headingTest{}, 0)
+	assert.NoError(t, err)
+
+	_, err = Subscribe[int](ctx, s, SubscribeOptions{Provider: p})
+	assert.Error(t, err)
+
+	// the chan ends with the provider
+	headings, err = Subscribe[headingTest](ctx, s, SubscribeOptions{Provider: p})
+	require.NoError(t, err)
+	require.NoError(t, p.Close())
+	assert.Eventually(t, func() bool {
+		select {
+		case _, ok := <-headings:
+			return !ok
+		default:
+			return false
+		}
+	}, 2*time.Second, 10*time.Millisecond)
+}
@@ -0,0 +1,171 @@
+package simgo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// RestartPolicy configure how TrackWithPolicy restarts a failed tracking session
+type RestartPolicy struct {
+	// MaxTries is the number of consecutive failed sessions before giving up with ErrExceededRetries.
+	// A session which received data resets the count
+	MaxTries int
+	// Backoff is the delay before the first restart, doubled after each failure up to MaxBackoff.
+	// One second and 30 seconds when zero
+	Backoff    time.Duration
+	MaxBackoff time.Duration
+}
+
+func (p RestartPolicy) delay(attempt int) time.Duration {
+	backoff, max := p.Backoff, p.MaxBackoff
+	if backoff <= 0 {
+		backoff = time.Second
+	}
+	if max <= 0 {
+		max = 30 * time.Second
+	}
+	for i := 1; i < attempt && backoff < max; i++ {
+		backoff *= 2
+	}
+	if backoff > max {
+		backoff = max
+	}
+	return backoff
+}
+
+// TrackWithRecover track report and restart the session up to maxTries consecutive failures
+func (s *SimGo) TrackWithRecover(name string, report interface{}, maxTries int, trackID int) {
+	s.TrackWithPolicy(name, report, RestartPolicy{MaxTries: maxTries}, trackID)
+}
+
+// TrackWithPolicy track report in the background. Reports are sent on TrackEvent, failures on Error as
+// *TrackError wrapping ErrConnectFailed, ErrStaleData, ErrSimQuit, ErrCrashed or ErrPanic. The session is
+// restarted following policy, ErrExceededRetries is sent when it gives up. Tracking stops with SimGo.Context
+func (s *SimGo) TrackWithPolicy(name string, report interface{}, policy RestartPolicy, trackID int) {
+	s.TrackReports(name, policy, trackID, &TrackedReport{Report: report, C: s.TrackEvent})
+}
+
+// TrackedReport is a report struct tracked at its own rate, its reports are sent on C
+type TrackedReport struct {
+	Report interface{}
+	// Interval between two reports, one second when zero
+	Interval time.Duration
+	// ChangedOnly send the report only when a SimVar changed by more than the epsilon tag of its field
+	ChangedOnly bool
+	C           chan interface{}
+}
+
+// NewTrackedReport return a TrackedReport with a new C
+func NewTrackedReport(report interface{}, interval time.Duration) *TrackedReport {
+	return &TrackedReport{Report: report, Interval: interval, C: make(chan interface{})}
+}
+
+// TrackReports is TrackWithPolicy for several reports sharing one connection. Every report is
+// registered again when the session is restarted, their C stay the same. Only the SimConnect provider is
+// tracked, the others send ErrUnsupportedProvider on Error and are used through NewProvider.
+// A SimGo has one supervisor driving its State, the calls made before it stops send ErrAlreadyTracking,
+// track other reports in the same session or with another SimGo
+func (s *SimGo) TrackReports(name string, policy RestartPolicy, trackID int, reports ...*TrackedReport) {
+	if s.Provider != SimConnect {
+		err := fmt.Errorf("%w: %s, use NewProvider", ErrUnsupportedProvider, s.Provider)
+		s.Logger.Errorf("Track %d failed: %s", trackID, err.Error())
+		s.sendError(&TrackError{trackID, 1, err})
+		return
+	}
+	if !s.state.acquire() {
+		s.Logger.Errorf("Track %d failed: %s", trackID, ErrAlreadyTracking.Error())
+		s.sendError(&TrackError{trackID, 1, ErrAlreadyTracking})
+		return
+	}
+	ctx := s.Context
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	s.setState(StateWaitingForSimulator)
+	go s.supervise(ctx, newTrackSession(), name, reports, policy, trackID)
+}
+
+func (s *SimGo) supervise(ctx context.Context, session *trackSession, name string, reports []*TrackedReport, policy RestartPolicy, trackID int) {
+	for attempt := 1; ; {
+		err := s.runSession(ctx, session, name, reports)
+		if ctx.Err() != nil {
+			s.Logger.Infof("Track %d stopped", trackID)
+			s.state.release()
+			return
+		}
+		if session.hasReceived() {
+			attempt = 1
+		}
+		s.Logger.Errorf("Track %d failed: %s", trackID, err.Error())
+		s.sendError(&TrackError{trackID, attempt, err})
+
+		if attempt > policy.MaxTries {
+			s.Logger.Error("SimGo exceeded max tries. Exiting...")
+			s.setState(StateGaveUp)
+			s.state.release()
+			s.sendError(&TrackError{trackID, attempt, ErrExceededRetries})
+			return
+		}
+
+		s.setState(StateReconnecting)
+		delay := policy.delay(attempt)
+		s.Logger.Infof("Recovering in %s...", delay)
+		select {
+		case <-time.After(delay):
+		case <-ctx.Done():
+			s.state.release()
+			return
+		}
+		attempt++
+	}
+}
+
+// runSession run track with its watchdog, a panic is returned as ErrPanic
+func (s *SimGo) runSession(parent context.Context, session *trackSession, name string, reports []*TrackedReport) (err error) {
+	ctx, cancel := context.WithCancelCause(parent)
+	defer cancel(nil)
+
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("%w: %v", ErrPanic, r)
+		}
+	}()
+
+	session.reset()
+	go func() {
+		checker := time.NewTicker(session.staleTimeout)
+		defer checker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				s.Logger.Warning("Checking routine will exit")
+				return
+			case <-checker.C:
+				if reason, stale := session.stale(time.Now()); stale {
+					s.Logger.Error(reason)
+					cancel(fmt.Errorf("%w: %s", ErrStaleData, reason))
+				}
+			}
+		}
+	}()
+
+	err = s.track(ctx, session, name, reports)
+	if err == nil {
+		err = ErrSimQuit
+	}
+	if errors.Is(err, context.Canceled) && parent.Err() == nil {
+		err = ErrStaleData
+	}
+	return err
+}
+
+// sendError deliver err on SimGo.Error without blocking the supervisor when nobody reads it
+func (s *SimGo) sendError(err error) {
+	select {
+	case s.Error <- err:
+	default:
+		s.Logger.Warningf("Error dropped, SimGo.Error is full: %s", err.Error())
+	}
+}
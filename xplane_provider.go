@@ -0,0 +1,314 @@
+package simgo
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"net"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/op/go-logging"
+)
+
+// X-Plane UDP packet sizes, see "Exchanging Data with X-Plane.rtfd" in the X-Plane Instructions folder
+const (
+	xplaneHeaderSize = 5
+	xplaneRREFPath   = 400
+	xplaneDREFPath   = 500
+	xplaneMaxPacket  = 1 << 16
+)
+
+var _ DataProvider = (*XPlaneUDPProvider)(nil)
+
+// XPlaneUDPProvider is the DataProvider of X-Plane built-in UDP protocol. Reports use the dataref tag,
+// like `dataref:"sim/flightmodel/position/elevation"` or `dataref:"sim/flightmodel/engine/ENGN_N1_[0]"`.
+// Datarefs are read as float, strings are not supported
+type XPlaneUDPProvider struct {
+	address string
+	logger  *logging.Logger
+	// ctx is the lifetime of the subscriptions, cancel is called by Close
+	ctx    context.Context
+	cancel context.CancelFunc
+	conn   *net.UDPConn
+	events chan Event
+	mu     sync.Mutex
+	index  int32
+	refs   map[int32]xplaneRef
+}
+
+// xplaneRef is the position of a dataref in its subscription
+type xplaneRef struct {
+	sub  *xplaneSubscription
+	pos  int
+	path string
+}
+
+// xplaneSubscription keep the last value of the datarefs of a report, float64 or string
+type xplaneSubscription struct {
+	report   reflect.Value
+	fields   []int
+	mu       sync.Mutex
+	values   []interface{}
+	received []bool
+}
+
+func newXPlaneSubscription(report reflect.Value, fields []int) *xplaneSubscription {
+	return &xplaneSubscription{
+		report:   report,
+		fields:   fields,
+		values:   make([]interface{}, len(fields)),
+		received: make([]bool, len(fields)),
+	}
+}
+
+// NewXPlaneUDPProvider create a X-Plane provider for address (host:port), like DefaultXPlaneAddr
+func NewXPlaneUDPProvider(address string, logger *logging.Logger) *XPlaneUDPProvider {
+	return &XPlaneUDPProvider{
+		address: address,
+		logger:  logger,
+		events:  make(chan Event, 16),
+		refs:    make(map[int32]xplaneRef),
+	}
+}
+
+// Connect open the UDP socket, X-Plane answers once datarefs are subscribed. The socket lasts until Close
+func (p *XPlaneUDPProvider) Connect(ctx context.Context) error {
+	addr, err := net.ResolveUDPAddr("udp", p.address)
+	if err != nil {
+		return err
+	}
+	conn, err := net.DialUDP("udp", nil, addr)
+	if err != nil {
+		return err
+	}
+	connCtx, cancel := context.WithCancel(context.Background())
+	p.mu.Lock()
+	p.ctx, p.cancel, p.conn = connCtx, cancel, conn
+	p.mu.Unlock()
+	p.logger.Infof("Connected to X-Plane on %s", p.address)
+	go p.readLoop(conn, cancel)
+	return nil
+}
+
+func (p *XPlaneUDPProvider) readLoop(conn *net.UDPConn, cancel context.CancelFunc) {
+	buf := make([]byte, xplaneMaxPacket)
+	for {
+		n, err := conn.Read(buf)
+		if err != nil {
+			if _, cerr := p.connection(); cerr == nil {
+				p.logger.Errorf("Unable to read from X-Plane: %s", err.Error())
+				sendEvent(p.events, Event{Type: EventQuit})
+			}
+			// the subscriptions end with the connection
+			cancel()
+			return
+		}
+		if n < xplaneHeaderSize || string(buf[:4]) != "RREF" {
+			continue
+		}
+		for data := buf[xplaneHeaderSize:n]; len(data) >= 8; data = data[8:] {
+			index := int32(binary.LittleEndian.Uint32(data))
+			value := math.Float32frombits(binary.LittleEndian.Uint32(data[4:]))
+			p.mu.Lock()
+			ref, found := p.refs[index]
+			p.mu.Unlock()
+			if found {
+				ref.sub.set(ref.pos, float64(value))
+			}
+		}
+	}
+}
+
+func (p *XPlaneUDPProvider) connection() (*net.UDPConn, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.conn == nil {
+		return nil, errProviderNotConnected
+	}
+	return p.conn, nil
+}
+
+func (p *XPlaneUDPProvider) send(header string, body ...interface{}) error {
+	conn, err := p.connection()
+	if err != nil {
+		return err
+	}
+	buf := new(bytes.Buffer)
+	buf.WriteString(header)
+	buf.WriteByte(0)
+	for _, b := range body {
+		binary.Write(buf, binary.LittleEndian, b)
+	}
+	_, err = conn.Write(buf.Bytes())
+	return err
+}
+
+// xplanePath return a zero padded dataref path of size bytes
+func xplanePath(path string, size int) []byte {
+	b := make([]byte, size)
+	copy(b[:size-1], path)
+	return b
+}
+
+func (p *XPlaneUDPProvider) rref(freq int32, index int32, path string) error {
+	return p.send("RREF", freq, index, xplanePath(path, xplaneRREFPath))
+}
+
+// datarefs return the field index and the dataref of every field with a dataref tag
+func datarefs(t reflect.Type) ([]int, []string) {
+	fields := make([]int, 0)
+	paths := make([]string, 0)
+	for i := 0; i < t.NumField(); i++ {
+		if path, _ := t.Field(i).Tag.Lookup("dataref"); path != "" {
+			fields = append(fields, i)
+			paths = append(paths, path)
+		}
+	}
+	return fields, paths
+}
+
+// Subscribe request the datarefs of report with RREF. X-Plane sends them interval apart, one second when zero
+func (p *XPlaneUDPProvider) Subscribe(report interface{}, interval time.Duration) (<-chan interface{}, error) {
+	if _, err := p.connection(); err != nil {
+		return nil, err
+	}
+	val := reflect.Indirect(reflect.ValueOf(report))
+	if val.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("report must be a struct, got %s", val.Kind())
+	}
+	fields, paths := datarefs(val.Type())
+	if len(fields) == 0 {
+		return nil, errors.New("report has no dataref")
+	}
+	if interval <= 0 {
+		interval = time.Second
+	}
+	freq := int32(math.Ceil(float64(time.Second) / float64(interval)))
+
+	sub := newXPlaneSubscription(val, fields)
+	for pos, path := range paths {
+		p.mu.Lock()
+		p.index++
+		index := p.index
+		p.refs[index] = xplaneRef{sub, pos, path}
+		p.mu.Unlock()
+		if err := p.rref(freq, index, path); err != nil {
+			return nil, err
+		}
+	}
+
+	p.mu.Lock()
+	ctx := p.ctx
+	p.mu.Unlock()
+	return sub.run(ctx, interval), nil
+}
+
+// run send a snapshot of the report every interval until ctx is done
+func (s *xplaneSubscription) run(ctx context.Context, interval time.Duration) <-chan interface{} {
+	out := make(chan interface{})
+	go func() {
+		defer close(out)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+			r, ok := s.snapshot()
+			if !ok {
+				continue
+			}
+			select {
+			case out <- r:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return out
+}
+
+func (s *xplaneSubscription) set(pos int, value interface{}) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.values[pos] = value
+	s.received[pos] = true
+}
+
+// snapshot return a new report with the last values, false until every dataref was received once
+func (s *xplaneSubscription) snapshot() (interface{}, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	r := reflect.New(s.report.Type()).Elem()
+	for pos, field := range s.fields {
+		if !s.received[pos] {
+			return nil, false
+		}
+		switch v := s.values[pos].(type) {
+		case float64:
+			setFloat(r.Field(field), v)
+		case string:
+			if r.Field(field).Kind() == reflect.String {
+				r.Field(field).SetString(v)
+			}
+		}
+	}
+	return r.Interface(), true
+}
+
+// Write set the datarefs of report with DREF
+func (p *XPlaneUDPProvider) Write(report interface{}) error {
+	val := reflect.Indirect(reflect.ValueOf(report))
+	if val.Kind() != reflect.Struct {
+		return fmt.Errorf("report must be a struct, got %s", val.Kind())
+	}
+	fields, paths := datarefs(val.Type())
+	for i, field := range fields {
+		f, ok := fieldFloat(val.Field(field))
+		if !ok {
+			continue
+		}
+		if err := p.send("DREF", float32(f), xplanePath(paths[i], xplaneDREFPath)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Command run a X-Plane command like "sim/flight_controls/landing_gear_toggle" with CMND
+func (p *XPlaneUDPProvider) Command(command string) error {
+	return p.send("CMND", []byte(command))
+}
+
+// Events return EventQuit when X-Plane is not reachable anymore
+func (p *XPlaneUDPProvider) Events() <-chan Event {
+	return p.events
+}
+
+// Close unsubscribe every dataref and close the socket
+func (p *XPlaneUDPProvider) Close() error {
+	p.mu.Lock()
+	refs := p.refs
+	p.refs = make(map[int32]xplaneRef)
+	p.mu.Unlock()
+	for index, ref := range refs {
+		p.rref(0, index, ref.path)
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.conn == nil {
+		return nil
+	}
+	err := p.conn.Close()
+	p.conn = nil
+	p.cancel()
+	return err
+}
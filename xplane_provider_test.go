@@ -0,0 +1,119 @@
+package simgo
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"math"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/op/go-logging"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type xplaneReport struct {
+	Elevation float64 `dataref:"sim/flightmodel/position/elevation"`
+	OnGround  bool    `dataref:"sim/flightmodel/failures/onground_any"`
+	Title     string
+}
+
+// xplaneStandIn answers RREF requests like X-Plane and records the other packets
+func xplaneStandIn(t *testing.T, values map[string]float32) (*net.UDPConn, <-chan []byte) {
+	conn, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)})
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+	packets := make(chan []byte, 16)
+	go func() {
+		buf := make([]byte, 1024)
+		for {
+			n, addr, err := conn.ReadFromUDP(buf)
+			if err != nil {
+				return
+			}
+			packet := append([]byte(nil), buf[:n]...)
+			if string(packet[:4]) != "RREF" {
+				packets <- packet
+				continue
+			}
+			freq := binary.LittleEndian.Uint32(packet[5:])
+			if freq == 0 {
+				continue
+			}
+			index := binary.LittleEndian.Uint32(packet[9:])
+			path := string(bytes.TrimRight(packet[13:], "\x00"))
+			reply := []byte("RREF,")
+			reply = binary.LittleEndian.AppendUint32(reply, index)
+			reply = binary.LittleEndian.AppendUint32(reply, math.Float32bits(values[path]))
+			conn.WriteToUDP(reply, addr)
+		}
+	}()
+	return conn, packets
+}
+
+func TestXPlaneUDPProvider(t *testing.T) {
+	conn, packets := xplaneStandIn(t, map[string]float32{
+		"sim/flightmodel/position/elevation":    1234.5,
+		"sim/flightmodel/failures/onground_any": 1,
+	})
+
+	s := NewSimGo(logging.MustGetLogger("simgo-test"), XPlane)
+	p, err := s.NewProvider("simgo-test", conn.LocalAddr().String())
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	require.NoError(t, p.Connect(ctx))
+	defer p.Close()
+	// the connection outlives the ctx of Connect
+	cancel()
+
+	c, err := p.Subscribe(xplaneReport{}, 50*time.Millisecond)
+	require.NoError(t, err)
+	select {
+	case r := <-c:
+		assert.Equal(t, xplaneReport{Elevation: 1234.5, OnGround: true}, r)
+	case <-time.After(2 * time.Second):
+		t.Fatal("no report received")
+	}
+
+	require.NoError(t, p.Write(struct {
+		Throttle float32 `dataref:"sim/cockpit2/engine/actuators/throttle_ratio_all"`
+	}{0.75}))
+	dref := <-packets
+	assert.Equal(t, "DREF\x00", string(dref[:5]))
+	assert.Equal(t, float32(0.75), math.Float32frombits(binary.LittleEndian.Uint32(dref[5:])))
+	assert.Equal(t, "sim/cockpit2/engine/actuators/throttle_ratio_all", string(bytes.TrimRight(dref[9:], "\x00")))
+	assert.Len(t, dref, 509)
+
+	require.NoError(t, p.(*XPlaneUDPProvider).Command("sim/flight_controls/landing_gear_toggle"))
+	assert.Equal(t, "CMND\x00sim/flight_controls/landing_gear_toggle", string(<-packets))
+
+	require.NoError(t, p.Close())
+	requireClosed(t, c)
+}
+
+func TestXPlaneUDPProviderPeerClosed(t *testing.T) {
+	conn, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)})
+	require.NoError(t, err)
+	addr := conn.LocalAddr().String()
+	conn.Close()
+
+	p := NewXPlaneUDPProvider(addr, logging.MustGetLogger("simgo-test"))
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	require.NoError(t, p.Connect(ctx))
+	defer p.Close()
+
+	// nothing listens, the packet is refused and the read fails
+	require.NoError(t, p.Command("sim/flight_controls/landing_gear_toggle"))
+	select {
+	case e := <-p.Events():
+		assert.Equal(t, EventQuit, e.Type)
+	case <-time.After(2 * time.Second):
+		t.Fatal("no quit event")
+	}
+	// the subscriptions end with the read loop
+	assert.Eventually(t, func() bool { return p.ctx.Err() != nil }, 2*time.Second, 10*time.Millisecond)
+}
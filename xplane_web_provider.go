@@ -0,0 +1,349 @@
+package simgo
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/op/go-logging"
+	"nhooyr.io/websocket"
+	"nhooyr.io/websocket/wsjson"
+)
+
+// xplaneWebAPI is the path of the X-Plane Web API, REST and WebSocket
+const xplaneWebAPI = "/api/v2"
+
+var _ DataProvider = (*XPlaneWebProvider)(nil)
+
+// XPlaneWebProvider is the DataProvider of the X-Plane 12 Web API. Reports use the dataref tag like XPlaneUDPProvider,
+// string fields are filled from data datarefs like `dataref:"sim/aircraft/view/acf_ui_name"`
+type XPlaneWebProvider struct {
+	url    string
+	logger *logging.Logger
+	client *http.Client
+	// ctx is the lifetime of the connection, cancel is called by Close
+	ctx      context.Context
+	cancel   context.CancelFunc
+	ws       *websocket.Conn
+	events   chan Event
+	mu       sync.Mutex
+	reqID    int
+	ids      map[string]int64
+	commands map[string]int64
+	refs     map[int64][]xplaneWebRef
+}
+
+// xplaneWebRef is a dataref of a subscription, index is -1 for a whole dataref
+type xplaneWebRef struct {
+	xplaneRef
+	index int
+}
+
+type xplaneWebRequest struct {
+	ReqID  int         `json:"req_id"`
+	Type   string      `json:"type"`
+	Params interface{} `json:"params"`
+}
+
+type xplaneWebMessage struct {
+	ReqID        int                        `json:"req_id"`
+	Type         string                     `json:"type"`
+	Success      bool                       `json:"success"`
+	ErrorCode    string                     `json:"error_code"`
+	ErrorMessage string                     `json:"error_message"`
+	Data         map[string]json.RawMessage `json:"data"`
+}
+
+type xplaneWebDataref struct {
+	ID    int64       `json:"id"`
+	Index *int        `json:"index,omitempty"`
+	Value interface{} `json:"value,omitempty"`
+}
+
+type xplaneWebCommand struct {
+	ID       int64 `json:"id"`
+	IsActive bool  `json:"is_active"`
+}
+
+// NewXPlaneWebProvider create a X-Plane Web API provider for url, like DefaultXPlaneWebAddr
+func NewXPlaneWebProvider(url string, logger *logging.Logger) *XPlaneWebProvider {
+	return &XPlaneWebProvider{
+		url:      strings.TrimRight(url, "/"),
+		logger:   logger,
+		client:   &http.Client{Timeout: 10 * time.Second},
+		events:   make(chan Event, 16),
+		ids:      make(map[string]int64),
+		commands: make(map[string]int64),
+		refs:     make(map[int64][]xplaneWebRef),
+	}
+}
+
+// Connect open the WebSocket of the Web API. ctx only bounds the handshake, the connection lasts until Close
+func (p *XPlaneWebProvider) Connect(ctx context.Context) error {
+	ws, _, err := websocket.Dial(ctx, "ws"+strings.TrimPrefix(p.url, "http")+xplaneWebAPI, nil)
+	if err != nil {
+		return err
+	}
+	connCtx, cancel := context.WithCancel(context.Background())
+	p.mu.Lock()
+	p.ctx, p.cancel, p.ws = connCtx, cancel, ws
+	p.mu.Unlock()
+	p.logger.Infof("Connected to X-Plane Web API on %s", p.url)
+	go p.readLoop(connCtx, cancel, ws)
+	return nil
+}
+
+func (p *XPlaneWebProvider) readLoop(ctx context.Context, cancel context.CancelFunc, ws *websocket.Conn) {
+	for {
+		var msg xplaneWebMessage
+		if err := wsjson.Read(ctx, ws, &msg); err != nil {
+			if _, _, cerr := p.conn(); cerr == nil {
+				p.logger.Errorf("Unable to read from X-Plane: %s", err.Error())
+				sendEvent(p.events, Event{Type: EventQuit})
+			}
+			// the subscriptions end with the connection
+			cancel()
+			return
+		}
+		switch msg.Type {
+		case "result":
+			if !msg.Success {
+				p.logger.Errorf("X-Plane request %d failed: %s %s", msg.ReqID, msg.ErrorCode, msg.ErrorMessage)
+			}
+		case "dataref_update_values":
+			for key, raw := range msg.Data {
+				id, err := strconv.ParseInt(key, 10, 64)
+				if err != nil {
+					continue
+				}
+				var value interface{}
+				if err := json.Unmarshal(raw, &value); err != nil {
+					continue
+				}
+				p.mu.Lock()
+				refs := p.refs[id]
+				p.mu.Unlock()
+				for _, ref := range refs {
+					if v, ok := xplaneWebValue(value, ref.index); ok {
+						ref.sub.set(ref.pos, v)
+					}
+				}
+			}
+		}
+	}
+}
+
+// xplaneWebValue return the float64 or string value of a dataref, the element index of an array
+func xplaneWebValue(value interface{}, index int) (interface{}, bool) {
+	switch v := value.(type) {
+	case float64:
+		return v, true
+	case bool:
+		if v {
+			return float64(1), true
+		}
+		return float64(0), true
+	case string:
+		b, err := base64.StdEncoding.DecodeString(v)
+		if err != nil {
+			return v, true
+		}
+		return strings.TrimRight(string(b), "\x00"), true
+	case []interface{}:
+		if index < 0 || index >= len(v) {
+			return nil, false
+		}
+		return xplaneWebValue(v[index], -1)
+	}
+	return nil, false
+}
+
+// splitDataref split "sim/flightmodel/engine/ENGN_N1_[0]" in the dataref name and the array index, -1 when absent
+func splitDataref(path string) (string, int) {
+	i := strings.LastIndex(path, "[")
+	if i < 0 || !strings.HasSuffix(path, "]") {
+		return path, -1
+	}
+	index, err := strconv.Atoi(path[i+1 : len(path)-1])
+	if err != nil {
+		return path, -1
+	}
+	return path[:i], index
+}
+
+func (p *XPlaneWebProvider) conn() (context.Context, *websocket.Conn, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.ws == nil {
+		return nil, nil, errProviderNotConnected
+	}
+	return p.ctx, p.ws, nil
+}
+
+func (p *XPlaneWebProvider) request(requestType string, params interface{}) error {
+	ctx, ws, err := p.conn()
+	if err != nil {
+		return err
+	}
+	p.mu.Lock()
+	p.reqID++
+	req := xplaneWebRequest{p.reqID, requestType, params}
+	p.mu.Unlock()
+	return wsjson.Write(ctx, ws, req)
+}
+
+// lookup resolve the ID of a dataref or command name with the REST API, IDs are cached in cache
+func (p *XPlaneWebProvider) lookup(kind string, name string, cache map[string]int64) (int64, error) {
+	p.mu.Lock()
+	id, found := cache[name]
+	ctx := p.ctx
+	p.mu.Unlock()
+	if found {
+		return id, nil
+	}
+	if ctx == nil {
+		return 0, errProviderNotConnected
+	}
+
+	query := url.Values{"filter[name]": {name}}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.url+xplaneWebAPI+"/"+kind+"?"+query.Encode(), nil)
+	if err != nil {
+		return 0, err
+	}
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return 0, fmt.Errorf("lookup %s %s: %s", kind, name, resp.Status)
+	}
+	var result struct {
+		Data []struct {
+			ID   int64  `json:"id"`
+			Name string `json:"name"`
+		} `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return 0, err
+	}
+	for _, d := range result.Data {
+		if d.Name == name {
+			p.mu.Lock()
+			cache[name] = d.ID
+			p.mu.Unlock()
+			return d.ID, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown X-Plane %s %s", strings.TrimSuffix(kind, "s"), name)
+}
+
+// Subscribe to the datarefs of report. X-Plane sends updates at its own rate, the report is sent every interval,
+// one second when zero
+func (p *XPlaneWebProvider) Subscribe(report interface{}, interval time.Duration) (<-chan interface{}, error) {
+	ctx, _, err := p.conn()
+	if err != nil {
+		return nil, err
+	}
+	val := reflect.Indirect(reflect.ValueOf(report))
+	if val.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("report must be a struct, got %s", val.Kind())
+	}
+	fields, paths := datarefs(val.Type())
+	if len(fields) == 0 {
+		return nil, errors.New("report has no dataref")
+	}
+	if interval <= 0 {
+		interval = time.Second
+	}
+
+	sub := newXPlaneSubscription(val, fields)
+	params := make([]xplaneWebDataref, 0, len(paths))
+	for pos, path := range paths {
+		name, index := splitDataref(path)
+		id, err := p.lookup("datarefs", name, p.ids)
+		if err != nil {
+			return nil, err
+		}
+		p.mu.Lock()
+		p.refs[id] = append(p.refs[id], xplaneWebRef{xplaneRef{sub, pos, path}, index})
+		p.mu.Unlock()
+		params = append(params, xplaneWebDataref{ID: id})
+	}
+	if err := p.request("dataref_subscribe_values", map[string]interface{}{"datarefs": params}); err != nil {
+		return nil, err
+	}
+	return sub.run(ctx, interval), nil
+}
+
+// Write set the datarefs of report
+func (p *XPlaneWebProvider) Write(report interface{}) error {
+	val := reflect.Indirect(reflect.ValueOf(report))
+	if val.Kind() != reflect.Struct {
+		return fmt.Errorf("report must be a struct, got %s", val.Kind())
+	}
+	fields, paths := datarefs(val.Type())
+	params := make([]xplaneWebDataref, 0, len(fields))
+	for i, field := range fields {
+		f, ok := fieldFloat(val.Field(field))
+		if !ok {
+			continue
+		}
+		name, index := splitDataref(paths[i])
+		id, err := p.lookup("datarefs", name, p.ids)
+		if err != nil {
+			return err
+		}
+		d := xplaneWebDataref{ID: id, Value: f}
+		if index >= 0 {
+			d.Index = &index
+		}
+		params = append(params, d)
+	}
+	if len(params) == 0 {
+		return nil
+	}
+	return p.request("dataref_set_values", map[string]interface{}{"datarefs": params})
+}
+
+// Command activate and release a X-Plane command like "sim/flight_controls/landing_gear_toggle"
+func (p *XPlaneWebProvider) Command(command string) error {
+	id, err := p.lookup("commands", command, p.commands)
+	if err != nil {
+		return err
+	}
+	for _, active := range []bool{true, false} {
+		params := map[string]interface{}{"commands": []xplaneWebCommand{{id, active}}}
+		if err := p.request("command_set_is_active", params); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Events return EventQuit when the connection to X-Plane is lost
+func (p *XPlaneWebProvider) Events() <-chan Event {
+	return p.events
+}
+
+// Close the WebSocket connection
+func (p *XPlaneWebProvider) Close() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.ws == nil {
+		return nil
+	}
+	err := p.ws.Close(websocket.StatusNormalClosure, "")
+	p.ws = nil
+	p.cancel()
+	return err
+}
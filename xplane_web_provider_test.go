@@ -0,0 +1,155 @@
+package simgo
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/op/go-logging"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"nhooyr.io/websocket"
+	"nhooyr.io/websocket/wsjson"
+)
+
+type xplaneWebReport struct {
+	Elevation float64 `dataref:"sim/flightmodel/position/elevation"`
+	N1        float64 `dataref:"sim/flightmodel/engine/ENGN_N1_[1]"`
+	Title     string  `dataref:"sim/aircraft/view/acf_ui_name"`
+}
+
+// xplaneWebStandIn serves the REST and WebSocket Web API like X-Plane and records the WebSocket requests
+func xplaneWebStandIn(t *testing.T, ids map[string]int64, values map[int64]interface{}) (*httptest.Server, <-chan map[string]interface{}) {
+	requests := make(chan map[string]interface{}, 16)
+	lookup := func(w http.ResponseWriter, r *http.Request) {
+		name := r.URL.Query().Get("filter[name]")
+		data := []map[string]interface{}{}
+		if id, found := ids[name]; found {
+			data = append(data, map[string]interface{}{"id": id, "name": name})
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{"data": data})
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v2/datarefs", lookup)
+	mux.HandleFunc("/api/v2/commands", lookup)
+	mux.HandleFunc("/api/v2", func(w http.ResponseWriter, r *http.Request) {
+		ws, err := websocket.Accept(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer ws.CloseNow()
+		for {
+			var req map[string]interface{}
+			if err := wsjson.Read(r.Context(), ws, &req); err != nil {
+				return
+			}
+			requests <- req
+			wsjson.Write(r.Context(), ws, map[string]interface{}{"req_id": req["req_id"], "type": "result", "success": true})
+			if req["type"] == "dataref_subscribe_values" {
+				data := map[string]interface{}{}
+				for _, d := range req["params"].(map[string]interface{})["datarefs"].([]interface{}) {
+					id := int64(d.(map[string]interface{})["id"].(float64))
+					data[strconv.FormatInt(id, 10)] = values[id]
+				}
+				wsjson.Write(r.Context(), ws, map[string]interface{}{"type": "dataref_update_values", "data": data})
+			}
+		}
+	})
+	ts := httptest.NewServer(mux)
+	t.Cleanup(ts.Close)
+	return ts, requests
+}
+
+func TestXPlaneWebProvider(t *testing.T) {
+	ts, requests := xplaneWebStandIn(t, map[string]int64{
+		"sim/flightmodel/position/elevation":      1,
+		"sim/flightmodel/engine/ENGN_N1_":         2,
+		"sim/aircraft/view/acf_ui_name":           3,
+		"sim/flight_controls/landing_gear_toggle": 10,
+	}, map[int64]interface{}{
+		1: 1234.5,
+		2: []interface{}{80.0, 82.5},
+		3: base64.StdEncoding.EncodeToString([]byte("Cessna 172\x00\x00")),
+	})
+
+	s := NewSimGo(logging.MustGetLogger("simgo-test"), XPlaneWeb)
+	p, err := s.NewProvider("simgo-test", ts.URL)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	require.NoError(t, p.Connect(ctx))
+	defer p.Close()
+	// the connection outlives the ctx of Connect
+	cancel()
+
+	c, err := p.Subscribe(xplaneWebReport{}, 50*time.Millisecond)
+	require.NoError(t, err)
+	assert.Equal(t, "dataref_subscribe_values", (<-requests)["type"])
+	select {
+	case r := <-c:
+		assert.Equal(t, xplaneWebReport{Elevation: 1234.5, N1: 82.5, Title: "Cessna 172"}, r)
+	case <-time.After(2 * time.Second):
+		t.Fatal("no report received")
+	}
+
+	require.NoError(t, p.Write(struct {
+		N1 float64 `dataref:"sim/flightmodel/engine/ENGN_N1_[1]"`
+	}{90}))
+	set := <-requests
+	assert.Equal(t, "dataref_set_values", set["type"])
+	assert.Equal(t, map[string]interface{}{"datarefs": []interface{}{
+		map[string]interface{}{"id": 2.0, "index": 1.0, "value": 90.0},
+	}}, set["params"])
+
+	require.NoError(t, p.(*XPlaneWebProvider).Command("sim/flight_controls/landing_gear_toggle"))
+	for _, active := range []bool{true, false} {
+		cmd := <-requests
+		assert.Equal(t, "command_set_is_active", cmd["type"])
+		assert.Equal(t, map[string]interface{}{"commands": []interface{}{
+			map[string]interface{}{"id": 10.0, "is_active": active},
+		}}, cmd["params"])
+	}
+
+	_, err = p.Subscribe(struct {
+		Unknown float64 `dataref:"sim/unknown"`
+	}{}, 0)
+	assert.Error(t, err)
+
+	require.NoError(t, p.Close())
+	requireClosed(t, c)
+}
+
+func TestXPlaneWebProviderDropped(t *testing.T) {
+	drop := make(chan struct{})
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ws, err := websocket.Accept(w, r, nil)
+		if err != nil {
+			return
+		}
+		<-drop
+		ws.CloseNow()
+	}))
+	t.Cleanup(ts.Close)
+
+	p := NewXPlaneWebProvider(ts.URL, logging.MustGetLogger("simgo-test"))
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	require.NoError(t, p.Connect(ctx))
+	defer p.Close()
+
+	close(drop)
+	select {
+	case e := <-p.Events():
+		assert.Equal(t, EventQuit, e.Type)
+	case <-time.After(2 * time.Second):
+		t.Fatal("no quit event")
+	}
+	// the subscriptions end with the read loop
+	assert.Eventually(t, func() bool { return p.ctx.Err() != nil }, 2*time.Second, 10*time.Millisecond)
+}
@@ -0,0 +1,261 @@
+package simgo
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// XPUIPCConfig is an XPUIPC offset override file like XPUIPCOffsets.cfg
+type XPUIPCConfig struct {
+	Datarefs []XPUIPCDataref
+	Offsets  []XPUIPCOffset
+}
+
+// XPUIPCDataref is a Dataref line, it names a dataref used as $Name by Offset lines
+//
+//	Dataref SEAT_B sim/cockpit/switches/fasten_seat_belts int
+type XPUIPCDataref struct {
+	Name string
+	Path string
+	// Type is the dataref type: int, float, double, or an array like float[8]
+	Type string
+	// Comment is the comment lines written before the line, without #
+	Comment []string
+}
+
+// XPUIPCOffset is an Offset line, it maps an FSUIPC offset to an RPN expression of datarefs
+//
+//	Offset 0x341D UINT8 1 rw $SEAT_B 1 -
+type XPUIPCOffset struct {
+	Address uint32
+	// Type is the offset type: UINT8, SINT16, FLOAT32, FLOAT64...
+	Type string
+	Size int
+	// Access is r, w or rw
+	Access string
+	// Expression is the RPN expression starting with a $VAR reference, followed by the scaling like "65536 *"
+	Expression []string
+	// Comment is the comment lines written before the line, without #
+	Comment []string
+}
+
+// Variable return the dataref name referenced by the expression, without $
+func (o XPUIPCOffset) Variable() string {
+	for _, token := range o.Expression {
+		if strings.HasPrefix(token, "$") {
+			return strings.TrimPrefix(token, "$")
+		}
+	}
+	return ""
+}
+
+// Readable return true if FSUIPC clients can read the offset
+func (o XPUIPCOffset) Readable() bool {
+	return strings.Contains(o.Access, "r")
+}
+
+// Writable return true if FSUIPC clients can write the offset
+func (o XPUIPCOffset) Writable() bool {
+	return strings.Contains(o.Access, "w")
+}
+
+// ParseXPUIPCConfig read an XPUIPC offset override file
+func ParseXPUIPCConfig(r io.Reader) (*XPUIPCConfig, error) {
+	c := &XPUIPCConfig{}
+	var comment []string
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" {
+			continue
+		}
+		if strings.HasPrefix(text, "#") {
+			comment = append(comment, strings.TrimSpace(strings.TrimPrefix(text, "#")))
+			continue
+		}
+		fields := strings.Fields(text)
+		switch strings.ToLower(fields[0]) {
+		case "dataref":
+			if len(fields) != 4 {
+				return nil, fmt.Errorf("line %d: Dataref needs a name, a path and a type", line)
+			}
+			c.Datarefs = append(c.Datarefs, XPUIPCDataref{fields[1], fields[2], fields[3], comment})
+		case "offset":
+			if len(fields) < 6 {
+				return nil, fmt.Errorf("line %d: Offset needs an address, a type, a size, an access and an expression", line)
+			}
+			address, err := strconv.ParseUint(fields[1], 0, 32)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: invalid address %s", line, fields[1])
+			}
+			size, err := strconv.Atoi(fields[3])
+			if err != nil || size <= 0 {
+				return nil, fmt.Errorf("line %d: invalid size %s", line, fields[3])
+			}
+			access := strings.ToLower(fields[4])
+			if access != "r" && access != "w" && access != "rw" {
+				return nil, fmt.Errorf("line %d: invalid access %s, expected r, w or rw", line, fields[4])
+			}
+			c.Offsets = append(c.Offsets, XPUIPCOffset{uint32(address), strings.ToUpper(fields[2]), size, access, fields[5:], comment})
+		default:
+			return nil, fmt.Errorf("line %d: unknown directive %s", line, fields[0])
+		}
+		comment = nil
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// WriteTo write the config in the XPUIPC format, Dataref lines first
+func (c *XPUIPCConfig) WriteTo(w io.Writer) (int64, error) {
+	b := &strings.Builder{}
+	writeComment := func(comment []string) {
+		for _, line := range comment {
+			fmt.Fprintf(b, "# %s\n", line)
+		}
+	}
+	for _, d := range c.Datarefs {
+		writeComment(d.Comment)
+		fmt.Fprintf(b, "Dataref %s\t%s\t%s\n", d.Name, d.Path, d.Type)
+	}
+	for i, o := range c.Offsets {
+		if i == 0 && len(c.Datarefs) > 0 {
+			b.WriteString("\n")
+		}
+		writeComment(o.Comment)
+		fmt.Fprintf(b, "Offset 0x%04X\t%s\t%d\t%s\t%s\n", o.Address, o.Type, o.Size, o.Access, strings.Join(o.Expression, " "))
+	}
+	n, err := io.WriteString(w, b.String())
+	return int64(n), err
+}
+
+// Validate check duplicate dataref names, undefined $VAR references, duplicate offset addresses and overlapping sizes
+func (c *XPUIPCConfig) Validate() error {
+	errs := make([]error, 0)
+	names := make(map[string]bool)
+	for _, d := range c.Datarefs {
+		if names[d.Name] {
+			errs = append(errs, fmt.Errorf("dataref %s is declared twice", d.Name))
+		}
+		names[d.Name] = true
+	}
+
+	offsets := append([]XPUIPCOffset(nil), c.Offsets...)
+	sort.SliceStable(offsets, func(i, j int) bool { return offsets[i].Address < offsets[j].Address })
+	for i, o := range offsets {
+		if v := o.Variable(); v == "" {
+			errs = append(errs, fmt.Errorf("offset 0x%04X has no $VAR reference", o.Address))
+		} else if !names[v] {
+			errs = append(errs, fmt.Errorf("offset 0x%04X references undefined dataref $%s", o.Address, v))
+		}
+		if i == 0 {
+			continue
+		}
+		prev := offsets[i-1]
+		switch {
+		case prev.Address == o.Address:
+			errs = append(errs, fmt.Errorf("offset 0x%04X is declared twice", o.Address))
+		case prev.Address+uint32(prev.Size) > o.Address:
+			errs = append(errs, fmt.Errorf("offset 0x%04X (%d bytes) overlaps offset 0x%04X", prev.Address, prev.Size, o.Address))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// GenerateXPUIPCConfig build the overrides of the fields of reports having a dataref tag and
+// the address, type and size tags of FSUIPC. The access tag (r, w or rw, r by default) and the scale tag,
+// an RPN expression applied to the dataref like "65536 *", are optional. The dataref is named after the field,
+// FASTEN_SEAT_BELTS for FastenSeatBelts, unless a name tag is set
+//
+//	FastenSeatBelts int `address:"0x341D" type:"int" size:"1" dataref:"sim/cockpit/switches/fasten_seat_belts" name:"SEAT_B" access:"rw"`
+func GenerateXPUIPCConfig(reports ...interface{}) (*XPUIPCConfig, error) {
+	c := &XPUIPCConfig{}
+	for _, report := range reports {
+		t := reflect.Indirect(reflect.ValueOf(report)).Type()
+		if t.Kind() != reflect.Struct {
+			return nil, fmt.Errorf("report must be a struct, got %s", t.Kind())
+		}
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			path := field.Tag.Get("dataref")
+			addressTag := field.Tag.Get("address")
+			if path == "" || addressTag == "" {
+				continue
+			}
+			address, err := strconv.ParseUint(addressTag, 0, 32)
+			if err != nil {
+				return nil, fmt.Errorf("%s.%s: invalid address %s", t.Name(), field.Name, addressTag)
+			}
+			size, _ := strconv.Atoi(field.Tag.Get("size"))
+			offsetType, err := xpuipcOffsetType(field.Tag.Get("type"), size)
+			if err != nil {
+				return nil, fmt.Errorf("%s.%s: %w", t.Name(), field.Name, err)
+			}
+			access := field.Tag.Get("access")
+			if access == "" {
+				access = "r"
+			}
+
+			name := field.Tag.Get("name")
+			if name == "" {
+				name = xpuipcName(field.Name)
+			}
+			c.Datarefs = append(c.Datarefs, XPUIPCDataref{Name: name, Path: path, Type: xpuipcDatarefType(field.Type)})
+			expression := append([]string{"$" + name}, strings.Fields(field.Tag.Get("scale"))...)
+			c.Offsets = append(c.Offsets, XPUIPCOffset{Address: uint32(address), Type: offsetType, Size: size, Access: access, Expression: expression})
+		}
+	}
+	return c, c.Validate()
+}
+
+// xpuipcOffsetType convert the FSUIPC type and size tags to the XPUIPC offset type
+func xpuipcOffsetType(fsuipcType string, size int) (string, error) {
+	if size != 1 && size != 2 && size != 4 && size != 8 {
+		return "", fmt.Errorf("unsupported size %d", size)
+	}
+	bits := strconv.Itoa(size * 8)
+	switch fsuipcType {
+	case "uint", "bits":
+		return "UINT" + bits, nil
+	case "int":
+		return "SINT" + bits, nil
+	case "float":
+		if size == 4 || size == 8 {
+			return "FLOAT" + bits, nil
+		}
+	}
+	return "", fmt.Errorf("unsupported type %s of %d bytes", fsuipcType, size)
+}
+
+// xpuipcDatarefType return the dataref type matching a Go field type
+func xpuipcDatarefType(t reflect.Type) string {
+	switch t.Kind() {
+	case reflect.Float32:
+		return "float"
+	case reflect.Float64:
+		return "double"
+	}
+	return "int"
+}
+
+// xpuipcName convert FastenSeatBelts to FASTEN_SEAT_BELTS
+func xpuipcName(field string) string {
+	b := &strings.Builder{}
+	runes := []rune(field)
+	for i, r := range runes {
+		if i > 0 && unicode.IsUpper(r) && (unicode.IsLower(runes[i-1]) || (i+1 < len(runes) && unicode.IsLower(runes[i+1]))) {
+			b.WriteRune('_')
+		}
+		b.WriteRune(unicode.ToUpper(r))
+	}
+	return b.String()
+}
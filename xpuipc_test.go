@@ -0,0 +1,100 @@
+package simgo
+
+import (
+	"flag"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var update = flag.Bool("update", false, "regenerate XPUIPCOffsets.cfg from Offsets")
+
+const xpuipcHeader = "Generated from simgo.Offsets by go test -run TestXPUIPCOffsets -update, do not edit"
+
+func TestXPUIPCRoundTrip(t *testing.T) {
+	src := `# DATAREFS
+Dataref SEAT_B		sim/cockpit/switches/fasten_seat_belts	int
+Dataref N1 sim/flightmodel/engine/ENGN_N1_ float[8]
+
+# OFFSETS
+Offset 	0x341D	UINT8	1	rw	$SEAT_B 1 -
+Offset  0x0898	UINT16	2	r	$N1 16384 * 100 /
+`
+	c, err := ParseXPUIPCConfig(strings.NewReader(src))
+	require.NoError(t, err)
+	require.NoError(t, c.Validate())
+	require.Len(t, c.Datarefs, 2)
+	require.Len(t, c.Offsets, 2)
+	assert.Equal(t, XPUIPCDataref{"SEAT_B", "sim/cockpit/switches/fasten_seat_belts", "int", []string{"DATAREFS"}}, c.Datarefs[0])
+	assert.Equal(t, uint32(0x0898), c.Offsets[1].Address)
+	assert.Equal(t, "N1", c.Offsets[1].Variable())
+	assert.Equal(t, []string{"$N1", "16384", "*", "100", "/"}, c.Offsets[1].Expression)
+	assert.True(t, c.Offsets[0].Writable())
+	assert.False(t, c.Offsets[1].Writable())
+
+	b := &strings.Builder{}
+	_, err = c.WriteTo(b)
+	require.NoError(t, err)
+	again, err := ParseXPUIPCConfig(strings.NewReader(b.String()))
+	require.NoError(t, err)
+	assert.Equal(t, c, again)
+}
+
+func TestXPUIPCParseErrors(t *testing.T) {
+	for _, src := range []string{
+		"Dataref SEAT_B sim/cockpit/switches/fasten_seat_belts",
+		"Offset 0xZZ UINT8 1 rw $SEAT_B",
+		"Offset 0x341D UINT8 1 x $SEAT_B",
+		"Lua script.lua",
+	} {
+		_, err := ParseXPUIPCConfig(strings.NewReader(src))
+		assert.Error(t, err, src)
+	}
+}
+
+func TestXPUIPCValidate(t *testing.T) {
+	c, err := ParseXPUIPCConfig(strings.NewReader(`
+Dataref SEAT_B sim/cockpit/switches/fasten_seat_belts int
+Dataref SEAT_B sim/cockpit/switches/fasten_seat_belts int
+Offset 0x341C UINT32 2 rw $SEAT_B
+Offset 0x341D UINT32 2 rw $NO_SMOKING
+Offset 0x341D UINT8 1 rw $SEAT_B
+`))
+	require.NoError(t, err)
+	err = c.Validate()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "dataref SEAT_B is declared twice")
+	assert.Contains(t, err.Error(), "undefined dataref $NO_SMOKING")
+	assert.Contains(t, err.Error(), "offset 0x341C (2 bytes) overlaps offset 0x341D")
+	assert.Contains(t, err.Error(), "offset 0x341D is declared twice")
+}
+
+func TestXPUIPCOffsets(t *testing.T) {
+	generated, err := GenerateXPUIPCConfig(Offsets{})
+	require.NoError(t, err)
+	require.NotEmpty(t, generated.Datarefs)
+	names := make([]string, 0, len(generated.Datarefs))
+	for _, d := range generated.Datarefs {
+		names = append(names, d.Name)
+	}
+	assert.Subset(t, names, []string{"SEAT_B", "NO_SMOKING", "CANOPY_OPEN"}, "name tags keep the identifiers of the shipped config")
+	generated.Datarefs[0].Comment = []string{xpuipcHeader}
+
+	if *update {
+		f, err := os.Create("XPUIPCOffsets.cfg")
+		require.NoError(t, err)
+		defer f.Close()
+		_, err = generated.WriteTo(f)
+		require.NoError(t, err)
+	}
+
+	f, err := os.Open("XPUIPCOffsets.cfg")
+	require.NoError(t, err)
+	defer f.Close()
+	shipped, err := ParseXPUIPCConfig(f)
+	require.NoError(t, err)
+	assert.Equal(t, generated, shipped, "XPUIPCOffsets.cfg is out of date, run go test -run TestXPUIPCOffsets -update")
+}